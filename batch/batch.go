@@ -0,0 +1,256 @@
+// Package batch provides methods for resolving lists of labels (or identifiers) against a `libraryofcongress.Lookup`
+// instance concurrently and writing the results in a variety of formats.
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"io"
+	"runtime"
+	"strings"
+)
+
+const (
+	// ERROR_NOT_FOUND is the error type assigned to inputs which return no records.
+	ERROR_NOT_FOUND string = "NotFound"
+	// ERROR_MULTIPLE_CANDIDATES is the error type assigned to inputs which return more than one record.
+	ERROR_MULTIPLE_CANDIDATES string = "MultipleCandidates"
+	// ERROR_OTHER is the error type assigned to inputs whose lookup failed for any other reason.
+	ERROR_OTHER string = "Error"
+)
+
+// type Result is a struct containing the outcome of resolving a single input against a `libraryofcongress.Lookup` instance.
+type Result struct {
+	// Input is the original input value.
+	Input string `json:"input"`
+	// Count is the number of records matching Input.
+	Count int `json:"count"`
+	// Ids is the list of identifiers for the records matching Input.
+	Ids []string `json:"ids"`
+	// Labels is the list of labels for the records matching Input.
+	Labels []string `json:"labels"`
+	// Sources is the list of sources for the records matching Input.
+	Sources []string `json:"sources"`
+	// URIs is the list of canonical id.loc.gov URIs for the records matching Input.
+	URIs []string `json:"uris"`
+	// Error is the (optional) error type for Input. Valid options are "NotFound", "MultipleCandidates" and "Error".
+	Error string `json:"error,omitempty"`
+	// Message is the (optional) error message for Input.
+	Message string `json:"message,omitempty"`
+}
+
+// type uriRecord is implemented by records that can derive their canonical id.loc.gov URI.
+type uriRecord interface {
+	URI() string
+}
+
+// type ResolveFunc is a function invoked, in input order, for each result by the `ResolveWithReader` method. If it returns
+// an error then no more values are resolved and that error is returned by `ResolveWithReader`.
+type ResolveFunc func(context.Context, *Result) error
+
+// Resolve() resolves each value in 'inputs' against 'l' using up to 'workers' concurrent lookups and returns the results
+// in the same order as 'inputs'. If 'workers' is less than 1 then `runtime.NumCPU()` is used. If 'ctx' is cancelled then
+// no more values are resolved and the context's error is returned.
+func Resolve(ctx context.Context, l libraryofcongress.Lookup, inputs []string, workers int) ([]*Result, error) {
+
+	results := make([]*Result, 0, len(inputs))
+
+	cb := func(ctx context.Context, r *Result) error {
+		results = append(results, r)
+		return nil
+	}
+
+	err := ResolveWithReader(ctx, l, NewSliceReader(inputs), workers, cb)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// ResolveWithReader() resolves each value read from 'in' against 'l' using up to 'workers' concurrent lookups and invokes
+// 'cb' with each result in the same order that the values were read. If 'workers' is less than 1 then `runtime.NumCPU()`
+// is used. Values are read as they are needed and results are passed to 'cb' as soon as they (and the results before them)
+// are ready, so only a small number of values and results, proportional to 'workers', are held in memory at any one time.
+// If 'ctx' is cancelled then no more values are resolved and the context's error is returned.
+func ResolveWithReader(ctx context.Context, l libraryofcongress.Lookup, in InputReader, workers int, cb ResolveFunc) error {
+
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+
+	// Stop dispatching values if 'cb' fails
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	throttle := make(chan bool, workers)
+
+	// Each value is assigned a (buffered) channel for its result; these channels are queued in input
+	// order which bounds the number of results waiting to be passed to 'cb'
+
+	pending := make(chan chan *Result, workers)
+
+	var read_err error
+
+	go func() {
+
+		defer close(pending)
+
+		for {
+
+			select {
+			case <-ctx.Done():
+				return
+			case throttle <- true:
+				// pass
+			}
+
+			input, err := in.Read()
+
+			if err != nil {
+
+				<-throttle
+
+				if err != io.EOF {
+					read_err = fmt.Errorf("Failed to read input, %w", err)
+				}
+
+				return
+			}
+
+			result_ch := make(chan *Result, 1)
+
+			go func(input string) {
+
+				defer func() {
+					<-throttle
+				}()
+
+				result_ch <- ResolveOne(ctx, l, input)
+			}(input)
+
+			select {
+			case <-ctx.Done():
+				return
+			case pending <- result_ch:
+				// pass
+			}
+		}
+	}()
+
+	for {
+
+		var result_ch chan *Result
+		var ok bool
+
+		// Reading a value may block (for example, on STDIN) so don't wait for the next result to be queued if 'ctx'
+		// has been cancelled
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case result_ch, ok = <-pending:
+			// pass
+		}
+
+		if !ok {
+			break
+		}
+
+		var r *Result
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case r = <-result_ch:
+			// pass
+		}
+
+		err := cb(ctx, r)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if read_err != nil {
+		return read_err
+	}
+
+	return ctx.Err()
+}
+
+// ResolveOne() resolves 'input' against 'l'. Empty inputs are assigned the `ERROR_NOT_FOUND` error type without
+// being looked up.
+func ResolveOne(ctx context.Context, l libraryofcongress.Lookup, input string) *Result {
+
+	r := &Result{
+		Input:   input,
+		Ids:     make([]string, 0),
+		Labels:  make([]string, 0),
+		Sources: make([]string, 0),
+		URIs:    make([]string, 0),
+	}
+
+	// Empty inputs (for example blank lines) are never found but are still returned so results line up with inputs
+
+	if strings.TrimSpace(input) == "" {
+		r.Error = ERROR_NOT_FOUND
+		r.Message = "Empty input"
+		return r
+	}
+
+	records, err := l.Find(ctx, input)
+
+	if err != nil {
+
+		switch {
+		case errors.Is(err, libraryofcongress.ErrNotFound):
+			r.Error = ERROR_NOT_FOUND
+		case errors.Is(err, libraryofcongress.ErrMultipleCandidates):
+			r.Error = ERROR_MULTIPLE_CANDIDATES
+		default:
+			r.Error = ERROR_OTHER
+		}
+
+		r.Message = err.Error()
+
+		return r
+	}
+
+	for _, rec := range records {
+
+		loc_rec, ok := rec.(libraryofcongress.Record)
+
+		if !ok {
+			continue
+		}
+
+		r.Ids = append(r.Ids, loc_rec.GetId())
+		r.Labels = append(r.Labels, loc_rec.GetLabel())
+		r.Sources = append(r.Sources, loc_rec.GetSource())
+
+		uri_rec, ok := rec.(uriRecord)
+
+		if ok {
+			r.URIs = append(r.URIs, uri_rec.URI())
+		}
+	}
+
+	r.Count = len(r.Ids)
+
+	switch r.Count {
+	case 0:
+		r.Error = ERROR_NOT_FOUND
+	case 1:
+		// pass
+	default:
+		r.Error = ERROR_MULTIPLE_CANDIDATES
+	}
+
+	return r
+}
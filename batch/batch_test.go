@@ -0,0 +1,237 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	_ "github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcsh"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+
+	ctx := context.Background()
+
+	rel_path := "../fixtures/lcsh.csv.bz2"
+	abs_path, err := filepath.Abs(rel_path)
+
+	if err != nil {
+		t.Fatalf("Failed to derive absolute path for %s, %v", rel_path, err)
+	}
+
+	l, err := libraryofcongress.NewLookup(ctx, fmt.Sprintf("lcsh://file%s", abs_path))
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	inputs, err := ReadCSV(strings.NewReader("id,subject\n1,Airplanes\n2,Unknown\n3,Cooking\n4,\n"), "subject", true)
+
+	if err != nil {
+		t.Fatalf("Failed to read inputs, %v", err)
+	}
+
+	results, err := Resolve(ctx, l, inputs, 2)
+
+	if err != nil {
+		t.Fatalf("Failed to resolve inputs, %v", err)
+	}
+
+	if len(results) != len(inputs) {
+		t.Fatalf("Expected %d results, got %d", len(inputs), len(results))
+	}
+
+	expected := []string{"", ERROR_NOT_FOUND, ERROR_MULTIPLE_CANDIDATES, ERROR_NOT_FOUND}
+
+	for idx, r := range results {
+
+		if r.Input != inputs[idx] {
+			t.Fatalf("Unexpected input at position %d, %s", idx, r.Input)
+		}
+
+		if r.Error != expected[idx] {
+			t.Fatalf("Unexpected error for '%s', '%s'", r.Input, r.Error)
+		}
+	}
+
+	var buf bytes.Buffer
+
+	wr, err := NewWriter(FORMAT_CSV, &buf)
+
+	if err != nil {
+		t.Fatalf("Failed to create writer, %v", err)
+	}
+
+	for _, r := range results {
+		wr.Write(r)
+	}
+
+	err = wr.Close()
+
+	if err != nil {
+		t.Fatalf("Failed to close writer, %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	if len(lines) != 5 {
+		t.Fatalf("Unexpected output, %s", buf.String())
+	}
+
+	if lines[1] != "Airplanes,1,sh85002782,Airplanes," {
+		t.Fatalf("Unexpected row, %s", lines[1])
+	}
+}
+
+// type countingReader is a struct that implements the `InputReader` interface and counts the number of values read.
+type countingReader struct {
+	InputReader
+	count int32
+}
+
+func (in *countingReader) Read() (string, error) {
+	atomic.AddInt32(&in.count, 1)
+	return in.InputReader.Read()
+}
+
+func TestResolveWithReader(t *testing.T) {
+
+	ctx := context.Background()
+
+	rel_path := "../fixtures/lcsh.csv.bz2"
+	abs_path, err := filepath.Abs(rel_path)
+
+	if err != nil {
+		t.Fatalf("Failed to derive absolute path for %s, %v", rel_path, err)
+	}
+
+	l, err := libraryofcongress.NewLookup(ctx, fmt.Sprintf("lcsh://file%s", abs_path))
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	inputs := make([]string, 1000)
+
+	for i := range inputs {
+		inputs[i] = fmt.Sprintf("Unknown %d", i)
+	}
+
+	// Results are passed to the callback in input order
+
+	in, err := NewInputReader(strings.NewReader(strings.Join(inputs, "\n")), INPUT_FORMAT_LINES, "", true)
+
+	if err != nil {
+		t.Fatalf("Failed to create input reader, %v", err)
+	}
+
+	idx := 0
+
+	err = ResolveWithReader(ctx, l, in, 4, func(ctx context.Context, r *Result) error {
+
+		if r.Input != inputs[idx] {
+			return fmt.Errorf("Unexpected input at position %d, %s", idx, r.Input)
+		}
+
+		idx += 1
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("Failed to resolve inputs, %v", err)
+	}
+
+	if idx != len(inputs) {
+		t.Fatalf("Expected %d results, got %d", len(inputs), idx)
+	}
+
+	// Values stop being read once the callback fails
+
+	counting := &countingReader{InputReader: NewSliceReader(inputs)}
+	cb_err := errors.New("Callback failed")
+
+	err = ResolveWithReader(ctx, l, counting, 4, func(ctx context.Context, r *Result) error {
+		return cb_err
+	})
+
+	if err != cb_err {
+		t.Fatalf("Expected callback error, got %v", err)
+	}
+
+	if atomic.LoadInt32(&counting.count) > 16 {
+		t.Fatalf("Expected reading to stop after callback failed, read %d values", counting.count)
+	}
+
+	// Nothing is resolved once the context has been cancelled
+
+	cancelled_ctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	_, err = Resolve(cancelled_ctx, l, inputs, 4)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled error, got %v", err)
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+
+	tests := map[string][]string{
+		"subject": []string{"Airplanes", "", "Cooking"},
+		"2":       []string{"Airplanes", "", "Cooking"},
+		"3":       []string{"", "", ""},
+	}
+
+	for column, expected := range tests {
+
+		inputs, err := ReadCSV(strings.NewReader("id,subject\n1,Airplanes\n2,\n3, Cooking\n"), column, true)
+
+		if err != nil {
+			t.Fatalf("Failed to read CSV using column %s, %v", column, err)
+		}
+
+		if strings.Join(inputs, "|") != strings.Join(expected, "|") {
+			t.Fatalf("Unexpected inputs using column %s, %v", column, inputs)
+		}
+	}
+
+	inputs, err := ReadCSV(strings.NewReader("1,Airplanes\n2,\n3, Cooking\n"), "2", false)
+
+	if err != nil {
+		t.Fatalf("Failed to read CSV without header, %v", err)
+	}
+
+	if strings.Join(inputs, "|") != "Airplanes||Cooking" {
+		t.Fatalf("Unexpected inputs without header, %v", inputs)
+	}
+
+	_, err = ReadCSV(strings.NewReader("id,subject\n"), "label", true)
+
+	if err == nil {
+		t.Fatalf("Expected invalid column to fail")
+	}
+
+	_, err = ReadCSV(strings.NewReader("id,subject\n"), "subject", false)
+
+	if err == nil {
+		t.Fatalf("Expected column name without header to fail")
+	}
+}
+
+func TestReadLines(t *testing.T) {
+
+	inputs, err := ReadLines(strings.NewReader("Airplanes\n\n  Cooking \n"))
+
+	if err != nil {
+		t.Fatalf("Failed to read lines, %v", err)
+	}
+
+	if strings.Join(inputs, "|") != "Airplanes||Cooking" {
+		t.Fatalf("Unexpected inputs, %v", inputs)
+	}
+}
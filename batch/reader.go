@@ -0,0 +1,233 @@
+package batch
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	// INPUT_FORMAT_LINES is the input format for newline-delimited values.
+	INPUT_FORMAT_LINES string = "lines"
+	// INPUT_FORMAT_CSV is the input format for CSV-encoded values.
+	INPUT_FORMAT_CSV string = "csv"
+)
+
+// type InputReader is an interface for reading values to resolve one at a time.
+type InputReader interface {
+	// Read() returns the next value to resolve or `io.EOF` if there are no more values.
+	Read() (string, error)
+}
+
+// NewInputReader() returns a new `InputReader` instance for values read from 'r' encoded as 'format'. If 'format' is
+// `INPUT_FORMAT_CSV` then 'column' is used to select the column containing values and 'header' indicates whether the
+// first row is a header row (see `NewCSVReader`).
+func NewInputReader(r io.Reader, format string, column string, header bool) (InputReader, error) {
+
+	switch format {
+	case INPUT_FORMAT_LINES:
+		return NewLinesReader(r), nil
+	case INPUT_FORMAT_CSV:
+		return NewCSVReader(r, column, header)
+	default:
+		return nil, fmt.Errorf("Unsupported input format '%s'", format)
+	}
+}
+
+// ReadInputs() returns the list of values to resolve read from 'r' encoded as 'format'. If 'format' is
+// `INPUT_FORMAT_CSV` then 'column' and 'header' are used to select the column containing values (see `ReadCSV`).
+func ReadInputs(r io.Reader, format string, column string, header bool) ([]string, error) {
+
+	in, err := NewInputReader(r, format, column, header)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return readAll(in)
+}
+
+// ReadLines() returns the (trimmed) lines read from 'r', including empty lines.
+func ReadLines(r io.Reader) ([]string, error) {
+	return readAll(NewLinesReader(r))
+}
+
+// ReadCSV() returns the (trimmed) values, including empty values, in 'column' read from CSV data in 'r'. See
+// `NewCSVReader` for details about how 'column' and 'header' are interpreted.
+func ReadCSV(r io.Reader, column string, header bool) ([]string, error) {
+
+	in, err := NewCSVReader(r, column, header)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return readAll(in)
+}
+
+// type SliceReader implements the `InputReader` interface for a list of values.
+type SliceReader struct {
+	inputs []string
+}
+
+// NewSliceReader() returns a new `SliceReader` instance for 'inputs'.
+func NewSliceReader(inputs []string) InputReader {
+
+	in := &SliceReader{
+		inputs: inputs,
+	}
+
+	return in
+}
+
+// Read() returns the next value in the list.
+func (in *SliceReader) Read() (string, error) {
+
+	if len(in.inputs) == 0 {
+		return "", io.EOF
+	}
+
+	v := in.inputs[0]
+	in.inputs = in.inputs[1:]
+
+	return v, nil
+}
+
+// type LinesReader implements the `InputReader` interface for newline-delimited values.
+type LinesReader struct {
+	scanner *bufio.Scanner
+}
+
+// NewLinesReader() returns a new `LinesReader` instance for the lines in 'r'.
+func NewLinesReader(r io.Reader) InputReader {
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	in := &LinesReader{
+		scanner: scanner,
+	}
+
+	return in
+}
+
+// Read() returns the next (trimmed) line. Empty lines are returned as empty strings so that results can be matched
+// to the lines they were read from.
+func (in *LinesReader) Read() (string, error) {
+
+	if in.scanner.Scan() {
+		return strings.TrimSpace(in.scanner.Text()), nil
+	}
+
+	err := in.scanner.Err()
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to read lines, %w", err)
+	}
+
+	return "", io.EOF
+}
+
+// type CSVReader implements the `InputReader` interface for values in a column of CSV data.
+type CSVReader struct {
+	csv_r *csv.Reader
+	idx   int
+}
+
+// NewCSVReader() returns a new `CSVReader` instance for the (trimmed) values in 'column' read from CSV data in 'r'. If
+// 'header' is true then the first row is a header row, which is never returned as a value, and 'column' may either
+// match a value in that row or be a (1-based) column position. If 'header' is false then 'column' must be a (1-based)
+// column position and the first row is treated as data.
+func NewCSVReader(r io.Reader, column string, header bool) (InputReader, error) {
+
+	csv_r := csv.NewReader(r)
+	csv_r.FieldsPerRecord = -1
+
+	in := &CSVReader{
+		csv_r: csv_r,
+	}
+
+	idx := -1
+
+	if header {
+
+		row, err := csv_r.Read()
+
+		if err == io.EOF {
+			return in, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read CSV header, %w", err)
+		}
+
+		for i, h := range row {
+
+			if strings.TrimSpace(h) == column {
+				idx = i
+				break
+			}
+		}
+	}
+
+	if idx == -1 {
+
+		pos, err := strconv.Atoi(column)
+
+		if err != nil || pos < 1 {
+			return nil, fmt.Errorf("Invalid column '%s'", column)
+		}
+
+		idx = pos - 1
+	}
+
+	in.idx = idx
+	return in, nil
+}
+
+// Read() returns the (trimmed) value in the column for the next row. Empty values, and rows without the column, are
+// returned as empty strings so that results can be matched to the rows they were read from.
+func (in *CSVReader) Read() (string, error) {
+
+	row, err := in.csv_r.Read()
+
+	if err == io.EOF {
+		return "", io.EOF
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to read CSV row, %w", err)
+	}
+
+	if in.idx >= len(row) {
+		return "", nil
+	}
+
+	return strings.TrimSpace(row[in.idx]), nil
+}
+
+// readAll() returns all the values read from 'in'.
+func readAll(in InputReader) ([]string, error) {
+
+	inputs := make([]string, 0)
+
+	for {
+
+		v, err := in.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		inputs = append(inputs, v)
+	}
+
+	return inputs, nil
+}
@@ -0,0 +1,354 @@
+package batch
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	// FORMAT_CSV is the output format for CSV-encoded results.
+	FORMAT_CSV string = "csv"
+	// FORMAT_JSON is the output format for results encoded as a JSON array.
+	FORMAT_JSON string = "json"
+	// FORMAT_JSONL is the output format for results encoded as newline-delimited JSON.
+	FORMAT_JSONL string = "jsonl"
+	// FORMAT_TSV is the output format for tab-separated results.
+	FORMAT_TSV string = "tsv"
+	// FORMAT_URI is the output format for results encoded as one line of id.loc.gov URIs per input.
+	FORMAT_URI string = "uri"
+	// FORMAT_TEXT is the output format for results encoded as one "{ID} {LABEL}" line per matching record.
+	FORMAT_TEXT string = "text"
+)
+
+// MULTI_VALUE_SEPARATOR is the string used to join multiple values (identifiers, labels) in a single CSV column.
+const MULTI_VALUE_SEPARATOR string = "|"
+
+// type Writer is an interface for writing `Result` instances.
+type Writer interface {
+	// Write() writes a single `Result` instance.
+	Write(*Result) error
+	// Close() flushes any pending data and terminates the output.
+	Close() error
+}
+
+// NewWriter() returns a new `Writer` instance for 'format' that writes to 'wr'.
+func NewWriter(format string, wr io.Writer) (Writer, error) {
+
+	switch format {
+	case FORMAT_CSV:
+		return NewCSVWriter(wr)
+	case FORMAT_JSON:
+		return NewJSONWriter(wr)
+	case FORMAT_JSONL:
+		return NewJSONLWriter(wr)
+	case FORMAT_TSV:
+		return NewTSVWriter(wr)
+	case FORMAT_URI:
+		return NewURIWriter(wr)
+	case FORMAT_TEXT:
+		return NewTextWriter(wr)
+	default:
+		return nil, fmt.Errorf("Unsupported format '%s'", format)
+	}
+}
+
+// type CSVWriter implements the `Writer` interface for CSV-encoded results.
+type CSVWriter struct {
+	Writer
+	csv_wr       *csv.Writer
+	wrote_header bool
+}
+
+// NewCSVWriter() returns a new `CSVWriter` instance that writes to 'wr'.
+func NewCSVWriter(wr io.Writer) (Writer, error) {
+
+	w := &CSVWriter{
+		csv_wr: csv.NewWriter(wr),
+	}
+
+	return w, nil
+}
+
+// Write() writes 'r' as a CSV row, preceded by a header row if it is the first result written.
+func (w *CSVWriter) Write(r *Result) error {
+
+	if !w.wrote_header {
+
+		err := w.csv_wr.Write([]string{"input", "count", "ids", "labels", "error"})
+
+		if err != nil {
+			return fmt.Errorf("Failed to write header, %w", err)
+		}
+
+		w.wrote_header = true
+	}
+
+	row := []string{
+		r.Input,
+		strconv.Itoa(r.Count),
+		strings.Join(r.Ids, MULTI_VALUE_SEPARATOR),
+		strings.Join(r.Labels, MULTI_VALUE_SEPARATOR),
+		r.Error,
+	}
+
+	err := w.csv_wr.Write(row)
+
+	if err != nil {
+		return fmt.Errorf("Failed to write row, %w", err)
+	}
+
+	return nil
+}
+
+// Close() flushes any buffered CSV data.
+func (w *CSVWriter) Close() error {
+	w.csv_wr.Flush()
+	return w.csv_wr.Error()
+}
+
+// type JSONWriter implements the `Writer` interface for results encoded as a JSON array.
+type JSONWriter struct {
+	Writer
+	wr    io.Writer
+	count int
+}
+
+// NewJSONWriter() returns a new `JSONWriter` instance that writes to 'wr'.
+func NewJSONWriter(wr io.Writer) (Writer, error) {
+
+	w := &JSONWriter{
+		wr: wr,
+	}
+
+	return w, nil
+}
+
+// Write() writes 'r' as an element of a JSON array.
+func (w *JSONWriter) Write(r *Result) error {
+
+	enc, err := json.Marshal(r)
+
+	if err != nil {
+		return fmt.Errorf("Failed to marshal result, %w", err)
+	}
+
+	prefix := ","
+
+	if w.count == 0 {
+		prefix = "["
+	}
+
+	_, err = fmt.Fprintf(w.wr, "%s%s", prefix, enc)
+
+	if err != nil {
+		return fmt.Errorf("Failed to write result, %w", err)
+	}
+
+	w.count += 1
+	return nil
+}
+
+// Close() terminates the JSON array.
+func (w *JSONWriter) Close() error {
+
+	suffix := "]\n"
+
+	if w.count == 0 {
+		suffix = "[]\n"
+	}
+
+	_, err := io.WriteString(w.wr, suffix)
+	return err
+}
+
+// type JSONLWriter implements the `Writer` interface for results encoded as newline-delimited JSON.
+type JSONLWriter struct {
+	Writer
+	enc *json.Encoder
+}
+
+// NewJSONLWriter() returns a new `JSONLWriter` instance that writes to 'wr'.
+func NewJSONLWriter(wr io.Writer) (Writer, error) {
+
+	w := &JSONLWriter{
+		enc: json.NewEncoder(wr),
+	}
+
+	return w, nil
+}
+
+// Write() writes 'r' as a single line of JSON.
+func (w *JSONLWriter) Write(r *Result) error {
+
+	err := w.enc.Encode(r)
+
+	if err != nil {
+		return fmt.Errorf("Failed to write result, %w", err)
+	}
+
+	return nil
+}
+
+// Close() is a no-op for newline-delimited JSON.
+func (w *JSONLWriter) Close() error {
+	return nil
+}
+
+// type TSVWriter implements the `Writer` interface for tab-separated results. Unlike CSV output values are never
+// quoted; any tab or newline characters in values are replaced by spaces.
+type TSVWriter struct {
+	Writer
+	wr           io.Writer
+	wrote_header bool
+}
+
+// NewTSVWriter() returns a new `TSVWriter` instance that writes to 'wr'.
+func NewTSVWriter(wr io.Writer) (Writer, error) {
+
+	w := &TSVWriter{
+		wr: wr,
+	}
+
+	return w, nil
+}
+
+// Write() writes 'r' as a tab-separated row, preceded by a header row if it is the first result written.
+func (w *TSVWriter) Write(r *Result) error {
+
+	if !w.wrote_header {
+
+		err := w.writeRow([]string{"input", "count", "ids", "labels", "error"})
+
+		if err != nil {
+			return fmt.Errorf("Failed to write header, %w", err)
+		}
+
+		w.wrote_header = true
+	}
+
+	row := []string{
+		r.Input,
+		strconv.Itoa(r.Count),
+		strings.Join(r.Ids, MULTI_VALUE_SEPARATOR),
+		strings.Join(r.Labels, MULTI_VALUE_SEPARATOR),
+		r.Error,
+	}
+
+	err := w.writeRow(row)
+
+	if err != nil {
+		return fmt.Errorf("Failed to write row, %w", err)
+	}
+
+	return nil
+}
+
+// Close() is a no-op for tab-separated output.
+func (w *TSVWriter) Close() error {
+	return nil
+}
+
+func (w *TSVWriter) writeRow(row []string) error {
+
+	for idx, v := range row {
+		row[idx] = tsv_replacer.Replace(v)
+	}
+
+	_, err := fmt.Fprintln(w.wr, strings.Join(row, "\t"))
+	return err
+}
+
+// tsv_replacer is used to remove characters with special meaning from tab-separated values.
+var tsv_replacer = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+
+// type URIWriter implements the `Writer` interface for results encoded as id.loc.gov URIs.
+type URIWriter struct {
+	Writer
+	wr io.Writer
+}
+
+// NewURIWriter() returns a new `URIWriter` instance that writes to 'wr'.
+func NewURIWriter(wr io.Writer) (Writer, error) {
+
+	w := &URIWriter{
+		wr: wr,
+	}
+
+	return w, nil
+}
+
+// Write() writes the URIs for the records matching 'r' as a single line, joined by `MULTI_VALUE_SEPARATOR`. Inputs
+// with no matching records are written as an empty line so that output lines always correspond to input values.
+func (w *URIWriter) Write(r *Result) error {
+
+	_, err := fmt.Fprintln(w.wr, strings.Join(r.URIs, MULTI_VALUE_SEPARATOR))
+
+	if err != nil {
+		return fmt.Errorf("Failed to write result, %w", err)
+	}
+
+	return nil
+}
+
+// Close() is a no-op for URI output.
+func (w *URIWriter) Close() error {
+	return nil
+}
+
+// type TextWriter implements the `Writer` interface for results encoded as "{ID} {LABEL}" strings.
+type TextWriter struct {
+	Writer
+	wr io.Writer
+}
+
+// NewTextWriter() returns a new `TextWriter` instance that writes to 'wr'.
+func NewTextWriter(wr io.Writer) (Writer, error) {
+
+	w := &TextWriter{
+		wr: wr,
+	}
+
+	return w, nil
+}
+
+// Write() writes one "{ID} {LABEL}" line for each record matching 'r' or, if the lookup failed, a single
+// "{INPUT} *** {ERROR}" line.
+func (w *TextWriter) Write(r *Result) error {
+
+	if r.Count == 0 && r.Error != "" {
+
+		msg := r.Message
+
+		if msg == "" {
+			msg = r.Error
+		}
+
+		_, err := fmt.Fprintf(w.wr, "%s *** %s\n", r.Input, msg)
+
+		if err != nil {
+			return fmt.Errorf("Failed to write result, %w", err)
+		}
+
+		return nil
+	}
+
+	for idx, id := range r.Ids {
+
+		_, err := fmt.Fprintf(w.wr, "%s %s\n", id, r.Labels[idx])
+
+		if err != nil {
+			return fmt.Errorf("Failed to write result, %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Close() is a no-op for text output.
+func (w *TextWriter) Close() error {
+	return nil
+}
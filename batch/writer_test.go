@@ -0,0 +1,66 @@
+package batch
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriters(t *testing.T) {
+
+	results := []*Result{
+		&Result{
+			Input:  " Airplanes",
+			Count:  1,
+			Ids:    []string{"sh85002782"},
+			Labels: []string{" Airplanes"},
+			URIs:   []string{"http://id.loc.gov/authorities/subjects/sh85002782"},
+		},
+		&Result{
+			Input: "Unknown",
+			Error: ERROR_NOT_FOUND,
+		},
+	}
+
+	tests := map[string]string{
+		FORMAT_TSV:   "input\tcount\tids\tlabels\terror\n Airplanes\t1\tsh85002782\t Airplanes\t\nUnknown\t0\t\t\tNotFound\n",
+		FORMAT_URI:   "http://id.loc.gov/authorities/subjects/sh85002782\n\n",
+		FORMAT_TEXT:  "sh85002782  Airplanes\nUnknown *** NotFound\n",
+		FORMAT_JSONL: `{"input":" Airplanes","count":1,"ids":["sh85002782"],"labels":[" Airplanes"],"sources":null,"uris":["http://id.loc.gov/authorities/subjects/sh85002782"]}` + "\n" + `{"input":"Unknown","count":0,"ids":null,"labels":null,"sources":null,"uris":null,"error":"NotFound"}` + "\n",
+	}
+
+	for format, expected := range tests {
+
+		var buf bytes.Buffer
+
+		wr, err := NewWriter(format, &buf)
+
+		if err != nil {
+			t.Fatalf("Failed to create %s writer, %v", format, err)
+		}
+
+		for _, r := range results {
+
+			err := wr.Write(r)
+
+			if err != nil {
+				t.Fatalf("Failed to write %s result, %v", format, err)
+			}
+		}
+
+		err = wr.Close()
+
+		if err != nil {
+			t.Fatalf("Failed to close %s writer, %v", format, err)
+		}
+
+		if buf.String() != expected {
+			t.Fatalf("Unexpected %s output: %q", format, buf.String())
+		}
+	}
+
+	_, err := NewWriter("xml", &bytes.Buffer{})
+
+	if err == nil {
+		t.Fatalf("Expected unsupported format to fail")
+	}
+}
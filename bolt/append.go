@@ -0,0 +1,72 @@
+package bolt
+
+import (
+	"context"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcnaf"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcsh"
+	bbolt "go.etcd.io/bbolt"
+)
+
+// Append() adds 'data' to the underlying database. 'data' may be a `*lcsh.SubjectHeading`, a `*lcnaf.NamedAuthority`,
+// any other `libraryofcongress.Record` implementation or a `map[string]string` row with "id", "label" and "source" keys.
+// Existing records with the same identifier (and source) are replaced.
+func (l *BoltLookup) Append(ctx context.Context, data interface{}) error {
+
+	row, err := l.newRow(data)
+
+	if err != nil {
+		return err
+	}
+
+	return l.db.Update(func(tx *bbolt.Tx) error {
+
+		_, err := deleteRecords(tx, l.normalize, row["id"], row["source"])
+
+		if err != nil {
+			return fmt.Errorf("Failed to remove existing records, %w", err)
+		}
+
+		return putRecord(tx, l.normalize, row["id"], row["source"], row["label"])
+	})
+}
+
+// newRow() returns a `map[string]string` row, with "id", "label" and "source" keys, derived from 'data'. It returns
+// an error if the row's source does not match the lookup's source, if defined.
+func (l *BoltLookup) newRow(data interface{}) (map[string]string, error) {
+
+	var row map[string]string
+
+	switch r := data.(type) {
+	case *lcsh.SubjectHeading:
+		row = map[string]string{"id": r.Id, "label": r.Label, "source": lcsh.SOURCE}
+	case *lcnaf.NamedAuthority:
+		row = map[string]string{"id": r.Id, "label": r.Label, "source": lcnaf.SOURCE}
+	case libraryofcongress.Record:
+		row = map[string]string{"id": r.GetId(), "label": r.GetLabel(), "source": r.GetSource()}
+	case map[string]string:
+		row = map[string]string{"id": r["id"], "label": r["label"], "source": r["source"]}
+	default:
+		return nil, fmt.Errorf("Unsupported record type %T", data)
+	}
+
+	for _, k := range []string{"id", "label", "source"} {
+
+		if row[k] == "" {
+			return nil, fmt.Errorf("Record is missing %s", k)
+		}
+	}
+
+	_, err := newRecord(row["id"], row["label"], row["source"])
+
+	if err != nil {
+		return nil, err
+	}
+
+	if l.source != "" && row["source"] != l.source {
+		return nil, fmt.Errorf("Record source '%s' does not match lookup source '%s'", row["source"], l.source)
+	}
+
+	return row, nil
+}
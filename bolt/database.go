@@ -0,0 +1,228 @@
+package bolt
+
+import (
+	"compress/bzip2"
+	"context"
+	"fmt"
+	"github.com/sfomuseum/go-csvdict"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	bbolt "go.etcd.io/bbolt"
+	"io"
+)
+
+// IMPORT_BATCH_SIZE is the number of records written in each transaction by the `Import` function.
+const IMPORT_BATCH_SIZE int = 10000
+
+// CreateDatabase() opens (creating it if necessary) the bbolt database at 'path' and ensures that it contains the
+// buckets used by `BoltLookup`. Labels will be normalized using the normalizer specification 'normalize_spec' (see
+// `libraryofcongress.NewNormalizeFunc`). It is an error to change the normalizer specification of an existing database.
+func CreateDatabase(ctx context.Context, path string, normalize_spec string) (*bbolt.DB, error) {
+
+	if normalize_spec == "" {
+		normalize_spec = libraryofcongress.DEFAULT_NORMALIZER
+	}
+
+	_, err := libraryofcongress.NewNormalizeFunc(normalize_spec)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create normalizer, %w", err)
+	}
+
+	db, err := bbolt.Open(path, 0644, &bbolt.Options{Timeout: OPEN_TIMEOUT})
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open database, %w", err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+
+		for _, name := range []string{RECORDS_BUCKET, LABELS_BUCKET, META_BUCKET} {
+
+			_, err := tx.CreateBucketIfNotExists([]byte(name))
+
+			if err != nil {
+				return fmt.Errorf("Failed to create %s bucket, %w", name, err)
+			}
+		}
+
+		meta := tx.Bucket([]byte(META_BUCKET))
+		existing := meta.Get([]byte(NORMALIZE_KEY))
+
+		if existing != nil {
+
+			if string(existing) != normalize_spec {
+				return fmt.Errorf("Database labels are normalized using '%s' not '%s'", existing, normalize_spec)
+			}
+
+			return nil
+		}
+
+		return meta.Put([]byte(NORMALIZE_KEY), []byte(normalize_spec))
+	})
+
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// Import() adds the records in 'r', bzip2-compressed CSV data with "id" and "label" columns (as returned by the
+// `lcsh.OpenData` and `lcnaf.OpenData` functions), to 'db' labeling them as belonging to 'source'. It returns the
+// number of records imported.
+func Import(ctx context.Context, db *bbolt.DB, source string, r io.Reader) (int64, error) {
+
+	_, err := newRecord("", "", source)
+
+	if err != nil {
+		return 0, err
+	}
+
+	csv_r, err := csvdict.NewReader(bzip2.NewReader(r))
+
+	if err != nil {
+		return 0, fmt.Errorf("Failed to create CSV reader, %w", err)
+	}
+
+	count := int64(0)
+	batch := make([]map[string]string, 0, IMPORT_BATCH_SIZE)
+
+	for {
+
+		select {
+		case <-ctx.Done():
+			return count, ctx.Err()
+		default:
+			// pass
+		}
+
+		row, err := csv_r.Read()
+
+		if err != nil && err != io.EOF {
+			return count, fmt.Errorf("Failed to read row, %w", err)
+		}
+
+		if row != nil {
+			batch = append(batch, row)
+		}
+
+		if len(batch) == IMPORT_BATCH_SIZE || (err == io.EOF && len(batch) > 0) {
+
+			write_err := writeBatch(db, source, batch)
+
+			if write_err != nil {
+				return count, write_err
+			}
+
+			count += int64(len(batch))
+			batch = batch[:0]
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	return count, nil
+}
+
+// writeBatch() adds 'rows' to 'db' in a single transaction.
+func writeBatch(db *bbolt.DB, source string, rows []map[string]string) error {
+
+	return db.Update(func(tx *bbolt.Tx) error {
+
+		normalize, err := normalizeFunc(tx)
+
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+
+			err := putRecord(tx, normalize, row["id"], source, row["label"])
+
+			if err != nil {
+				return fmt.Errorf("Failed to add record %s, %w", row["id"], err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// putRecord() adds a record to the records bucket and a pointer to it, keyed by its normalized label, to the labels bucket.
+func putRecord(tx *bbolt.Tx, normalize libraryofcongress.NormalizeFunc, id string, source string, label string) error {
+
+	record_key := recordKey(id, source, label)
+
+	err := tx.Bucket([]byte(RECORDS_BUCKET)).Put(record_key, []byte{})
+
+	if err != nil {
+		return err
+	}
+
+	if label == "" {
+		return nil
+	}
+
+	return tx.Bucket([]byte(LABELS_BUCKET)).Put(labelKey(normalize(label), record_key), []byte{})
+}
+
+// deleteRecords() removes all the records whose identifier is 'id', and whose source is 'source' (or any source
+// if empty), and the pointers to them from the labels bucket. It returns the number of records removed.
+func deleteRecords(tx *bbolt.Tx, normalize libraryofcongress.NormalizeFunc, id string, source string) (int, error) {
+
+	records := tx.Bucket([]byte(RECORDS_BUCKET))
+	labels := tx.Bucket([]byte(LABELS_BUCKET))
+
+	// Collect keys first since deleting keys while iterating over a cursor may cause keys to be skipped.
+
+	record_keys := make([][]byte, 0)
+
+	err := scanPrefix(records, prefixKey(id), func(k []byte) error {
+
+		// Keys are only valid for the life of the transaction so make a copy
+
+		record_key := append([]byte{}, k...)
+
+		_, record_source, _, err := parseRecordKey(record_key)
+
+		if err != nil {
+			return err
+		}
+
+		if source == "" || record_source == source {
+			record_keys = append(record_keys, record_key)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	for _, record_key := range record_keys {
+
+		_, _, label, _ := parseRecordKey(record_key)
+
+		err := records.Delete(record_key)
+
+		if err != nil {
+			return 0, err
+		}
+
+		if label == "" {
+			continue
+		}
+
+		err = labels.Delete(labelKey(normalize(label), record_key))
+
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(record_keys), nil
+}
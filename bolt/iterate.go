@@ -0,0 +1,45 @@
+package bolt
+
+import (
+	"context"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	bbolt "go.etcd.io/bbolt"
+)
+
+// Iterate() invokes 'cb' for each record in the database (restricted to the lookup's source, if defined). Records are
+// returned sorted by identifier. Iteration happens inside a read-only transaction so 'cb' must not modify the lookup.
+func (l *BoltLookup) Iterate(ctx context.Context, cb libraryofcongress.IterateFunc) error {
+
+	return l.db.View(func(tx *bbolt.Tx) error {
+
+		c := tx.Bucket([]byte(RECORDS_BUCKET)).Cursor()
+
+		for k, _ := c.First(); k != nil; k, _ = c.Next() {
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+				// pass
+			}
+
+			r, err := l.record(k)
+
+			if err != nil {
+				return err
+			}
+
+			if r == nil {
+				continue
+			}
+
+			err = cb(ctx, r.(libraryofcongress.Record))
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,39 @@
+package bolt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// KEY_SEPARATOR is the string used to separate the components of the keys stored in the records and labels buckets.
+const KEY_SEPARATOR string = "\x00"
+
+// recordKey() returns the key used to store a record in the records bucket. Keys take the form of
+// "{ID}\x00{SOURCE}\x00{LABEL}" so that all the records for an identifier can be found using a prefix scan.
+func recordKey(id string, source string, label string) []byte {
+	return []byte(id + KEY_SEPARATOR + source + KEY_SEPARATOR + label)
+}
+
+// labelKey() returns the key used to store a pointer to the record with key 'record_key' in the labels bucket.
+// Keys take the form of "{NORMALIZED_LABEL}\x00{RECORD_KEY}" so that all the records for a normalized label can
+// be found using a prefix scan.
+func labelKey(normalized string, record_key []byte) []byte {
+	return []byte(normalized + KEY_SEPARATOR + string(record_key))
+}
+
+// prefixKey() returns the prefix used to scan for all the keys whose first component is 'v'.
+func prefixKey(v string) []byte {
+	return []byte(v + KEY_SEPARATOR)
+}
+
+// parseRecordKey() returns the identifier, source and label encoded in 'k'.
+func parseRecordKey(k []byte) (string, string, string, error) {
+
+	parts := strings.SplitN(string(k), KEY_SEPARATOR, 3)
+
+	if len(parts) != 3 {
+		return "", "", "", fmt.Errorf("Invalid record key '%s'", k)
+	}
+
+	return parts[0], parts[1], parts[2], nil
+}
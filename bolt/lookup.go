@@ -0,0 +1,283 @@
+// Package bolt implements the `libraryofcongress.Lookup` interface for LoC records stored in a bbolt (embedded,
+// pure-Go key/value store) database. Databases are created using the `to-bolt` tool or the `CreateDatabase` and
+// `Import` functions.
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcnaf"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcsh"
+	bbolt "go.etcd.io/bbolt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// RECORDS_BUCKET is the name of the bucket containing (identifier, source, label) records.
+const RECORDS_BUCKET string = "records"
+
+// LABELS_BUCKET is the name of the bucket containing (normalized label, record) pointers.
+const LABELS_BUCKET string = "labels"
+
+// META_BUCKET is the name of the bucket containing database metadata.
+const META_BUCKET string = "meta"
+
+// NORMALIZE_KEY is the key, in the meta bucket, for the normalizer specification used to normalize labels.
+const NORMALIZE_KEY string = "normalize"
+
+// OPEN_TIMEOUT is the amount of time to wait to obtain a lock on a database before giving up.
+const OPEN_TIMEOUT time.Duration = 1 * time.Second
+
+// type BoltLookup implements the `libraryofcongress.Lookup` interface for records stored in a bbolt database.
+type BoltLookup struct {
+	libraryofcongress.Lookup
+	db        *bbolt.DB
+	normalize libraryofcongress.NormalizeFunc
+	source    string
+}
+
+// type BoltLookupOptions defines configuration options for `BoltLookup` instances.
+type BoltLookupOptions struct {
+	// Source is the (optional) name of the LoC data source to restrict results to. Valid options are "lcsh" and "lcnaf".
+	// If empty results from all the sources in the database are returned.
+	Source string
+}
+
+func init() {
+	ctx := context.Background()
+	libraryofcongress.RegisterLookup(ctx, "bolt", NewBoltLookup)
+}
+
+// NewBoltLookup() returns a new `BoltLookup` instance configured by 'uri' which is expected to take the form of:
+//
+//	bolt:///path/to/libraryofcongress.bolt?{PARAMETERS}
+//
+// Where {PARAMETERS} may be:
+// * `?source=` An optional LoC data source ("lcsh" or "lcnaf") to restrict results to.
+// * `?readonly=` An optional boolean flag to open the database in read-only mode. Multiple processes may open the same database in read-only mode.
+//
+// Labels are normalized using the normalizers recorded in the database when it was created.
+func NewBoltLookup(ctx context.Context, uri string) (libraryofcongress.Lookup, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	bolt_opts := &bbolt.Options{
+		Timeout: OPEN_TIMEOUT,
+	}
+
+	if q.Has("readonly") {
+
+		readonly, err := strconv.ParseBool(q.Get("readonly"))
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid ?readonly= parameter, %w", err)
+		}
+
+		bolt_opts.ReadOnly = readonly
+	}
+
+	db, err := bbolt.Open(u.Path, 0644, bolt_opts)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open database, %w", err)
+	}
+
+	opts := &BoltLookupOptions{
+		Source: q.Get("source"),
+	}
+
+	l, err := NewBoltLookupWithDatabaseAndOptions(ctx, db, opts)
+
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// NewBoltLookupWithDatabaseAndOptions() returns a new `BoltLookup` instance for 'db' configured using 'opts'.
+func NewBoltLookupWithDatabaseAndOptions(ctx context.Context, db *bbolt.DB, opts *BoltLookupOptions) (libraryofcongress.Lookup, error) {
+
+	switch opts.Source {
+	case "", lcsh.SOURCE, lcnaf.SOURCE:
+		// pass
+	default:
+		return nil, fmt.Errorf("Unsupported source, %s", opts.Source)
+	}
+
+	var normalize libraryofcongress.NormalizeFunc
+
+	err := db.View(func(tx *bbolt.Tx) error {
+
+		for _, name := range []string{RECORDS_BUCKET, LABELS_BUCKET, META_BUCKET} {
+
+			if tx.Bucket([]byte(name)) == nil {
+				return fmt.Errorf("Database is missing %s bucket", name)
+			}
+		}
+
+		fn, err := normalizeFunc(tx)
+
+		if err != nil {
+			return err
+		}
+
+		normalize = fn
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	l := &BoltLookup{
+		db:        db,
+		normalize: normalize,
+		source:    opts.Source,
+	}
+
+	return l, nil
+}
+
+// Find() returns the records whose identifier, or normalized label, matches 'code'.
+func (l *BoltLookup) Find(ctx context.Context, code string) ([]interface{}, error) {
+
+	results := make([]interface{}, 0)
+
+	err := l.db.View(func(tx *bbolt.Tx) error {
+
+		seen := make(map[string]bool)
+
+		cb := func(record_key []byte) error {
+
+			if seen[string(record_key)] {
+				return nil
+			}
+
+			seen[string(record_key)] = true
+
+			r, err := l.record(record_key)
+
+			if err != nil {
+				return err
+			}
+
+			if r != nil {
+				results = append(results, r)
+			}
+
+			return nil
+		}
+
+		err := scanPrefix(tx.Bucket([]byte(RECORDS_BUCKET)), prefixKey(code), cb)
+
+		if err != nil {
+			return err
+		}
+
+		label_prefix := prefixKey(l.normalize(code))
+
+		return scanPrefix(tx.Bucket([]byte(LABELS_BUCKET)), label_prefix, func(k []byte) error {
+			return cb(k[len(label_prefix):])
+		})
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query database, %w", err)
+	}
+
+	if len(results) == 0 {
+		return nil, l.notFound(code)
+	}
+
+	return results, nil
+}
+
+// Close() closes the underlying database.
+func (l *BoltLookup) Close() error {
+	return l.db.Close()
+}
+
+// record() returns the record encoded in 'record_key' as an `lcsh.SubjectHeading` or `lcnaf.NamedAuthority`
+// instance or nil if the record's source does not match the lookup's source.
+func (l *BoltLookup) record(record_key []byte) (interface{}, error) {
+
+	id, source, label, err := parseRecordKey(record_key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if l.source != "" && source != l.source {
+		return nil, nil
+	}
+
+	return newRecord(id, label, source)
+}
+
+// notFound() returns a `NotFound` error for 'code' specific to the lookup's source, if defined.
+func (l *BoltLookup) notFound(code string) error {
+
+	switch l.source {
+	case lcsh.SOURCE:
+		return lcsh.NotFound{Code: code}
+	case lcnaf.SOURCE:
+		return lcnaf.NotFound{Code: code}
+	default:
+		return libraryofcongress.NotFound{Code: code}
+	}
+}
+
+// scanPrefix() invokes 'cb' for each key in 'b' which starts with 'prefix'.
+func scanPrefix(b *bbolt.Bucket, prefix []byte, cb func([]byte) error) error {
+
+	c := b.Cursor()
+
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+
+		err := cb(k)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// normalizeFunc() returns the `NormalizeFunc` for the normalizer specification stored in the meta bucket.
+func normalizeFunc(tx *bbolt.Tx) (libraryofcongress.NormalizeFunc, error) {
+
+	spec := tx.Bucket([]byte(META_BUCKET)).Get([]byte(NORMALIZE_KEY))
+
+	normalize, err := libraryofcongress.NewNormalizeFunc(string(spec))
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create normalizer, %w", err)
+	}
+
+	return normalize, nil
+}
+
+// newRecord() returns a new `lcsh.SubjectHeading` or `lcnaf.NamedAuthority` instance, depending on the value of 'source'.
+func newRecord(id string, label string, source string) (interface{}, error) {
+
+	switch source {
+	case lcnaf.SOURCE:
+		return &lcnaf.NamedAuthority{Id: id, Label: label}, nil
+	case lcsh.SOURCE:
+		return &lcsh.SubjectHeading{Id: id, Label: label}, nil
+	default:
+		return nil, fmt.Errorf("Unsupported source, %s", source)
+	}
+}
@@ -0,0 +1,227 @@
+package bolt
+
+import (
+	"context"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcnaf"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcsh"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// createFixtureDatabase() creates a new bbolt database, in a temporary directory, containing the LCSH and LCNAF fixture data
+// and returns its path.
+func createFixtureDatabase(t *testing.T) string {
+
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "libraryofcongress.bolt")
+
+	db, err := CreateDatabase(ctx, path, "")
+
+	if err != nil {
+		t.Fatalf("Failed to create database, %v", err)
+	}
+
+	defer db.Close()
+
+	fixtures := map[string]string{
+		lcsh.SOURCE:  "../fixtures/lcsh.csv.bz2",
+		lcnaf.SOURCE: "../fixtures/lcnaf.csv.bz2",
+	}
+
+	for source, rel_path := range fixtures {
+
+		r, err := os.Open(rel_path)
+
+		if err != nil {
+			t.Fatalf("Failed to open %s, %v", rel_path, err)
+		}
+
+		_, err = Import(ctx, db, source, r)
+
+		r.Close()
+
+		if err != nil {
+			t.Fatalf("Failed to import %s, %v", rel_path, err)
+		}
+	}
+
+	return path
+}
+
+func TestBoltLookup(t *testing.T) {
+
+	ctx := context.Background()
+
+	path := createFixtureDatabase(t)
+
+	l, err := libraryofcongress.NewLookup(ctx, fmt.Sprintf("bolt://%s?readonly=true", path))
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	defer l.(*BoltLookup).Close()
+
+	tests := map[string]int{
+		"Airplanes":            1,
+		"Cooking":              2,
+		"sh2010007517":         2,
+		"n94099999":            1,
+		"Graham, Sean":         1,
+		"Brockmann, Lester C.": 1,
+	}
+
+	for code, expected := range tests {
+
+		results, err := l.Find(ctx, code)
+
+		if err != nil {
+			t.Fatalf("Failed to find %s, %v", code, err)
+		}
+
+		if len(results) != expected {
+			t.Fatalf("Expected %d results for %s, got %d", expected, code, len(results))
+		}
+	}
+
+	_, err = l.Find(ctx, "Zeppelins")
+
+	if !libraryofcongress.IsNotFound(err) {
+		t.Fatalf("Expected NotFound error, got %v", err)
+	}
+
+	count := 0
+
+	err = libraryofcongress.Iterate(ctx, l, func(ctx context.Context, r libraryofcongress.Record) error {
+		count += 1
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("Failed to iterate records, %v", err)
+	}
+
+	if count != 37 {
+		t.Fatalf("Expected 37 records, got %d", count)
+	}
+}
+
+func TestBoltLookupSource(t *testing.T) {
+
+	ctx := context.Background()
+
+	path := createFixtureDatabase(t)
+
+	l, err := libraryofcongress.NewLookup(ctx, fmt.Sprintf("bolt://%s?source=lcnaf", path))
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	defer l.(*BoltLookup).Close()
+
+	results, err := l.Find(ctx, "n94099999")
+
+	if err != nil {
+		t.Fatalf("Failed to find n94099999, %v", err)
+	}
+
+	if _, ok := results[0].(*lcnaf.NamedAuthority); !ok {
+		t.Fatalf("Unexpected record type %T", results[0])
+	}
+
+	_, err = l.Find(ctx, "Airplanes")
+
+	if !lcnaf.IsNotFound(err) {
+		t.Fatalf("Expected LCNAF NotFound error, got %v", err)
+	}
+
+	err = l.Append(ctx, &lcsh.SubjectHeading{Id: "sh00000000", Label: "Zeppelins"})
+
+	if err == nil {
+		t.Fatalf("Expected source mismatch to fail")
+	}
+}
+
+func TestBoltLookupMutable(t *testing.T) {
+
+	ctx := context.Background()
+
+	path := createFixtureDatabase(t)
+
+	l, err := libraryofcongress.NewLookup(ctx, fmt.Sprintf("bolt://%s", path))
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	defer l.(*BoltLookup).Close()
+
+	m, ok := l.(libraryofcongress.MutableLookup)
+
+	if !ok {
+		t.Fatalf("Lookup does not implement MutableLookup")
+	}
+
+	err = m.Append(ctx, &lcsh.SubjectHeading{Id: "sh00000000", Label: "Zeppelins"})
+
+	if err != nil {
+		t.Fatalf("Failed to append record, %v", err)
+	}
+
+	err = m.Update(ctx, &lcsh.SubjectHeading{Id: "sh85002782", Label: "Aeroplanes"})
+
+	if err != nil {
+		t.Fatalf("Failed to update record, %v", err)
+	}
+
+	_, err = l.Find(ctx, "Airplanes")
+
+	if !libraryofcongress.IsNotFound(err) {
+		t.Fatalf("Expected old label to be removed, got %v", err)
+	}
+
+	sh, err := libraryofcongress.FindOne(ctx, l, "Aeroplanes")
+
+	if err != nil {
+		t.Fatalf("Failed to find updated record, %v", err)
+	}
+
+	if sh.(*lcsh.SubjectHeading).Id != "sh85002782" {
+		t.Fatalf("Unexpected record for updated label, %v", sh)
+	}
+
+	err = m.Replace(ctx, "sh00000000", &lcsh.SubjectHeading{Id: "sh00000001", Label: "Airships"})
+
+	if err != nil {
+		t.Fatalf("Failed to replace record, %v", err)
+	}
+
+	_, err = l.Find(ctx, "sh00000000")
+
+	if !libraryofcongress.IsNotFound(err) {
+		t.Fatalf("Expected replaced record to be removed, got %v", err)
+	}
+
+	_, err = libraryofcongress.FindOne(ctx, l, "Airships")
+
+	if err != nil {
+		t.Fatalf("Failed to find replacement record, %v", err)
+	}
+
+	err = m.Remove(ctx, "sh00000001")
+
+	if err != nil {
+		t.Fatalf("Failed to remove record, %v", err)
+	}
+
+	err = m.Remove(ctx, "sh00000001")
+
+	if !libraryofcongress.IsNotFound(err) {
+		t.Fatalf("Expected NotFound error removing missing record, got %v", err)
+	}
+}
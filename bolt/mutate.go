@@ -0,0 +1,85 @@
+package bolt
+
+import (
+	"context"
+	bbolt "go.etcd.io/bbolt"
+)
+
+// Remove() removes the records whose identifier is 'id' from the database. If there is no matching record a
+// "not found" error is returned.
+func (l *BoltLookup) Remove(ctx context.Context, id string) error {
+
+	return l.db.Update(func(tx *bbolt.Tx) error {
+
+		count, err := deleteRecords(tx, l.normalize, id, l.source)
+
+		if err != nil {
+			return err
+		}
+
+		if count == 0 {
+			return l.notFound(id)
+		}
+
+		return nil
+	})
+}
+
+// Update() replaces the records whose identifier matches the identifier of 'data' with 'data', for example when
+// the label for a heading has been changed. If there is no matching record a "not found" error is returned.
+func (l *BoltLookup) Update(ctx context.Context, data interface{}) error {
+
+	row, err := l.newRow(data)
+
+	if err != nil {
+		return err
+	}
+
+	return l.db.Update(func(tx *bbolt.Tx) error {
+
+		count, err := deleteRecords(tx, l.normalize, row["id"], row["source"])
+
+		if err != nil {
+			return err
+		}
+
+		if count == 0 {
+			return l.notFound(row["id"])
+		}
+
+		return putRecord(tx, l.normalize, row["id"], row["source"], row["label"])
+	})
+}
+
+// Replace() removes the records whose identifier is 'id' and adds 'data' in their place, in a single transaction, for
+// example when a heading has been deprecated in favour of a new heading. If there is no record matching 'id' a
+// "not found" error is returned.
+func (l *BoltLookup) Replace(ctx context.Context, id string, data interface{}) error {
+
+	row, err := l.newRow(data)
+
+	if err != nil {
+		return err
+	}
+
+	return l.db.Update(func(tx *bbolt.Tx) error {
+
+		count, err := deleteRecords(tx, l.normalize, id, l.source)
+
+		if err != nil {
+			return err
+		}
+
+		if count == 0 {
+			return l.notFound(id)
+		}
+
+		_, err = deleteRecords(tx, l.normalize, row["id"], row["source"])
+
+		if err != nil {
+			return err
+		}
+
+		return putRecord(tx, l.normalize, row["id"], row["source"], row["label"])
+	})
+}
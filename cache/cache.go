@@ -0,0 +1,469 @@
+// Package cache implements a simple binary cache file format for storing the (identifier, label) records used to populate
+// the in-memory `lcsh://` and `lcnaf://` lookups. Cache files are keyed by the SHA-256 checksum of the (compressed) source
+// data they were derived from so that a change in the source data invalidates the cache.
+package cache
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// MAGIC is the sequence of bytes that every cache file starts with.
+const MAGIC string = "LOCCACHE01"
+
+// RECORD_MARKER is the byte written before each record in a cache file.
+const RECORD_MARKER byte = 0x01
+
+// END_MARKER is the byte written after the last record in a cache file. It is followed by the number of records in the file.
+const END_MARKER byte = 0x00
+
+// MAX_STRING_LENGTH is the maximum length, in bytes, of the strings (identifiers, labels and header values) stored in a cache file.
+const MAX_STRING_LENGTH uint64 = 1024 * 1024
+
+// CACHE_PARAMETER is the name of the query parameter, in lookup URIs, used to define the directory where cache files are stored.
+const CACHE_PARAMETER string = "cache"
+
+// Path() returns the path of the cache file, in 'dir', for data from 'source' whose checksum is 'checksum'.
+func Path(dir string, source string, checksum string) string {
+	return filepath.Join(dir, fmt.Sprintf("%s-%s.cache", source, checksum))
+}
+
+// Spool() copies 'r' to a temporary file in 'dir', creating 'dir' if necessary, and returns that file (positioned at
+// the start of the data) and the hex-encoded SHA-256 checksum of its contents. Callers are expected to close and
+// remove the file when they are done with it. If 'ctx' is cancelled while the data is being copied the spool file is
+// removed and the context's error is returned.
+func Spool(ctx context.Context, dir string, r io.Reader) (*os.File, string, error) {
+
+	err := os.MkdirAll(dir, 0755)
+
+	if err != nil {
+		return nil, "", fmt.Errorf("Failed to create cache directory, %w", err)
+	}
+
+	fh, err := os.CreateTemp(dir, "spool-*")
+
+	if err != nil {
+		return nil, "", fmt.Errorf("Failed to create spool file, %w", err)
+	}
+
+	abandon := func() {
+		fh.Close()
+		os.Remove(fh.Name())
+	}
+
+	h := sha256.New()
+
+	_, err = io.Copy(io.MultiWriter(fh, h), &contextReader{ctx: ctx, r: r})
+
+	if err != nil {
+		abandon()
+		return nil, "", fmt.Errorf("Failed to spool data, %w", err)
+	}
+
+	_, err = fh.Seek(0, io.SeekStart)
+
+	if err != nil {
+		abandon()
+		return nil, "", fmt.Errorf("Failed to rewind spool file, %w", err)
+	}
+
+	return fh, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// type Source is a struct containing the source data that a cache file is derived from, and its checksum.
+type Source struct {
+	// Reader is the source data, positioned at the start of the data.
+	Reader io.ReadSeeker
+	// Checksum is the hex-encoded SHA-256 checksum of the source data.
+	Checksum string
+	// spool is the temporary file the source data was copied to, if it was spooled.
+	spool *os.File
+}
+
+// OpenSource() returns a new `Source` instance for the data in 'r', creating 'dir' if necessary. If 'r' implements the
+// `io.Seeker` interface then its checksum is derived in place and 'r' is rewound, so nothing is copied whether or not
+// there is a cache file for the data. Otherwise the data has to be read in full to derive its checksum so it is spooled
+// to a temporary file in 'dir' (see `Spool`). Callers are expected to invoke the `Close` method once they are done with
+// the source data.
+func OpenSource(ctx context.Context, dir string, r io.Reader) (*Source, error) {
+
+	err := os.MkdirAll(dir, 0755)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create cache directory, %w", err)
+	}
+
+	rs, ok := r.(io.ReadSeeker)
+
+	if ok {
+
+		checksum, err := Checksum(ctx, rs)
+
+		if err != nil {
+			return nil, err
+		}
+
+		s := &Source{
+			Reader:   rs,
+			Checksum: checksum,
+		}
+
+		return s, nil
+	}
+
+	fh, checksum, err := Spool(ctx, dir, r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Source{
+		Reader:   fh,
+		Checksum: checksum,
+		spool:    fh,
+	}
+
+	return s, nil
+}
+
+// Close() removes the temporary file that the source data was spooled to, if any.
+func (s *Source) Close() error {
+
+	if s.spool == nil {
+		return nil
+	}
+
+	s.spool.Close()
+	return os.Remove(s.spool.Name())
+}
+
+// Checksum() returns the hex-encoded SHA-256 checksum of the data in 'r' and then rewinds 'r' to its current position.
+func Checksum(ctx context.Context, r io.ReadSeeker) (string, error) {
+
+	offset, err := r.Seek(0, io.SeekCurrent)
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to determine position of data, %w", err)
+	}
+
+	h := sha256.New()
+
+	_, err = io.Copy(h, &contextReader{ctx: ctx, r: r})
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to derive checksum, %w", err)
+	}
+
+	_, err = r.Seek(offset, io.SeekStart)
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to rewind data, %w", err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Read() invokes 'cb' for each (identifier, label) record in the cache file at 'path'. It returns an error if the
+// file was not created for 'source' and 'checksum' or is otherwise invalid.
+func Read(ctx context.Context, path string, source string, checksum string, cb func(string, string) error) error {
+
+	fh, err := os.Open(path)
+
+	if err != nil {
+		return err
+	}
+
+	defer fh.Close()
+
+	info, err := fh.Stat()
+
+	if err != nil {
+		return fmt.Errorf("Failed to stat cache, %w", err)
+	}
+
+	cr := newCacheReader(fh, info.Size())
+	br := cr.br
+
+	magic := make([]byte, len(MAGIC))
+
+	_, err = io.ReadFull(br, magic)
+
+	if err != nil || string(magic) != MAGIC {
+		return fmt.Errorf("Invalid cache header")
+	}
+
+	header := make([]string, 2)
+
+	for i := range header {
+
+		v, err := cr.readString()
+
+		if err != nil {
+			return fmt.Errorf("Failed to read cache header, %w", err)
+		}
+
+		header[i] = v
+	}
+
+	if header[0] != source || header[1] != checksum {
+		return fmt.Errorf("Cache does not match source data")
+	}
+
+	count := uint64(0)
+
+	for {
+
+		if count%10000 == 0 {
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+				// pass
+			}
+		}
+
+		marker, err := br.ReadByte()
+
+		if err != nil {
+			return fmt.Errorf("Failed to read record marker, %w", err)
+		}
+
+		if marker == END_MARKER {
+			break
+		}
+
+		if marker != RECORD_MARKER {
+			return fmt.Errorf("Invalid record marker")
+		}
+
+		id, err := cr.readString()
+
+		if err != nil {
+			return fmt.Errorf("Failed to read identifier for record %d, %w", count, err)
+		}
+
+		label, err := cr.readString()
+
+		if err != nil {
+			return fmt.Errorf("Failed to read label for record %d, %w", count, err)
+		}
+
+		err = cb(id, label)
+
+		if err != nil {
+			return err
+		}
+
+		count += 1
+	}
+
+	expected, err := binary.ReadUvarint(br)
+
+	if err != nil {
+		return fmt.Errorf("Failed to read record count, %w", err)
+	}
+
+	if expected != count {
+		return fmt.Errorf("Expected %d records but read %d", expected, count)
+	}
+
+	_, err = br.ReadByte()
+
+	if err != io.EOF {
+		return fmt.Errorf("Unexpected data at end of cache")
+	}
+
+	return nil
+}
+
+// type Writer provides methods for creating cache files. Records are written to a temporary file which is only
+// moved in to place, so that other processes never see a partially-written cache file, when the `Close` method is invoked.
+type Writer struct {
+	path  string
+	fh    *os.File
+	bw    *bufio.Writer
+	count uint64
+}
+
+// NewWriter() returns a new `Writer` instance for a cache file at 'path' for data from 'source' whose checksum is 'checksum'.
+func NewWriter(path string, source string, checksum string) (*Writer, error) {
+
+	fh, err := os.CreateTemp(filepath.Dir(path), "cache-*")
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create temporary file, %w", err)
+	}
+
+	bw := bufio.NewWriter(fh)
+
+	bw.WriteString(MAGIC)
+	writeString(bw, source)
+	writeString(bw, checksum)
+
+	wr := &Writer{
+		path: path,
+		fh:   fh,
+		bw:   bw,
+	}
+
+	return wr, nil
+}
+
+// Add() adds a record with identifier 'id' and label 'label' to the cache. It returns an error if either value is longer
+// than `MAX_STRING_LENGTH` bytes.
+func (wr *Writer) Add(id string, label string) error {
+
+	for _, v := range []string{id, label} {
+
+		if uint64(len(v)) > MAX_STRING_LENGTH {
+			return fmt.Errorf("Value exceeds maximum length of %d bytes", MAX_STRING_LENGTH)
+		}
+	}
+
+	wr.bw.WriteByte(RECORD_MARKER)
+	writeString(wr.bw, id)
+	writeString(wr.bw, label)
+
+	wr.count += 1
+
+	// bufio.Writer errors are sticky so there is no need to check every write
+	_, err := wr.bw.Write(nil)
+	return err
+}
+
+// Abort() discards the cache file.
+func (wr *Writer) Abort() error {
+	wr.fh.Close()
+	return os.Remove(wr.fh.Name())
+}
+
+// Close() finishes writing the cache file and moves it in to place.
+func (wr *Writer) Close() error {
+
+	wr.bw.WriteByte(END_MARKER)
+	writeUvarint(wr.bw, wr.count)
+
+	err := wr.bw.Flush()
+
+	if err != nil {
+		wr.Abort()
+		return fmt.Errorf("Failed to write cache, %w", err)
+	}
+
+	// Temporary files are only readable by their owner
+
+	err = wr.fh.Chmod(0644)
+
+	if err != nil {
+		wr.Abort()
+		return fmt.Errorf("Failed to set cache permissions, %w", err)
+	}
+
+	err = wr.fh.Close()
+
+	if err != nil {
+		os.Remove(wr.fh.Name())
+		return fmt.Errorf("Failed to close cache, %w", err)
+	}
+
+	err = os.Rename(wr.fh.Name(), wr.path)
+
+	if err != nil {
+		os.Remove(wr.fh.Name())
+		return fmt.Errorf("Failed to rename cache, %w", err)
+	}
+
+	return nil
+}
+
+// type cacheReader is a struct for reading the values in a cache file which keeps track of how much data is left to read.
+type cacheReader struct {
+	br *bufio.Reader
+	lr *io.LimitedReader
+}
+
+// newCacheReader() returns a new `cacheReader` instance for the 'size' bytes of data in 'r'.
+func newCacheReader(r io.Reader, size int64) *cacheReader {
+
+	lr := &io.LimitedReader{R: r, N: size}
+
+	cr := &cacheReader{
+		br: bufio.NewReader(lr),
+		lr: lr,
+	}
+
+	return cr
+}
+
+// remaining() returns the number of bytes left to read.
+func (cr *cacheReader) remaining() uint64 {
+	return uint64(cr.lr.N) + uint64(cr.br.Buffered())
+}
+
+// readString() reads a length-prefixed string. It returns an error, rather than allocating the string, if the
+// length is greater than `MAX_STRING_LENGTH` or than the amount of data left to read, which is the case for corrupt
+// or truncated cache files.
+func (cr *cacheReader) readString() (string, error) {
+
+	length, err := binary.ReadUvarint(cr.br)
+
+	if err != nil {
+		return "", err
+	}
+
+	if length > MAX_STRING_LENGTH {
+		return "", fmt.Errorf("String length (%d) exceeds maximum length", length)
+	}
+
+	if length > cr.remaining() {
+		return "", fmt.Errorf("String length (%d) exceeds remaining data", length)
+	}
+
+	b := make([]byte, length)
+
+	_, err = io.ReadFull(cr.br, b)
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+func writeString(bw *bufio.Writer, s string) {
+	writeUvarint(bw, uint64(len(s)))
+	bw.WriteString(s)
+}
+
+func writeUvarint(bw *bufio.Writer, v uint64) {
+
+	n := make([]byte, binary.MaxVarintLen64)
+	bw.Write(n[:binary.PutUvarint(n, v)])
+}
+
+// type contextReader is a struct that implements the `io.Reader` interface and stops reading from
+// an underlying `io.Reader` once its context has been cancelled.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+// Read() reads from the underlying reader, returning the context's error if it has been cancelled.
+func (cr *contextReader) Read(p []byte) (int, error) {
+
+	err := cr.ctx.Err()
+
+	if err != nil {
+		return 0, err
+	}
+
+	return cr.r.Read(p)
+}
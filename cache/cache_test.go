@@ -0,0 +1,294 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+
+	ctx := context.Background()
+
+	dir := t.TempDir()
+
+	fh, checksum, err := Spool(ctx, dir, bytes.NewReader([]byte("example data")))
+
+	if err != nil {
+		t.Fatalf("Failed to spool data, %v", err)
+	}
+
+	fh.Close()
+	os.Remove(fh.Name())
+
+	path := Path(dir, "lcsh", checksum)
+
+	wr, err := NewWriter(path, "lcsh", checksum)
+
+	if err != nil {
+		t.Fatalf("Failed to create writer, %v", err)
+	}
+
+	records := [][]string{
+		[]string{"sh85002782", "Airplanes"},
+		[]string{"sh2010007517", "Cooking"},
+		[]string{"sh2010007517", "Cookbooks"},
+	}
+
+	for _, r := range records {
+
+		err := wr.Add(r[0], r[1])
+
+		if err != nil {
+			t.Fatalf("Failed to add record, %v", err)
+		}
+	}
+
+	err = wr.Close()
+
+	if err != nil {
+		t.Fatalf("Failed to close writer, %v", err)
+	}
+
+	read := make([][]string, 0)
+
+	err = Read(ctx, path, "lcsh", checksum, func(id string, label string) error {
+		read = append(read, []string{id, label})
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("Failed to read cache, %v", err)
+	}
+
+	if len(read) != len(records) {
+		t.Fatalf("Expected %d records, got %d", len(records), len(read))
+	}
+
+	for i, r := range records {
+
+		if read[i][0] != r[0] || read[i][1] != r[1] {
+			t.Fatalf("Unexpected record at position %d, %v", i, read[i])
+		}
+	}
+
+	noop := func(id string, label string) error {
+		return nil
+	}
+
+	err = Read(ctx, path, "lcnaf", checksum, noop)
+
+	if err == nil {
+		t.Fatalf("Expected source mismatch to fail")
+	}
+
+	err = Read(ctx, path, "lcsh", "not-the-checksum", noop)
+
+	if err == nil {
+		t.Fatalf("Expected checksum mismatch to fail")
+	}
+
+	// Truncated cache files should fail
+
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		t.Fatalf("Failed to read cache file, %v", err)
+	}
+
+	truncated := filepath.Join(dir, "truncated.cache")
+
+	err = os.WriteFile(truncated, data[:len(data)-8], 0644)
+
+	if err != nil {
+		t.Fatalf("Failed to write truncated cache file, %v", err)
+	}
+
+	err = Read(ctx, truncated, "lcsh", checksum, noop)
+
+	if err == nil {
+		t.Fatalf("Expected truncated cache to fail")
+	}
+}
+
+func TestSpoolCancelled(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	dir := t.TempDir()
+
+	_, _, err := Spool(ctx, dir, bytes.NewReader([]byte("example data")))
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled error, got %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+
+	if err != nil {
+		t.Fatalf("Failed to read cache directory, %v", err)
+	}
+
+	if len(entries) != 0 {
+		t.Fatalf("Expected spool file to be removed, found %d entries", len(entries))
+	}
+}
+
+func TestOpenSource(t *testing.T) {
+
+	ctx := context.Background()
+
+	body := []byte("example data")
+
+	dir := t.TempDir()
+
+	fh, expected, err := Spool(ctx, dir, bytes.NewReader(body))
+
+	if err != nil {
+		t.Fatalf("Failed to spool data, %v", err)
+	}
+
+	fh.Close()
+	os.Remove(fh.Name())
+
+	readers := map[string]io.Reader{
+		"seekable":     bytes.NewReader(body),
+		"not seekable": struct{ io.Reader }{bytes.NewReader(body)},
+	}
+
+	for label, r := range readers {
+
+		src, err := OpenSource(ctx, dir, r)
+
+		if err != nil {
+			t.Fatalf("Failed to open source (%s), %v", label, err)
+		}
+
+		if src.Checksum != expected {
+			t.Fatalf("Unexpected checksum (%s), %s", label, src.Checksum)
+		}
+
+		data, err := io.ReadAll(src.Reader)
+
+		if err != nil {
+			t.Fatalf("Failed to read source (%s), %v", label, err)
+		}
+
+		if !bytes.Equal(data, body) {
+			t.Fatalf("Unexpected source data (%s), %s", label, string(data))
+		}
+
+		// Only data that can't be checksummed in place is spooled
+
+		entries, err := os.ReadDir(dir)
+
+		if err != nil {
+			t.Fatalf("Failed to read cache directory, %v", err)
+		}
+
+		spooled := len(entries) == 1
+
+		if spooled != (label == "not seekable") {
+			t.Fatalf("Unexpected spool files (%s), found %d entries", label, len(entries))
+		}
+
+		err = src.Close()
+
+		if err != nil {
+			t.Fatalf("Failed to close source (%s), %v", label, err)
+		}
+
+		entries, err = os.ReadDir(dir)
+
+		if err != nil {
+			t.Fatalf("Failed to read cache directory, %v", err)
+		}
+
+		if len(entries) != 0 {
+			t.Fatalf("Expected spool file to be removed (%s), found %d entries", label, len(entries))
+		}
+	}
+}
+
+func TestReadCorrupted(t *testing.T) {
+
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	path := Path(dir, "lcsh", "checksum")
+
+	wr, err := NewWriter(path, "lcsh", "checksum")
+
+	if err != nil {
+		t.Fatalf("Failed to create writer, %v", err)
+	}
+
+	err = wr.Add("sh85002782", "Airplanes")
+
+	if err != nil {
+		t.Fatalf("Failed to add record, %v", err)
+	}
+
+	err = wr.Close()
+
+	if err != nil {
+		t.Fatalf("Failed to close writer, %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		t.Fatalf("Failed to read cache file, %v", err)
+	}
+
+	// The length of the record's identifier follows the header and the record marker
+
+	offset := len(MAGIC) + 1 + len("lcsh") + 1 + len("checksum") + 1
+
+	if data[offset] != byte(len("sh85002782")) {
+		t.Fatalf("Unexpected length prefix at offset %d", offset)
+	}
+
+	prefixes := map[string][]byte{
+		"huge":             []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01},
+		"too long":         []byte{0x81, 0x80, 0x80, 0x01},
+		"longer than file": []byte{0xff, 0x01},
+	}
+
+	noop := func(id string, label string) error {
+		return nil
+	}
+
+	for label, prefix := range prefixes {
+
+		corrupted := append([]byte{}, data[:offset]...)
+		corrupted = append(corrupted, prefix...)
+		corrupted = append(corrupted, data[offset+1:]...)
+
+		corrupted_path := filepath.Join(dir, "corrupted.cache")
+
+		err := os.WriteFile(corrupted_path, corrupted, 0644)
+
+		if err != nil {
+			t.Fatalf("Failed to write corrupted cache file, %v", err)
+		}
+
+		err = Read(ctx, corrupted_path, "lcsh", "checksum", noop)
+
+		if err == nil {
+			t.Fatalf("Expected cache with %s length prefix to fail", label)
+		}
+	}
+
+	err = wr.Add(strings.Repeat("x", int(MAX_STRING_LENGTH)+1), "Airplanes")
+
+	if err == nil {
+		t.Fatalf("Expected oversized value to fail")
+	}
+}
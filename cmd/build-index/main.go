@@ -0,0 +1,98 @@
+package main
+
+import (
+	_ "github.com/sfomuseum/go-sfomuseum-libraryofcongress/bolt"
+	_ "github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcnaf"
+	_ "github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcsh"
+	_ "github.com/sfomuseum/go-sfomuseum-libraryofcongress/sqlite"
+	_ "gocloud.dev/blob/fileblob"
+)
+
+import (
+	"context"
+	"flag"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/index"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func main() {
+
+	lookup_uri := flag.String("lookup-uri", "", "A valid libraryofcongress.Lookup URI to read records from.")
+	source := flag.String("source", "", "The LoC data source to index. Valid options are: lcsh, lcnaf. Records from other sources are skipped.")
+	normalize := flag.String("normalize", libraryofcongress.DEFAULT_NORMALIZER, "A comma-separated list of normalizers to apply to labels.")
+	output := flag.String("output", "", "The path to write the index file to.")
+
+	flag.Parse()
+
+	if *source == "" {
+		log.Fatalf("Missing -source flag")
+	}
+
+	if *output == "" {
+		log.Fatalf("Missing -output flag")
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	t1 := time.Now()
+
+	lookup, err := libraryofcongress.NewLookup(ctx, *lookup_uri)
+
+	if err != nil {
+		log.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	log.Printf("Time to load lookup '%s' %v\n", *lookup_uri, time.Since(t1))
+
+	b, err := index.NewBuilder(*source, *normalize)
+
+	if err != nil {
+		log.Fatalf("Failed to create builder, %v", err)
+	}
+
+	count := 0
+
+	err = libraryofcongress.Iterate(ctx, lookup, func(ctx context.Context, r libraryofcongress.Record) error {
+
+		if r.GetSource() != *source {
+			return nil
+		}
+
+		b.Add(r.GetId(), r.GetLabel())
+		count += 1
+
+		return nil
+	})
+
+	if err != nil {
+		log.Fatalf("Failed to iterate records, %v", err)
+	}
+
+	fh, err := os.Create(*output)
+
+	if err != nil {
+		log.Fatalf("Failed to create %s, %v", *output, err)
+	}
+
+	t2 := time.Now()
+
+	err = b.Write(fh)
+
+	if err != nil {
+		log.Fatalf("Failed to write index, %v", err)
+	}
+
+	err = fh.Close()
+
+	if err != nil {
+		log.Fatalf("Failed to close %s, %v", *output, err)
+	}
+
+	log.Printf("Indexed %d records in %v\n", count, time.Since(t2))
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	_ "github.com/sfomuseum/go-sfomuseum-libraryofcongress/bolt"
+	_ "github.com/sfomuseum/go-sfomuseum-libraryofcongress/index"
+	_ "github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcnaf"
+	_ "github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcsh"
+	_ "github.com/sfomuseum/go-sfomuseum-libraryofcongress/sqlite"
+	_ "gocloud.dev/blob/fileblob"
+)
+
+import (
+	"context"
+	"flag"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/export"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func main() {
+
+	lookup_uri := flag.String("lookup-uri", "", "A valid libraryofcongress.Lookup URI.")
+	format := flag.String("format", export.FORMAT_CSV, "The format to export records in. Valid options are: csv, csv.bz2, jsonl.")
+	output := flag.String("output", "-", "The path to write exported records to. If \"-\" then records are written to STDOUT.")
+
+	flag.Parse()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	t1 := time.Now()
+
+	lookup, err := libraryofcongress.NewLookup(ctx, *lookup_uri)
+
+	if err != nil {
+		log.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	log.Printf("Time to load lookup '%s' %v\n", *lookup_uri, time.Since(t1))
+
+	var wr io.Writer
+
+	switch *output {
+	case "-":
+		wr = os.Stdout
+	default:
+
+		fh, err := os.Create(*output)
+
+		if err != nil {
+			log.Fatalf("Failed to create %s, %v", *output, err)
+		}
+
+		defer fh.Close()
+		wr = fh
+	}
+
+	t2 := time.Now()
+
+	count, err := export.Export(ctx, lookup, wr, *format)
+
+	if err != nil {
+		log.Fatalf("Failed to export records, %v", err)
+	}
+
+	log.Printf("Exported %d records in %v\n", count, time.Since(t2))
+}
@@ -1,8 +1,12 @@
 package main
 
 import (
+	_ "github.com/sfomuseum/go-sfomuseum-libraryofcongress/bolt"
+	_ "github.com/sfomuseum/go-sfomuseum-libraryofcongress/index"
 	_ "github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcnaf"
 	_ "github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcsh"
+	_ "github.com/sfomuseum/go-sfomuseum-libraryofcongress/multi"
+	_ "github.com/sfomuseum/go-sfomuseum-libraryofcongress/sqlite"
 )
 
 import (
@@ -10,34 +14,148 @@ import (
 	"flag"
 	"fmt"
 	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/batch"
+	"io"
 	"log"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
 )
 
+// EXIT_NOT_FOUND is the exit code used when one or more inputs failed to resolve and -fail-on-not-found is set (the default).
+const EXIT_NOT_FOUND int = 2
+
+// EXIT_MULTIPLE_CANDIDATES is the exit code used when one or more inputs resolved to multiple records and -fail-on-multiple is set.
+const EXIT_MULTIPLE_CANDIDATES int = 3
+
+// EXIT_LOOKUP_ERROR is the exit code used when looking up one or more inputs failed for any other reason and -fail-on-error is set (the default).
+const EXIT_LOOKUP_ERROR int = 4
+
 func main() {
 
-	lookup_uri := flag.String("lookup-uri", "", "...")
+	lookup_uri := flag.String("lookup-uri", "", "A valid libraryofcongress.Lookup URI.")
+
+	input := flag.String("input", "", "The path to a file containing values to look up. If \"-\" then values will be read from STDIN. If empty then values are read from the command line arguments.")
+	input_format := flag.String("input-format", batch.INPUT_FORMAT_LINES, "The format of the -input data. Valid options are: lines, csv.")
+	column := flag.String("column", "1", "The name (or 1-based position) of the column containing values to look up when -input-format is csv. Column names are only supported if -header is true.")
+	header := flag.Bool("header", true, "Whether the first row of -input data is a header row, which is not looked up, when -input-format is csv.")
+	workers := flag.Int("workers", runtime.NumCPU(), "The number of concurrent lookups to perform.")
+	format := flag.String("format", "", "The output format for results. Valid options are: text, csv, tsv, json, jsonl, uri. If empty then the default is text for command line arguments and csv for -input data.")
+
+	fail_on_not_found := flag.Bool("fail-on-not-found", true, fmt.Sprintf("Exit with status %d if any input fails to resolve. Set to false to exit with status 0 regardless.", EXIT_NOT_FOUND))
+	fail_on_multiple := flag.Bool("fail-on-multiple", false, fmt.Sprintf("Exit with status %d if any input resolves to multiple records.", EXIT_MULTIPLE_CANDIDATES))
+	fail_on_error := flag.Bool("fail-on-error", true, fmt.Sprintf("Exit with status %d if looking up any input fails for a reason other than not finding any records. Set to false to exit with status 0 regardless.", EXIT_LOOKUP_ERROR))
 
 	flag.Parse()
 
-	ctx := context.Background()
+	// Stop resolving inputs, and finish writing the results for the inputs already resolved, if interrupted
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	lookup, err := libraryofcongress.NewLookup(ctx, *lookup_uri)
 
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	for _, code := range flag.Args() {
+	var inputs batch.InputReader
+
+	switch *input {
+	case "":
+
+		inputs = batch.NewSliceReader(flag.Args())
+
+		if *format == "" {
+			*format = batch.FORMAT_TEXT
+		}
+
+	default:
 
-		results, err := lookup.Find(ctx, code)
+		var r io.Reader
+
+		switch *input {
+		case "-":
+			r = os.Stdin
+		default:
+
+			fh, err := os.Open(*input)
+
+			if err != nil {
+				log.Fatalf("Failed to open %s, %v", *input, err)
+			}
+
+			defer fh.Close()
+			r = fh
+		}
+
+		inputs, err = batch.NewInputReader(r, *input_format, *column, *header)
 
 		if err != nil {
-			fmt.Printf("%s *** %s\n", code, err)
-			continue
+			log.Fatalf("Failed to create input reader, %v", err)
 		}
 
-		for _, a := range results {
-			fmt.Println(a)
+		if *format == "" {
+			*format = batch.FORMAT_CSV
 		}
 	}
 
+	wr, err := batch.NewWriter(*format, os.Stdout)
+
+	if err != nil {
+		log.Fatalf("Failed to create writer, %v", err)
+	}
+
+	not_found := false
+	multiple := false
+	lookup_error := false
+
+	// Results are written as soon as they are ready, in input order
+
+	resolve_func := func(ctx context.Context, res *batch.Result) error {
+
+		switch res.Error {
+		case batch.ERROR_MULTIPLE_CANDIDATES:
+			multiple = true
+		case batch.ERROR_NOT_FOUND:
+			not_found = true
+		case batch.ERROR_OTHER:
+			lookup_error = true
+		}
+
+		err := wr.Write(res)
+
+		if err != nil {
+			return fmt.Errorf("Failed to write result for '%s', %w", res.Input, err)
+		}
+
+		return nil
+	}
+
+	resolve_err := batch.ResolveWithReader(ctx, lookup, inputs, *workers, resolve_func)
+
+	// Always close the writer so that the results which were written are terminated properly
+
+	close_err := wr.Close()
+
+	if resolve_err != nil {
+		log.Fatalf("Failed to resolve inputs, %v", resolve_err)
+	}
+
+	if close_err != nil {
+		log.Fatalf("Failed to close writer, %v", close_err)
+	}
+
+	if lookup_error && *fail_on_error {
+		os.Exit(EXIT_LOOKUP_ERROR)
+	}
+
+	if not_found && *fail_on_not_found {
+		os.Exit(EXIT_NOT_FOUND)
+	}
+
+	if multiple && *fail_on_multiple {
+		os.Exit(EXIT_MULTIPLE_CANDIDATES)
+	}
 }
@@ -0,0 +1,248 @@
+package main
+
+import (
+	_ "github.com/sfomuseum/go-sfomuseum-libraryofcongress/bolt"
+	_ "github.com/sfomuseum/go-sfomuseum-libraryofcongress/index"
+	_ "github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcnaf"
+	_ "github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcsh"
+	_ "github.com/sfomuseum/go-sfomuseum-libraryofcongress/multi"
+	_ "github.com/sfomuseum/go-sfomuseum-libraryofcongress/sqlite"
+	_ "gocloud.dev/blob/fileblob"
+)
+
+import (
+	"context"
+	"flag"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	loc_http "github.com/sfomuseum/go-sfomuseum-libraryofcongress/http"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/reconcile"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/watcher"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+func main() {
+
+	lookup_uri := flag.String("lookup-uri", "lcsh://", "A valid libraryofcongress.Lookup URI.")
+	address := flag.String("address", "localhost:8080", "The address (host and port) the server should listen for requests on.")
+	timeout := flag.Duration("timeout", 10*time.Second, "The maximum amount of time to spend processing a request.")
+
+	enable_cors := flag.Bool("enable-cors", false, "Enable Cross-Origin Resource Sharing (CORS) headers.")
+	cors_origins := flag.String("cors-origin", "*", "A comma-separated list of origins to allow CORS requests from.")
+
+	enable_reconcile := flag.Bool("enable-reconcile", false, "Enable the W3C (OpenRefine) Reconciliation Service API endpoints.")
+	reconcile_prefix := flag.String("reconcile-prefix", "/reconcile", "The path to mount the Reconciliation Service API endpoints on.")
+
+	watch := flag.Bool("watch", false, "Reload the lookup when the data for -lookup-uri changes. Only lookups implementing libraryofcongress.ReloadableLookup, whose data is read from a local file or bucket, can be watched.")
+	watch_interval := flag.Duration("watch-interval", watcher.DEFAULT_INTERVAL, "The amount of time between checks for changes to the data for -lookup-uri.")
+
+	flag.Parse()
+
+	ctx := context.Background()
+
+	t1 := time.Now()
+
+	lookup, err := libraryofcongress.NewLookup(ctx, *lookup_uri)
+
+	if err != nil {
+		log.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	// Lookups may be loaded in the background (for example 'lcnaf://?background=true') in which case
+	// the server starts listening for requests immediately and the /ready endpoint reports whether the
+	// lookup can be queried yet. If loading fails the server keeps running, so that /ready can report
+	// the failure, and the lookup can be recovered by reloading it.
+
+	go func() {
+
+		err := libraryofcongress.Wait(ctx, lookup)
+
+		if err != nil {
+			log.Printf("Failed to load lookup '%s', %v", *lookup_uri, err)
+			return
+		}
+
+		log.Printf("Time to load lookup '%s' %v\n", *lookup_uri, time.Since(t1))
+	}()
+
+	// Reload the lookup when the process receives a SIGHUP signal
+
+	go func() {
+
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, syscall.SIGHUP)
+
+		for range ch {
+
+			t1 := time.Now()
+
+			err := libraryofcongress.Reload(ctx, lookup, *lookup_uri)
+
+			if err != nil {
+				log.Printf("Failed to reload lookup '%s', %v", *lookup_uri, err)
+				continue
+			}
+
+			log.Printf("Time to reload lookup '%s' %v\n", *lookup_uri, time.Since(t1))
+		}
+	}()
+
+	if *watch {
+
+		watcher_opts := &watcher.WatcherOptions{
+			Interval: *watch_interval,
+			Reloaded: func(ctx context.Context, err error) {
+
+				if err != nil {
+					log.Printf("Failed to reload lookup '%s', %v", *lookup_uri, err)
+					return
+				}
+
+				log.Printf("Reloaded lookup '%s'\n", *lookup_uri)
+			},
+		}
+
+		w, err := watcher.NewWatcher(ctx, lookup, *lookup_uri, watcher_opts)
+
+		if err != nil {
+			log.Fatalf("Failed to create watcher, %v", err)
+		}
+
+		go func() {
+
+			err := w.Watch(ctx)
+
+			if err != nil {
+				log.Printf("Stopped watching lookup '%s', %v", *lookup_uri, err)
+			}
+		}()
+	}
+
+	find_handler, err := loc_http.FindHandler(lookup)
+
+	if err != nil {
+		log.Fatalf("Failed to create find handler, %v", err)
+	}
+
+	id_handler, err := loc_http.IdHandler(lookup)
+
+	if err != nil {
+		log.Fatalf("Failed to create id handler, %v", err)
+	}
+
+	health_handler, err := loc_http.HealthHandler()
+
+	if err != nil {
+		log.Fatalf("Failed to create health handler, %v", err)
+	}
+
+	ready_handler, err := loc_http.ReadyHandler(lookup)
+
+	if err != nil {
+		log.Fatalf("Failed to create ready handler, %v", err)
+	}
+
+	wrap := func(h http.Handler) http.Handler {
+
+		h = http.TimeoutHandler(h, *timeout, "Request timed out")
+
+		if *enable_cors {
+			h = loc_http.CORSHandler(h, strings.Split(*cors_origins, ","))
+		}
+
+		return h
+	}
+
+	mux := http.NewServeMux()
+
+	mux.Handle("/find", wrap(find_handler))
+	mux.Handle("/id/", wrap(id_handler))
+	mux.Handle("/health", health_handler)
+	mux.Handle("/ready", ready_handler)
+
+	if *enable_reconcile {
+
+		prefix := strings.TrimRight(*reconcile_prefix, "/")
+
+		reconcile_service, err := reconcile.NewService(ctx, lookup)
+
+		if err != nil {
+			log.Fatalf("Failed to create reconcile service, %v", err)
+		}
+
+		reconcile_handler, err := loc_http.ReconcileHandler(reconcile_service, prefix)
+
+		if err != nil {
+			log.Fatalf("Failed to create reconcile handler, %v", err)
+		}
+
+		preview_handler, err := loc_http.ReconcilePreviewHandler(reconcile_service)
+
+		if err != nil {
+			log.Fatalf("Failed to create reconcile preview handler, %v", err)
+		}
+
+		suggest_handler, err := loc_http.ReconcileSuggestHandler(reconcile_service)
+
+		if err != nil {
+			log.Fatalf("Failed to create reconcile suggest handler, %v", err)
+		}
+
+		view_handler, err := loc_http.ReconcileViewHandler(reconcile_service)
+
+		if err != nil {
+			log.Fatalf("Failed to create reconcile view handler, %v", err)
+		}
+
+		mux.Handle(prefix, wrap(reconcile_handler))
+		mux.Handle(prefix+"/preview", wrap(preview_handler))
+		mux.Handle(prefix+"/suggest/entity", wrap(suggest_handler))
+		mux.Handle(prefix+"/view/", wrap(view_handler))
+	}
+
+	s := &http.Server{
+		Addr:              *address,
+		Handler:           mux,
+		ReadHeaderTimeout: *timeout,
+		WriteTimeout:      *timeout + time.Second,
+	}
+
+	// ListenAndServe returns as soon as Shutdown is invoked so wait for in-flight requests
+	// to finish draining before exiting.
+
+	shutdown_done := make(chan struct{})
+
+	go func() {
+
+		defer close(shutdown_done)
+
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+
+		<-ch
+
+		shutdown_ctx, cancel := context.WithTimeout(ctx, *timeout)
+		defer cancel()
+
+		err := s.Shutdown(shutdown_ctx)
+
+		if err != nil {
+			log.Printf("Failed to shut down server cleanly, %v", err)
+		}
+	}()
+
+	log.Printf("Listening for requests on http://%s\n", *address)
+
+	err = s.ListenAndServe()
+
+	if err != nil && err != http.ErrServerClosed {
+		log.Fatalf("Failed to serve requests, %v", err)
+	}
+
+	<-shutdown_done
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	_ "gocloud.dev/blob/fileblob"
+)
+
+import (
+	"context"
+	"flag"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/bolt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcnaf"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcsh"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func main() {
+
+	path := flag.String("path", "libraryofcongress.bolt", "The output path for the new bbolt database.")
+	normalize := flag.String("normalize", libraryofcongress.DEFAULT_NORMALIZER, "A comma-separated list of normalizers to apply to labels.")
+	lcsh_uri := flag.String("lcsh-data-uri", "lcsh://", "A valid lcsh.OpenData URI. If empty LCSH data will not be imported.")
+	lcnaf_uri := flag.String("lcnaf-data-uri", "lcnaf://", "A valid lcnaf.OpenData URI. If empty LCNAF data will not be imported.")
+
+	flag.Parse()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	db, err := bolt.CreateDatabase(ctx, *path, *normalize)
+
+	if err != nil {
+		log.Fatalf("Failed to create database, %v", err)
+	}
+
+	defer db.Close()
+
+	type open_func func(context.Context, string) (io.ReadCloser, error)
+
+	sources := []struct {
+		source string
+		uri    string
+		open   open_func
+	}{
+		{lcsh.SOURCE, *lcsh_uri, lcsh.OpenData},
+		{lcnaf.SOURCE, *lcnaf_uri, lcnaf.OpenData},
+	}
+
+	for _, s := range sources {
+
+		if s.uri == "" {
+			continue
+		}
+
+		t1 := time.Now()
+
+		r, err := s.open(ctx, s.uri)
+
+		if err != nil {
+			log.Fatalf("Failed to open data for '%s', %v", s.uri, err)
+		}
+
+		count, err := bolt.Import(ctx, db, s.source, r)
+
+		r.Close()
+
+		if err != nil {
+			log.Fatalf("Failed to import %s data, %v", s.source, err)
+		}
+
+		log.Printf("Imported %d %s records in %v\n", count, s.source, time.Since(t1))
+	}
+}
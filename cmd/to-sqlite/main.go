@@ -6,13 +6,15 @@ import (
 	_ "database/sql"
 	"flag"
 	"github.com/aaronland/go-sqlite"
-	"github.com/aaronland/go-sqlite/database"
 	loc_database "github.com/sfomuseum/go-libraryofcongress-database"
 	loc_sqlite "github.com/sfomuseum/go-libraryofcongress-database/sqlite"
 	loc_tables "github.com/sfomuseum/go-libraryofcongress-database/sqlite/tables"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
 	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/data"
 	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcnaf"
 	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcsh"
+	sfom_sqlite "github.com/sfomuseum/go-sfomuseum-libraryofcongress/sqlite"
+	sfom_tables "github.com/sfomuseum/go-sfomuseum-libraryofcongress/sqlite/tables"
 	"github.com/sfomuseum/go-timings"
 	"log"
 	"os"
@@ -22,20 +24,25 @@ func main() {
 
 	index_identifiers := flag.Bool("identifiers", true, "Index the identifiers tables.")
 	index_search := flag.Bool("search", false, "Index the search table.")
+	index_normalized := flag.Bool("normalized", false, "Index the normalized labels table.")
 	index_all := flag.Bool("all", false, "Index all tables.")
 
 	dsn := flag.String("dsn", "libraryofcongress.db", "The output path for the new SQLite database.")
+	driver := flag.String("driver", sfom_sqlite.DEFAULT_DRIVER, "The SQLite database driver to use. Valid options are: sqlite3 (cgo), sqlite (pure Go).")
+
+	normalize := flag.String("normalize", libraryofcongress.DEFAULT_NORMALIZER, "A comma-separated list of normalizers to apply to labels in the normalized labels table.")
 
 	flag.Parse()
 
 	if *index_all {
 		*index_identifiers = true
 		*index_search = true
+		*index_normalized = true
 	}
 
 	ctx := context.Background()
 
-	sqlite_db, err := database.NewDB(ctx, *dsn)
+	sqlite_db, err := sfom_sqlite.NewDB(ctx, *dsn, *driver)
 
 	if err != nil {
 		log.Fatalf("Failed to create new database, %v", err)
@@ -62,7 +69,7 @@ func main() {
 
 	if *index_search {
 
-		search_table, err := loc_tables.NewSearchTableWithDatabase(ctx, sqlite_db)
+		search_table, err := sfom_tables.NewSearchTableWithDatabase(ctx, sqlite_db)
 
 		if err != nil {
 			log.Fatalf("Failed to create search table, %v", err)
@@ -71,6 +78,23 @@ func main() {
 		tables = append(tables, search_table)
 	}
 
+	if *index_normalized {
+
+		normalize_func, err := libraryofcongress.NewNormalizeFunc(*normalize)
+
+		if err != nil {
+			log.Fatalf("Failed to create normalizer, %v", err)
+		}
+
+		normalized_table, err := sfom_tables.NewNormalizedTableWithDatabase(ctx, sqlite_db, normalize_func)
+
+		if err != nil {
+			log.Fatalf("Failed to create normalized table, %v", err)
+		}
+
+		tables = append(tables, normalized_table)
+	}
+
 	//
 
 	data_sources := make([]*loc_database.Source, 0)
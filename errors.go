@@ -0,0 +1,112 @@
+package libraryofcongress
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotFound is a sentinel error for representing missing LoC records. All of the "not found" errors returned by
+// the `Lookup` implementations in this package (and its sub-packages) match ErrNotFound when tested using `errors.Is`.
+var ErrNotFound = errors.New("not found")
+
+// ErrMultipleCandidates is a sentinel error for representing LoC identifiers (or labels) that return multiple records
+// where only one was expected. All of the "multiple candidates" errors returned by this package (and its sub-packages)
+// match ErrMultipleCandidates when tested using `errors.Is`.
+var ErrMultipleCandidates = errors.New("multiple candidates")
+
+// ErrNotReady is a sentinel error for representing lookups which are still being loaded. All of the "not ready" errors
+// returned by the `Lookup` implementations in this package (and its sub-packages) match ErrNotReady when tested using `errors.Is`.
+var ErrNotReady = errors.New("not ready")
+
+// type NotFound is a struct for representing LoC identifiers (or labels) that return no records.
+type NotFound struct {
+	Code string
+	// Source is the (optional) name of the LoC data source that was searched.
+	Source string
+}
+
+// Error() returns a stringified representation of 'e'.
+func (e NotFound) Error() string {
+
+	if e.Source != "" {
+		return fmt.Sprintf("Record '%s' not found in %s", e.Code, e.Source)
+	}
+
+	return fmt.Sprintf("Record '%s' not found", e.Code)
+}
+
+// Is() returns a boolean value indicating whether 'target' is `ErrNotFound`.
+func (e NotFound) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// String() returns a stringified representation of 'e'.
+func (e NotFound) String() string {
+	return e.Error()
+}
+
+// type MultipleCandidates is a struct for representing LoC identifiers (or labels) that return multiple records.
+type MultipleCandidates struct {
+	Code string
+	// Candidates is the list of records matching Code.
+	Candidates []interface{}
+}
+
+// Error() returns a stringified representation of 'e'.
+func (e MultipleCandidates) Error() string {
+	return fmt.Sprintf("Multiple (%d) candidates for '%s'", len(e.Candidates), e.Code)
+}
+
+// String() returns a stringified representation of 'e'.
+func (e MultipleCandidates) String() string {
+	return e.Error()
+}
+
+// Is() returns a boolean value indicating whether 'target' is `ErrMultipleCandidates`.
+func (e MultipleCandidates) Is(target error) bool {
+	return target == ErrMultipleCandidates
+}
+
+// type NotReady is a struct for representing lookups which can not be queried because they are still being loaded.
+type NotReady struct {
+	// Source is the (optional) name of the LoC data source being loaded.
+	Source string
+}
+
+// Error() returns a stringified representation of 'e'.
+func (e NotReady) Error() string {
+
+	if e.Source != "" {
+		return fmt.Sprintf("Lookup for %s is not ready", e.Source)
+	}
+
+	return "Lookup is not ready"
+}
+
+// Is() returns a boolean value indicating whether 'target' is `ErrNotReady`.
+func (e NotReady) Is(target error) bool {
+	return target == ErrNotReady
+}
+
+// String() returns a stringified representation of 'e'.
+func (e NotReady) String() string {
+	return e.Error()
+}
+
+// IsNotFound returns a boolean value indicating whether 'e' is, or wraps, a "not found" error from any of the
+// `Lookup` implementations in this package (and its sub-packages).
+func IsNotFound(e error) bool {
+	return errors.Is(e, ErrNotFound)
+}
+
+// IsMultipleCandidates returns a boolean value indicating whether 'e' is, or wraps, a "multiple candidates" error
+// from any of the `Lookup` implementations in this package (and its sub-packages).
+func IsMultipleCandidates(e error) bool {
+	return errors.Is(e, ErrMultipleCandidates)
+}
+
+// IsNotReady returns a boolean value indicating whether 'e' is, or wraps, a "not ready" error from any of the
+// `Lookup` implementations in this package (and its sub-packages).
+func IsNotReady(e error) bool {
+	return errors.Is(e, ErrNotReady)
+}
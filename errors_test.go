@@ -0,0 +1,68 @@
+package libraryofcongress
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestNotFound(t *testing.T) {
+
+	e := fmt.Errorf("Failed to find record, %w", NotFound{Code: "1234"})
+
+	if !IsNotFound(e) {
+		t.Fatalf("Expected wrapped error to be NotFound")
+	}
+
+	if !errors.Is(e, ErrNotFound) {
+		t.Fatalf("Expected wrapped error to match ErrNotFound")
+	}
+
+	if IsMultipleCandidates(e) {
+		t.Fatalf("Expected error to not be MultipleCandidates")
+	}
+
+	if IsNotFound(fmt.Errorf("Testing")) {
+		t.Fatalf("Expected error to not be NotFound")
+	}
+}
+
+func TestMultipleCandidates(t *testing.T) {
+
+	e := fmt.Errorf("Failed to find record, %w", &MultipleCandidates{Code: "1234", Candidates: []interface{}{"a", "b"}})
+
+	if !IsMultipleCandidates(e) {
+		t.Fatalf("Expected wrapped error to be MultipleCandidates")
+	}
+
+	var mc *MultipleCandidates
+
+	if !errors.As(e, &mc) {
+		t.Fatalf("Expected wrapped error to be convertible to MultipleCandidates")
+	}
+
+	if len(mc.Candidates) != 2 {
+		t.Fatalf("Unexpected candidates, %v", mc.Candidates)
+	}
+
+	if IsNotFound(e) {
+		t.Fatalf("Expected error to not be NotFound")
+	}
+}
+
+func TestNotReady(t *testing.T) {
+
+	e := fmt.Errorf("Failed to find record, %w", NotReady{Source: "lcnaf"})
+
+	if !IsNotReady(e) {
+		t.Fatalf("Expected wrapped error to be NotReady")
+	}
+
+	if IsNotFound(e) {
+		t.Fatalf("Expected error to not be NotFound")
+	}
+
+	if e.Error() != "Failed to find record, Lookup for lcnaf is not ready" {
+		t.Fatalf("Unexpected error string, %s", e.Error())
+	}
+}
@@ -0,0 +1,144 @@
+// Package export provides methods for writing all the records in a `libraryofcongress.Lookup` instance to CSV or
+// JSONL data.
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/dsnet/compress/bzip2"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"io"
+)
+
+const (
+	// FORMAT_CSV is the export format for CSV data.
+	FORMAT_CSV string = "csv"
+	// FORMAT_CSV_BZ2 is the export format for bzip2-compressed CSV data. This is the same format as the data
+	// files used by the `lcsh://` and `lcnaf://` lookups.
+	FORMAT_CSV_BZ2 string = "csv.bz2"
+	// FORMAT_JSONL is the export format for newline-delimited JSON data.
+	FORMAT_JSONL string = "jsonl"
+)
+
+// type Row is a struct for encoding exported records.
+type Row struct {
+	// Id is the unique identifier for the LoC record.
+	Id string `json:"id"`
+	// Label is the name (or title) for the LoC record.
+	Label string `json:"label"`
+	// Source is the name of the LoC data source for the record.
+	Source string `json:"source"`
+}
+
+// Export() writes all the records in 'l', which must implement the `libraryofcongress.IterableLookup` interface, to
+// 'wr' encoded as 'format'. It returns the number of records written.
+func Export(ctx context.Context, l libraryofcongress.Lookup, wr io.Writer, format string) (int64, error) {
+
+	switch format {
+	case FORMAT_CSV:
+		return exportCSV(ctx, l, wr)
+	case FORMAT_CSV_BZ2:
+
+		bz_wr, err := bzip2.NewWriter(wr, &bzip2.WriterConfig{Level: bzip2.BestCompression})
+
+		if err != nil {
+			return 0, fmt.Errorf("Failed to create bzip2 writer, %w", err)
+		}
+
+		count, err := exportCSV(ctx, l, bz_wr)
+
+		if err != nil {
+			return count, err
+		}
+
+		err = bz_wr.Close()
+
+		if err != nil {
+			return count, fmt.Errorf("Failed to close bzip2 writer, %w", err)
+		}
+
+		return count, nil
+
+	case FORMAT_JSONL:
+		return exportJSONL(ctx, l, wr)
+	default:
+		return 0, fmt.Errorf("Unsupported format '%s'", format)
+	}
+}
+
+// exportCSV() writes all the records in 'l' to 'wr' as CSV data with "id", "label" and "source" columns.
+func exportCSV(ctx context.Context, l libraryofcongress.Lookup, wr io.Writer) (int64, error) {
+
+	csv_wr := csv.NewWriter(wr)
+
+	err := csv_wr.Write([]string{"id", "label", "source"})
+
+	if err != nil {
+		return 0, fmt.Errorf("Failed to write header, %w", err)
+	}
+
+	count := int64(0)
+
+	cb := func(ctx context.Context, r libraryofcongress.Record) error {
+
+		err := csv_wr.Write([]string{r.GetId(), r.GetLabel(), r.GetSource()})
+
+		if err != nil {
+			return fmt.Errorf("Failed to write %s, %w", r.GetId(), err)
+		}
+
+		count += 1
+		return nil
+	}
+
+	err = libraryofcongress.Iterate(ctx, l, cb)
+
+	if err != nil {
+		return count, fmt.Errorf("Failed to iterate records, %w", err)
+	}
+
+	csv_wr.Flush()
+
+	err = csv_wr.Error()
+
+	if err != nil {
+		return count, fmt.Errorf("Failed to flush CSV writer, %w", err)
+	}
+
+	return count, nil
+}
+
+// exportJSONL() writes all the records in 'l' to 'wr' as newline-delimited JSON data.
+func exportJSONL(ctx context.Context, l libraryofcongress.Lookup, wr io.Writer) (int64, error) {
+
+	enc := json.NewEncoder(wr)
+	count := int64(0)
+
+	cb := func(ctx context.Context, r libraryofcongress.Record) error {
+
+		row := &Row{
+			Id:     r.GetId(),
+			Label:  r.GetLabel(),
+			Source: r.GetSource(),
+		}
+
+		err := enc.Encode(row)
+
+		if err != nil {
+			return fmt.Errorf("Failed to write %s, %w", r.GetId(), err)
+		}
+
+		count += 1
+		return nil
+	}
+
+	err := libraryofcongress.Iterate(ctx, l, cb)
+
+	if err != nil {
+		return count, fmt.Errorf("Failed to iterate records, %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,84 @@
+package export
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	_ "github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcsh"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+
+	ctx := context.Background()
+
+	rel_path := "../fixtures/lcsh.csv.bz2"
+	abs_path, err := filepath.Abs(rel_path)
+
+	if err != nil {
+		t.Fatalf("Failed to derive absolute path for %s, %v", rel_path, err)
+	}
+
+	l, err := libraryofcongress.NewLookup(ctx, fmt.Sprintf("lcsh://file%s", abs_path))
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	var jsonl_buf bytes.Buffer
+
+	jsonl_count, err := Export(ctx, l, &jsonl_buf, FORMAT_JSONL)
+
+	if err != nil {
+		t.Fatalf("Failed to export JSONL, %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(jsonl_buf.String()), "\n")
+
+	if int64(len(lines)) != jsonl_count {
+		t.Fatalf("Expected %d lines, got %d", jsonl_count, len(lines))
+	}
+
+	var bz2_buf bytes.Buffer
+
+	bz2_count, err := Export(ctx, l, &bz2_buf, FORMAT_CSV_BZ2)
+
+	if err != nil {
+		t.Fatalf("Failed to export bzip2-compressed CSV, %v", err)
+	}
+
+	if bz2_count != jsonl_count {
+		t.Fatalf("Expected %d records, got %d", jsonl_count, bz2_count)
+	}
+
+	rows, err := csv.NewReader(bzip2.NewReader(&bz2_buf)).ReadAll()
+
+	if err != nil {
+		t.Fatalf("Failed to read bzip2-compressed CSV, %v", err)
+	}
+
+	if int64(len(rows)) != bz2_count+1 {
+		t.Fatalf("Expected %d rows, got %d", bz2_count+1, len(rows))
+	}
+
+	ids := make(map[string]bool)
+
+	for _, row := range rows[1:] {
+		ids[row[0]] = true
+	}
+
+	if !ids["sh85002782"] {
+		t.Fatalf("Missing sh85002782")
+	}
+
+	_, err = Export(ctx, l, &bytes.Buffer{}, "xml")
+
+	if err == nil {
+		t.Fatalf("Expected unsupported format to fail")
+	}
+}
@@ -0,0 +1,27 @@
+package libraryofcongress
+
+import (
+	"context"
+)
+
+// FindOne() searches 'l' for a given LoC identifier (or label) and returns exactly one record. If there are no
+// matching records a `NotFound` error is returned (errors returned by 'l' itself, including package-specific "not found"
+// errors, are returned as-is). If there is more than one matching record a `MultipleCandidates` error, containing
+// the list of matching records, is returned.
+func FindOne(ctx context.Context, l Lookup, code string) (interface{}, error) {
+
+	results, err := l.Find(ctx, code)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(results) {
+	case 0:
+		return nil, NotFound{Code: code}
+	case 1:
+		return results[0], nil
+	default:
+		return nil, MultipleCandidates{Code: code, Candidates: results}
+	}
+}
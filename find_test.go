@@ -0,0 +1,45 @@
+package libraryofcongress
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFindOne(t *testing.T) {
+
+	ctx := context.Background()
+
+	l := &TestRecordLookup{}
+
+	_, err := FindOne(ctx, l, "a")
+
+	if !IsNotFound(err) {
+		t.Fatalf("Expected NotFound error, got %v", err)
+	}
+
+	l.Append(ctx, &TestRecord{Id: "a", Label: "A"})
+
+	r, err := FindOne(ctx, l, "a")
+
+	if err != nil {
+		t.Fatalf("Failed to find one record, %v", err)
+	}
+
+	if r.(*TestRecord).Id != "a" {
+		t.Fatalf("Unexpected record, %v", r)
+	}
+
+	l.Append(ctx, &TestRecord{Id: "b", Label: "A"})
+
+	tl := NewTypedLookupWithLookup[*TestRecord](l)
+
+	_, err = tl.FindOne(ctx, "a")
+
+	if !IsMultipleCandidates(err) {
+		t.Fatalf("Expected MultipleCandidates error, got %v", err)
+	}
+
+	if len(err.(MultipleCandidates).Candidates) != 2 {
+		t.Fatalf("Expected 2 candidates")
+	}
+}
@@ -1,13 +1,49 @@
 module github.com/sfomuseum/go-sfomuseum-libraryofcongress
 
-go 1.16
+go 1.18
 
 require (
+	github.com/aaronland/go-pagination v0.2.0
 	github.com/aaronland/go-roster v1.0.0
 	github.com/aaronland/go-sqlite v0.2.0
+	github.com/dsnet/compress v0.0.1
 	github.com/sfomuseum/go-csvdict v1.0.0
 	github.com/sfomuseum/go-libraryofcongress-database v0.0.4
 	github.com/sfomuseum/go-timings v1.0.0
+	go.etcd.io/bbolt v1.3.6
 	gocloud.dev v0.25.0
+	golang.org/x/text v0.3.7
+	modernc.org/sqlite v1.18.0
+)
 
+require (
+	github.com/golang/groupcache v0.0.0-20210331224755-41bb18bfe9da // indirect
+	github.com/golang/protobuf v1.5.2 // indirect
+	github.com/google/uuid v1.3.0 // indirect
+	github.com/googleapis/gax-go/v2 v2.2.0 // indirect
+	github.com/jtacoma/uritemplates v1.0.0 // indirect
+	github.com/kballard/go-shellquote v0.0.0-20180428030007-95032a82bc51 // indirect
+	github.com/mattn/go-isatty v0.0.12 // indirect
+	github.com/mattn/go-sqlite3 v1.14.13 // indirect
+	github.com/remyoudompheng/bigfft v0.0.0-20200410134404-eec4a21b6bb0 // indirect
+	github.com/sfomuseum/iso8601duration v1.0.0 // indirect
+	go.opencensus.io v0.23.0 // indirect
+	golang.org/x/mod v0.5.0 // indirect
+	golang.org/x/net v0.0.0-20220401154927-543a649e0bdd // indirect
+	golang.org/x/sys v0.0.0-20220708085239-5a0f0661e09d // indirect
+	golang.org/x/tools v0.1.5 // indirect
+	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1 // indirect
+	google.golang.org/api v0.74.0 // indirect
+	google.golang.org/genproto v0.0.0-20220401170504-314d38edb7de // indirect
+	google.golang.org/grpc v1.45.0 // indirect
+	google.golang.org/protobuf v1.28.0 // indirect
+	lukechampine.com/uint128 v1.1.1 // indirect
+	modernc.org/cc/v3 v3.36.0 // indirect
+	modernc.org/ccgo/v3 v3.16.6 // indirect
+	modernc.org/libc v1.16.7 // indirect
+	modernc.org/mathutil v1.4.1 // indirect
+	modernc.org/memory v1.1.1 // indirect
+	modernc.org/opt v0.1.1 // indirect
+	modernc.org/strutil v1.1.1 // indirect
+	modernc.org/token v1.0.0 // indirect
 )
@@ -0,0 +1,51 @@
+package http
+
+import (
+	gohttp "net/http"
+	"strings"
+)
+
+// CORSHandler() returns a `net/http.Handler` instance that adds Cross-Origin Resource Sharing (CORS) headers to
+// responses for requests whose "Origin" header matches one of 'origins' (or any origin if 'origins' contains "*")
+// before invoking 'next'. Preflight ("OPTIONS") requests are answered directly.
+func CORSHandler(next gohttp.Handler, origins []string) gohttp.Handler {
+
+	allow_all := false
+	allowed := make(map[string]bool)
+
+	for _, o := range origins {
+
+		if o == "*" {
+			allow_all = true
+		}
+
+		allowed[o] = true
+	}
+
+	fn := func(rsp gohttp.ResponseWriter, req *gohttp.Request) {
+
+		origin := req.Header.Get("Origin")
+
+		if origin != "" && (allow_all || allowed[origin]) {
+
+			if allow_all {
+				rsp.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				rsp.Header().Set("Access-Control-Allow-Origin", origin)
+				rsp.Header().Add("Vary", "Origin")
+			}
+
+			rsp.Header().Set("Access-Control-Allow-Methods", strings.Join([]string{gohttp.MethodGet, gohttp.MethodOptions}, ", "))
+			rsp.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type")
+		}
+
+		if req.Method == gohttp.MethodOptions {
+			rsp.WriteHeader(gohttp.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(rsp, req)
+	}
+
+	return gohttp.HandlerFunc(fn)
+}
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	gohttp "net/http"
+)
+
+// FindHandler() returns a `net/http.Handler` instance that will query 'l' for the value of the "q" query parameter
+// and return the results encoded using the content type negotiated from the request's "Accept" header.
+func FindHandler(l libraryofcongress.Lookup) (gohttp.Handler, error) {
+
+	fn := func(rsp gohttp.ResponseWriter, req *gohttp.Request) {
+
+		ctx := req.Context()
+
+		q := req.URL.Query().Get("q")
+
+		if q == "" {
+			gohttp.Error(rsp, "Missing ?q= parameter", gohttp.StatusBadRequest)
+			return
+		}
+
+		results, err := l.Find(ctx, q)
+
+		if err != nil {
+
+			if isNotFound(err) {
+				gohttp.Error(rsp, "Not found", gohttp.StatusNotFound)
+				return
+			}
+
+			if isNotReady(err) {
+				gohttp.Error(rsp, "Lookup not ready", gohttp.StatusServiceUnavailable)
+				return
+			}
+
+			gohttp.Error(rsp, "Failed to perform lookup", gohttp.StatusInternalServerError)
+			return
+		}
+
+		if len(results) == 0 {
+			gohttp.Error(rsp, "Not found", gohttp.StatusNotFound)
+			return
+		}
+
+		WriteResults(rsp, req, results)
+	}
+
+	return gohttp.HandlerFunc(fn), nil
+}
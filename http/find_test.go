@@ -0,0 +1,169 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	_ "github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcsh"
+	_ "github.com/sfomuseum/go-sfomuseum-libraryofcongress/sqlite"
+	gohttp "net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestLookup(t *testing.T) libraryofcongress.Lookup {
+
+	ctx := context.Background()
+
+	rel_path := "../fixtures/lcsh.csv.bz2"
+	abs_path, err := filepath.Abs(rel_path)
+
+	if err != nil {
+		t.Fatalf("Failed to derive absolute path for %s, %v", rel_path, err)
+	}
+
+	lookup_uri := fmt.Sprintf("lcsh://file%s", abs_path)
+
+	l, err := libraryofcongress.NewLookup(ctx, lookup_uri)
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	return l
+}
+
+func TestFindHandler(t *testing.T) {
+
+	l := newTestLookup(t)
+
+	find_handler, err := FindHandler(l)
+
+	if err != nil {
+		t.Fatalf("Failed to create find handler, %v", err)
+	}
+
+	id_handler, err := IdHandler(l)
+
+	if err != nil {
+		t.Fatalf("Failed to create id handler, %v", err)
+	}
+
+	tests := map[string]int{
+		"/find?q=Airplanes":                    gohttp.StatusOK,
+		"/find?q=Aeronautics+--+Popular+works": gohttp.StatusOK,
+		"/find?q=Unknown":                      gohttp.StatusNotFound,
+		"/find":                                gohttp.StatusBadRequest,
+		"/id/sh85002782":                       gohttp.StatusOK,
+		"/id/sh00000000":                       gohttp.StatusNotFound,
+	}
+
+	for path, expected := range tests {
+
+		h := find_handler
+
+		if strings.HasPrefix(path, "/id/") {
+			h = id_handler
+		}
+
+		req := httptest.NewRequest(gohttp.MethodGet, path, nil)
+		rsp := httptest.NewRecorder()
+
+		h.ServeHTTP(rsp, req)
+
+		if rsp.Code != expected {
+			t.Fatalf("Unexpected status code for %s, expected %d but got %d", path, expected, rsp.Code)
+		}
+
+		if expected != gohttp.StatusOK {
+			continue
+		}
+
+		var results []*Result
+
+		err := json.Unmarshal(rsp.Body.Bytes(), &results)
+
+		if err != nil {
+			t.Fatalf("Failed to decode response for %s, %v", path, err)
+		}
+
+		if len(results) != 1 || results[0].Source != "lcsh" {
+			t.Fatalf("Unexpected results for %s: %s", path, rsp.Body.String())
+		}
+	}
+
+	req := httptest.NewRequest(gohttp.MethodGet, "/find?q=Airplanes", nil)
+	req.Header.Set("Accept", "text/csv")
+
+	rsp := httptest.NewRecorder()
+	find_handler.ServeHTTP(rsp, req)
+
+	if rsp.Header().Get("Content-Type") != CONTENT_TYPE_CSV {
+		t.Fatalf("Unexpected content type: %s", rsp.Header().Get("Content-Type"))
+	}
+
+	if rsp.Body.String() != "id,label,source\nsh85002782,Airplanes,lcsh\n" {
+		t.Fatalf("Unexpected CSV output: %s", rsp.Body.String())
+	}
+}
+
+func TestIdHandlerSQLite(t *testing.T) {
+
+	ctx := context.Background()
+
+	rel_path := "../fixtures/libraryofcongress.db"
+	abs_path, err := filepath.Abs(rel_path)
+
+	if err != nil {
+		t.Fatalf("Failed to derive absolute path for %s, %v", rel_path, err)
+	}
+
+	l, err := libraryofcongress.NewLookup(ctx, fmt.Sprintf("sqlite://%s", abs_path))
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	id_handler, err := IdHandler(l)
+
+	if err != nil {
+		t.Fatalf("Failed to create id handler, %v", err)
+	}
+
+	tests := map[string]int{
+		"/id/sh00000011":  gohttp.StatusOK,
+		"/id/n2003099999": gohttp.StatusOK,
+		"/id/sh00000000":  gohttp.StatusNotFound,
+	}
+
+	for path, expected := range tests {
+
+		req := httptest.NewRequest(gohttp.MethodGet, path, nil)
+		rsp := httptest.NewRecorder()
+
+		id_handler.ServeHTTP(rsp, req)
+
+		if rsp.Code != expected {
+			t.Fatalf("Unexpected status code for %s, expected %d but got %d", path, expected, rsp.Code)
+		}
+
+		if expected != gohttp.StatusOK {
+			continue
+		}
+
+		var results []*Result
+
+		err := json.Unmarshal(rsp.Body.Bytes(), &results)
+
+		if err != nil {
+			t.Fatalf("Failed to decode response for %s, %v", path, err)
+		}
+
+		if len(results) != 1 || results[0].Id != filepath.Base(path) {
+			t.Fatalf("Unexpected results for %s: %s", path, rsp.Body.String())
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	gohttp "net/http"
+)
+
+// HealthHandler() returns a `net/http.Handler` instance that reports whether the server is able to respond to requests.
+func HealthHandler() (gohttp.Handler, error) {
+
+	fn := func(rsp gohttp.ResponseWriter, req *gohttp.Request) {
+
+		status := map[string]string{
+			"status": "ok",
+		}
+
+		rsp.Header().Set("Content-Type", CONTENT_TYPE_JSON)
+
+		enc := json.NewEncoder(rsp)
+		enc.Encode(status)
+	}
+
+	return gohttp.HandlerFunc(fn), nil
+}
+
+// ReadyHandler() returns a `net/http.Handler` instance that reports whether 'l' has finished loading and is able to
+// respond to queries. If 'l' is still being loaded, or failed to load, then a 503 Service Unavailable status is returned.
+// Lookups which do not implement the `libraryofcongress.ReadyLookup` interface are always considered to be ready.
+func ReadyHandler(l libraryofcongress.Lookup) (gohttp.Handler, error) {
+
+	fn := func(rsp gohttp.ResponseWriter, req *gohttp.Request) {
+
+		ctx := req.Context()
+
+		status := map[string]string{
+			"status": "ok",
+		}
+
+		status_code := gohttp.StatusOK
+
+		r, ok := l.(libraryofcongress.ReadyLookup)
+
+		if ok {
+
+			select {
+			case <-r.Ready():
+
+				err := r.Wait(ctx)
+
+				if err != nil {
+					status["status"] = "failed"
+					status_code = gohttp.StatusServiceUnavailable
+				}
+
+			default:
+				status["status"] = "loading"
+				status_code = gohttp.StatusServiceUnavailable
+			}
+		}
+
+		rsp.Header().Set("Content-Type", CONTENT_TYPE_JSON)
+		rsp.WriteHeader(status_code)
+
+		enc := json.NewEncoder(rsp)
+		enc.Encode(status)
+	}
+
+	return gohttp.HandlerFunc(fn), nil
+}
@@ -0,0 +1,99 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcsh"
+	gohttp "net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReadyHandler(t *testing.T) {
+
+	ctx := context.Background()
+
+	// Block loading until 'release' is closed
+
+	release := make(chan struct{})
+
+	lookup_func := func(ctx context.Context, l *lcsh.SubjectHeadingLookup) error {
+		<-release
+		return nil
+	}
+
+	opts := &lcsh.SubjectHeadingLookupOptions{
+		Normalize:  libraryofcongress.NormalizeSubdivisions,
+		Background: true,
+	}
+
+	l, err := lcsh.NewSubjectHeadingLookupWithLookupFuncAndOptions(ctx, lookup_func, opts)
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	ready_handler, err := ReadyHandler(l)
+
+	if err != nil {
+		t.Fatalf("Failed to create ready handler, %v", err)
+	}
+
+	find_handler, err := FindHandler(l)
+
+	if err != nil {
+		t.Fatalf("Failed to create find handler, %v", err)
+	}
+
+	check := func(h gohttp.Handler, path string, expected int) *httptest.ResponseRecorder {
+
+		req := httptest.NewRequest(gohttp.MethodGet, path, nil)
+		rsp := httptest.NewRecorder()
+
+		h.ServeHTTP(rsp, req)
+
+		if rsp.Code != expected {
+			t.Fatalf("Unexpected status code for %s, expected %d but got %d", path, expected, rsp.Code)
+		}
+
+		return rsp
+	}
+
+	rsp := check(ready_handler, "/ready", gohttp.StatusServiceUnavailable)
+
+	var status map[string]string
+
+	err = json.Unmarshal(rsp.Body.Bytes(), &status)
+
+	if err != nil {
+		t.Fatalf("Failed to decode response, %v", err)
+	}
+
+	if status["status"] != "loading" {
+		t.Fatalf("Unexpected status, %s", status["status"])
+	}
+
+	check(find_handler, "/find?q=Airplanes", gohttp.StatusServiceUnavailable)
+
+	close(release)
+
+	err = libraryofcongress.Wait(ctx, l)
+
+	if err != nil {
+		t.Fatalf("Failed to load lookup, %v", err)
+	}
+
+	check(ready_handler, "/ready", gohttp.StatusOK)
+	check(find_handler, "/find?q=Airplanes", gohttp.StatusNotFound)
+
+	// Lookups loaded when they are created are always ready
+
+	ready_handler, err = ReadyHandler(newTestLookup(t))
+
+	if err != nil {
+		t.Fatalf("Failed to create ready handler, %v", err)
+	}
+
+	check(ready_handler, "/ready", gohttp.StatusOK)
+}
@@ -0,0 +1,2 @@
+// Package http provides net/http handlers for querying `libraryofcongress.Lookup` instances.
+package http
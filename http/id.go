@@ -0,0 +1,63 @@
+package http
+
+import (
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	gohttp "net/http"
+	"path"
+)
+
+// IdHandler() returns a `net/http.Handler` instance that will query 'l' for the identifier defined by the last
+// element of the request path (for example "/id/sh85002782") and return the records whose identifier matches exactly
+// encoded using the content type negotiated from the request's "Accept" header.
+func IdHandler(l libraryofcongress.Lookup) (gohttp.Handler, error) {
+
+	fn := func(rsp gohttp.ResponseWriter, req *gohttp.Request) {
+
+		ctx := req.Context()
+
+		id := path.Base(req.URL.Path)
+
+		if id == "" || id == "/" || id == "." {
+			gohttp.Error(rsp, "Missing identifier", gohttp.StatusBadRequest)
+			return
+		}
+
+		results, err := l.Find(ctx, id)
+
+		if err != nil {
+
+			if isNotFound(err) {
+				gohttp.Error(rsp, "Not found", gohttp.StatusNotFound)
+				return
+			}
+
+			if isNotReady(err) {
+				gohttp.Error(rsp, "Lookup not ready", gohttp.StatusServiceUnavailable)
+				return
+			}
+
+			gohttp.Error(rsp, "Failed to perform lookup", gohttp.StatusInternalServerError)
+			return
+		}
+
+		matches := make([]interface{}, 0)
+
+		for _, r := range results {
+
+			rec, ok := r.(libraryofcongress.Record)
+
+			if ok && rec.GetId() == id {
+				matches = append(matches, r)
+			}
+		}
+
+		if len(matches) == 0 {
+			gohttp.Error(rsp, "Not found", gohttp.StatusNotFound)
+			return
+		}
+
+		WriteResults(rsp, req, matches)
+	}
+
+	return gohttp.HandlerFunc(fn), nil
+}
@@ -0,0 +1,129 @@
+package http
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	gohttp "net/http"
+	"strings"
+)
+
+const (
+	// CONTENT_TYPE_JSON is the content type for JSON-encoded responses.
+	CONTENT_TYPE_JSON string = "application/json"
+	// CONTENT_TYPE_CSV is the content type for CSV-encoded responses.
+	CONTENT_TYPE_CSV string = "text/csv"
+	// CONTENT_TYPE_TEXT is the content type for plain text responses.
+	CONTENT_TYPE_TEXT string = "text/plain"
+)
+
+// type Result is a struct for encoding lookup results in HTTP responses.
+type Result struct {
+	// Id is the unique identifier for the LoC record.
+	Id string `json:"id"`
+	// Label is the name (or title) for the LoC record.
+	Label string `json:"label"`
+	// Source is the name of the LoC data source for the record.
+	Source string `json:"source"`
+}
+
+// String() returns the a string-ified representation of the result's Id and Label properties.
+func (r *Result) String() string {
+	return fmt.Sprintf("%s %s", r.Id, r.Label)
+}
+
+// NewResult() returns a new `Result` instance derived from 'r' which is expected to implement the `libraryofcongress.Record` interface.
+func NewResult(r interface{}) (*Result, error) {
+
+	rec, ok := r.(libraryofcongress.Record)
+
+	if !ok {
+		return nil, fmt.Errorf("Unsupported record type %T", r)
+	}
+
+	result := &Result{
+		Id:     rec.GetId(),
+		Label:  rec.GetLabel(),
+		Source: rec.GetSource(),
+	}
+
+	return result, nil
+}
+
+// NegotiateContentType() returns the content type to use for responses to 'req' derived from its "Accept" header.
+// If no supported content type is present then `CONTENT_TYPE_JSON` is returned.
+func NegotiateContentType(req *gohttp.Request) string {
+
+	for _, accept := range strings.Split(req.Header.Get("Accept"), ",") {
+
+		media_type := strings.TrimSpace(strings.Split(accept, ";")[0])
+
+		switch media_type {
+		case CONTENT_TYPE_JSON, CONTENT_TYPE_CSV, CONTENT_TYPE_TEXT:
+			return media_type
+		case "*/*":
+			return CONTENT_TYPE_JSON
+		}
+	}
+
+	return CONTENT_TYPE_JSON
+}
+
+// WriteResults() writes 'results' to 'rsp' encoded using the content type negotiated from 'req'.
+func WriteResults(rsp gohttp.ResponseWriter, req *gohttp.Request, results []interface{}) {
+
+	output := make([]*Result, len(results))
+
+	for idx, r := range results {
+
+		result, err := NewResult(r)
+
+		if err != nil {
+			gohttp.Error(rsp, "Failed to encode results", gohttp.StatusInternalServerError)
+			return
+		}
+
+		output[idx] = result
+	}
+
+	content_type := NegotiateContentType(req)
+
+	rsp.Header().Set("Content-Type", content_type)
+	rsp.Header().Add("Vary", "Accept")
+
+	switch content_type {
+	case CONTENT_TYPE_CSV:
+
+		wr := csv.NewWriter(rsp)
+		wr.Write([]string{"id", "label", "source"})
+
+		for _, r := range output {
+			wr.Write([]string{r.Id, r.Label, r.Source})
+		}
+
+		wr.Flush()
+
+	case CONTENT_TYPE_TEXT:
+
+		for _, r := range output {
+			fmt.Fprintln(rsp, r.String())
+		}
+
+	default:
+
+		enc := json.NewEncoder(rsp)
+		enc.Encode(output)
+	}
+}
+
+// isNotFound() returns a boolean value indicating whether 'err' is a "not found" error.
+func isNotFound(err error) bool {
+	return errors.Is(err, libraryofcongress.ErrNotFound)
+}
+
+// isNotReady() returns a boolean value indicating whether 'err' is a "not ready" error.
+func isNotReady(err error) bool {
+	return errors.Is(err, libraryofcongress.ErrNotReady)
+}
@@ -0,0 +1,244 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcnaf"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcsh"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/reconcile"
+	"html/template"
+	gohttp "net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// re_callback is used to validate JSONP callback function names.
+var re_callback = regexp.MustCompile(`^[a-zA-Z_$][a-zA-Z0-9_$.]*$`)
+
+// preview_template is the HTML template used to render reconciliation previews.
+var preview_template = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{ .Label }}</title></head>
+<body style="font-family:sans-serif;font-size:14px;margin:8px;">
+<div><strong>{{ .Label }}</strong></div>
+<div style="color:#666;">{{ .Source }} <a href="{{ .URI }}" target="_blank">{{ .Id }}</a></div>
+</body>
+</html>
+`))
+
+// ReconcileHandler() returns a `net/http.Handler` instance implementing the reconciliation endpoint of the W3C
+// Reconciliation Service API for 's'. If the request has no "queries" parameter (passed as a query string or form value)
+// the service manifest is returned, otherwise the candidates for each query. 'prefix' is the path the handler is
+// mounted on (for example "/reconcile") and is used to derive the view, preview and suggest URLs in the manifest.
+func ReconcileHandler(s *reconcile.Service, prefix string) (gohttp.Handler, error) {
+
+	fn := func(rsp gohttp.ResponseWriter, req *gohttp.Request) {
+
+		ctx := req.Context()
+
+		err := req.ParseForm()
+
+		if err != nil {
+			gohttp.Error(rsp, "Failed to parse request", gohttp.StatusBadRequest)
+			return
+		}
+
+		str_queries := req.Form.Get("queries")
+
+		if str_queries == "" {
+			manifest := s.Manifest(rootURL(req, prefix))
+			writeJSONP(rsp, req, manifest)
+			return
+		}
+
+		var queries map[string]*reconcile.Query
+
+		err = json.Unmarshal([]byte(str_queries), &queries)
+
+		if err != nil {
+			gohttp.Error(rsp, "Invalid queries parameter", gohttp.StatusBadRequest)
+			return
+		}
+
+		results, err := s.Reconcile(ctx, queries)
+
+		if err != nil {
+
+			if isNotReady(err) {
+				gohttp.Error(rsp, "Lookup not ready", gohttp.StatusServiceUnavailable)
+				return
+			}
+
+			gohttp.Error(rsp, "Failed to reconcile queries", gohttp.StatusInternalServerError)
+			return
+		}
+
+		writeJSONP(rsp, req, results)
+	}
+
+	return gohttp.HandlerFunc(fn), nil
+}
+
+// ReconcilePreviewHandler() returns a `net/http.Handler` instance that renders a small HTML preview for the record
+// whose identifier is defined by the "id" query parameter.
+func ReconcilePreviewHandler(s *reconcile.Service) (gohttp.Handler, error) {
+
+	fn := func(rsp gohttp.ResponseWriter, req *gohttp.Request) {
+
+		ctx := req.Context()
+
+		id := req.URL.Query().Get("id")
+
+		if id == "" {
+			gohttp.Error(rsp, "Missing ?id= parameter", gohttp.StatusBadRequest)
+			return
+		}
+
+		rec, err := s.Record(ctx, id)
+
+		if err != nil {
+			gohttp.Error(rsp, "Not found", gohttp.StatusNotFound)
+			return
+		}
+
+		vars := map[string]string{
+			"Id":     rec.GetId(),
+			"Label":  rec.GetLabel(),
+			"Source": rec.GetSource(),
+			"URI":    viewURL(rec.GetSource(), rec.GetId()),
+		}
+
+		rsp.Header().Set("Content-Type", "text/html; charset=utf-8")
+		preview_template.Execute(rsp, vars)
+	}
+
+	return gohttp.HandlerFunc(fn), nil
+}
+
+// ReconcileSuggestHandler() returns a `net/http.Handler` instance implementing the "suggest entity" endpoint of the
+// W3C Reconciliation Service API, returning records matching the "prefix" query parameter.
+func ReconcileSuggestHandler(s *reconcile.Service) (gohttp.Handler, error) {
+
+	fn := func(rsp gohttp.ResponseWriter, req *gohttp.Request) {
+
+		ctx := req.Context()
+
+		q := req.URL.Query()
+
+		prefix := q.Get("prefix")
+		limit := 0
+
+		str_limit := q.Get("limit")
+
+		if str_limit != "" {
+
+			v, err := strconv.Atoi(str_limit)
+
+			if err != nil {
+				gohttp.Error(rsp, "Invalid ?limit= parameter", gohttp.StatusBadRequest)
+				return
+			}
+
+			limit = v
+		}
+
+		results, err := s.Suggest(ctx, prefix, limit)
+
+		if err != nil {
+
+			if isNotReady(err) {
+				gohttp.Error(rsp, "Lookup not ready", gohttp.StatusServiceUnavailable)
+				return
+			}
+
+			gohttp.Error(rsp, "Failed to derive suggestions", gohttp.StatusInternalServerError)
+			return
+		}
+
+		writeJSONP(rsp, req, results)
+	}
+
+	return gohttp.HandlerFunc(fn), nil
+}
+
+// ReconcileViewHandler() returns a `net/http.Handler` instance that redirects requests for a record, whose identifier is
+// defined by the last element of the request path, to its page on the id.loc.gov website.
+func ReconcileViewHandler(s *reconcile.Service) (gohttp.Handler, error) {
+
+	fn := func(rsp gohttp.ResponseWriter, req *gohttp.Request) {
+
+		ctx := req.Context()
+
+		id := path.Base(req.URL.Path)
+
+		if id == "" || id == "/" || id == "." {
+			gohttp.Error(rsp, "Missing identifier", gohttp.StatusBadRequest)
+			return
+		}
+
+		rec, err := s.Record(ctx, id)
+
+		if err != nil {
+			gohttp.Error(rsp, "Not found", gohttp.StatusNotFound)
+			return
+		}
+
+		gohttp.Redirect(rsp, req, viewURL(rec.GetSource(), rec.GetId()), gohttp.StatusFound)
+	}
+
+	return gohttp.HandlerFunc(fn), nil
+}
+
+// viewURL() returns the id.loc.gov web page URL for the record 'id' in 'source'.
+func viewURL(source string, id string) string {
+
+	switch source {
+	case lcnaf.SOURCE:
+		return fmt.Sprintf("https://id.loc.gov/authorities/names/%s.html", id)
+	case lcsh.SOURCE:
+		return fmt.Sprintf("https://id.loc.gov/authorities/subjects/%s.html", id)
+	default:
+		return fmt.Sprintf("https://id.loc.gov/search/?q=%s", id)
+	}
+}
+
+// rootURL() returns the fully-qualified URL for 'prefix' derived from 'req'.
+func rootURL(req *gohttp.Request, prefix string) string {
+
+	scheme := "http"
+
+	if req.TLS != nil {
+		scheme = "https"
+	}
+
+	return fmt.Sprintf("%s://%s%s", scheme, req.Host, strings.TrimRight(prefix, "/"))
+}
+
+// writeJSONP() writes 'data' to 'rsp' as JSON or, if the request has a valid "callback" parameter, as JSONP.
+func writeJSONP(rsp gohttp.ResponseWriter, req *gohttp.Request, data interface{}) {
+
+	enc, err := json.Marshal(data)
+
+	if err != nil {
+		gohttp.Error(rsp, "Failed to encode response", gohttp.StatusInternalServerError)
+		return
+	}
+
+	callback := req.FormValue("callback")
+
+	if callback == "" {
+		rsp.Header().Set("Content-Type", CONTENT_TYPE_JSON)
+		rsp.Write(enc)
+		return
+	}
+
+	if !re_callback.MatchString(callback) {
+		gohttp.Error(rsp, "Invalid callback parameter", gohttp.StatusBadRequest)
+		return
+	}
+
+	rsp.Header().Set("Content-Type", "application/javascript")
+	fmt.Fprintf(rsp, "%s(%s)", callback, enc)
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/reconcile"
+	gohttp "net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestReconcileHandler(t *testing.T) {
+
+	ctx := context.Background()
+
+	l := newTestLookup(t)
+
+	s, err := reconcile.NewService(ctx, l)
+
+	if err != nil {
+		t.Fatalf("Failed to create reconcile service, %v", err)
+	}
+
+	h, err := ReconcileHandler(s, "/reconcile")
+
+	if err != nil {
+		t.Fatalf("Failed to create reconcile handler, %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/reconcile", nil)
+	rsp := httptest.NewRecorder()
+
+	h.ServeHTTP(rsp, req)
+
+	var manifest reconcile.Manifest
+
+	err = json.Unmarshal(rsp.Body.Bytes(), &manifest)
+
+	if err != nil {
+		t.Fatalf("Failed to decode manifest, %v", err)
+	}
+
+	if manifest.Preview.URL != "http://example.com/reconcile/preview?id={{id}}" {
+		t.Fatalf("Unexpected preview URL '%s'", manifest.Preview.URL)
+	}
+
+	form := url.Values{}
+	form.Set("queries", `{"q0":{"query":"Airplanes","type":"lcsh"},"q1":{"query":"Unknown"}}`)
+
+	req = httptest.NewRequest("POST", "/reconcile", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	rsp = httptest.NewRecorder()
+
+	h.ServeHTTP(rsp, req)
+
+	if rsp.Code != gohttp.StatusOK {
+		t.Fatalf("Unexpected status code %d", rsp.Code)
+	}
+
+	var results map[string]*reconcile.Results
+
+	err = json.Unmarshal(rsp.Body.Bytes(), &results)
+
+	if err != nil {
+		t.Fatalf("Failed to decode results, %v", err)
+	}
+
+	if len(results["q0"].Result) != 1 || results["q0"].Result[0].Id != "sh85002782" {
+		t.Fatalf("Unexpected results for q0")
+	}
+
+	if len(results["q1"].Result) != 0 {
+		t.Fatalf("Unexpected results for q1")
+	}
+
+	req = httptest.NewRequest("GET", "/reconcile?callback=cb", nil)
+	rsp = httptest.NewRecorder()
+
+	h.ServeHTTP(rsp, req)
+
+	if !strings.HasPrefix(rsp.Body.String(), "cb(") {
+		t.Fatalf("Expected JSONP response")
+	}
+}
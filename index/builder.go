@@ -0,0 +1,191 @@
+package index
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"io"
+	"sort"
+)
+
+// type Builder provides methods for creating index files.
+type Builder struct {
+	source         string
+	normalize_spec string
+	normalize      libraryofcongress.NormalizeFunc
+	ids            []string
+	labels         []string
+}
+
+// NewBuilder() returns a new `Builder` instance for records from 'source' whose labels will be normalized using
+// the normalizer specification 'normalize_spec' (see `libraryofcongress.NewNormalizeFunc`).
+func NewBuilder(source string, normalize_spec string) (*Builder, error) {
+
+	if normalize_spec == "" {
+		normalize_spec = libraryofcongress.DEFAULT_NORMALIZER
+	}
+
+	normalize, err := libraryofcongress.NewNormalizeFunc(normalize_spec)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create normalizer, %w", err)
+	}
+
+	b := &Builder{
+		source:         source,
+		normalize_spec: normalize_spec,
+		normalize:      normalize,
+		ids:            make([]string, 0),
+		labels:         make([]string, 0),
+	}
+
+	return b, nil
+}
+
+// Add() adds a record with identifier 'id' and label 'label' to the index.
+func (b *Builder) Add(id string, label string) {
+	b.ids = append(b.ids, id)
+	b.labels = append(b.labels, label)
+}
+
+// Write() writes the index for all the records added to 'b' to 'wr'.
+func (b *Builder) Write(wr io.Writer) error {
+
+	// Sort records by identifier
+
+	order := make([]int, len(b.ids))
+
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return b.ids[order[i]] < b.ids[order[j]]
+	})
+
+	// Derive the (normalized label, record) pairs, sorted by normalized label
+
+	type key_entry struct {
+		key    string
+		record int
+	}
+
+	entries := make([]key_entry, 0, len(order))
+
+	for pos, i := range order {
+
+		key := b.normalize(b.labels[i])
+
+		if key == "" {
+			continue
+		}
+
+		entries = append(entries, key_entry{key: key, record: pos})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].key < entries[j].key
+	})
+
+	// Calculate offsets
+
+	meta_offset := uint64(HEADER_SIZE)
+	meta_size := stringSize(b.source) + stringSize(b.normalize_spec)
+
+	records_offset := meta_offset + meta_size
+	record_offsets := make([]uint64, len(order))
+
+	offset := records_offset
+
+	for pos, i := range order {
+		record_offsets[pos] = offset
+		offset += stringSize(b.ids[i]) + stringSize(b.labels[i])
+	}
+
+	key_offsets := make([]uint64, len(entries))
+
+	for i, e := range entries {
+
+		if i > 0 && entries[i-1].key == e.key {
+			key_offsets[i] = key_offsets[i-1]
+			continue
+		}
+
+		key_offsets[i] = offset
+		offset += stringSize(e.key)
+	}
+
+	ids_offset := offset
+	keys_offset := ids_offset + uint64(len(order)*8)
+
+	// Write everything
+
+	buf := bufio.NewWriter(wr)
+
+	header := make([]byte, HEADER_SIZE)
+	copy(header, MAGIC)
+
+	binary.LittleEndian.PutUint64(header[8:], uint64(len(order)))
+	binary.LittleEndian.PutUint64(header[16:], uint64(len(entries)))
+	binary.LittleEndian.PutUint64(header[24:], ids_offset)
+	binary.LittleEndian.PutUint64(header[32:], keys_offset)
+	binary.LittleEndian.PutUint64(header[40:], meta_offset)
+
+	buf.Write(header)
+
+	writeString(buf, b.source)
+	writeString(buf, b.normalize_spec)
+
+	for _, i := range order {
+		writeString(buf, b.ids[i])
+		writeString(buf, b.labels[i])
+	}
+
+	for i, e := range entries {
+
+		if i > 0 && entries[i-1].key == e.key {
+			continue
+		}
+
+		writeString(buf, e.key)
+	}
+
+	n := make([]byte, 8)
+
+	for _, o := range record_offsets {
+		binary.LittleEndian.PutUint64(n, o)
+		buf.Write(n)
+	}
+
+	entry := make([]byte, KEY_ENTRY_SIZE)
+
+	for i, e := range entries {
+		binary.LittleEndian.PutUint64(entry[0:], key_offsets[i])
+		binary.LittleEndian.PutUint32(entry[8:], uint32(e.record))
+		buf.Write(entry)
+	}
+
+	err := buf.Flush()
+
+	if err != nil {
+		return fmt.Errorf("Failed to write index, %w", err)
+	}
+
+	return nil
+}
+
+// stringSize() returns the number of bytes needed to encode 's' as a length-prefixed string.
+func stringSize(s string) uint64 {
+
+	n := make([]byte, binary.MaxVarintLen64)
+	return uint64(binary.PutUvarint(n, uint64(len(s))) + len(s))
+}
+
+// writeString() writes 's' to 'wr' as a length-prefixed string.
+func writeString(wr *bufio.Writer, s string) {
+
+	n := make([]byte, binary.MaxVarintLen64)
+	wr.Write(n[:binary.PutUvarint(n, uint64(len(s)))])
+	wr.WriteString(s)
+}
@@ -0,0 +1,311 @@
+// Package index implements a compact, read-only, on-disk index format for LoC records which is memory-mapped
+// (where supported) at runtime. Indices are produced using the `Builder` type (or the `build-index` tool) and
+// queried using the `Index` type or the `lcshidx://` and `lcnafidx://` lookups.
+//
+// An index file consists of a fixed-size header followed by a metadata section, a blob of (identifier, label) records
+// sorted by identifier, a blob of unique normalized labels, a table of record offsets and a table of (normalized label,
+// record) pairs sorted by normalized label. All integers are little-endian and all strings are prefixed by their length
+// encoded as an unsigned varint. Both identifier and label lookups are performed using a binary search.
+package index
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// MAGIC is the sequence of bytes that every index file starts with.
+const MAGIC string = "LOCIDX01"
+
+// HEADER_SIZE is the size, in bytes, of an index file header.
+const HEADER_SIZE int = 64
+
+// KEY_ENTRY_SIZE is the size, in bytes, of each entry in the (normalized) label table.
+const KEY_ENTRY_SIZE int = 12
+
+// ErrClosed is the error returned when querying an index that has been closed.
+var ErrClosed = errors.New("index is closed")
+
+// type Index provides methods for querying an index file.
+type Index struct {
+	data         []byte
+	source       string
+	normalize    string
+	record_count int
+	key_count    int
+	ids_offset   int
+	keys_offset  int
+	close_func   func() error
+	// mu ensures that the index data is not released (unmapped) by `Close` while it is being read.
+	mu     *sync.RWMutex
+	closed bool
+}
+
+// Open() returns a new `Index` instance for the index file at 'path'. Where supported the file is memory-mapped rather
+// than read in to memory. The `Close` method should be called once the index is no longer needed.
+func Open(path string) (*Index, error) {
+
+	data, close_func, err := mapFile(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open %s, %w", path, err)
+	}
+
+	idx, err := NewIndex(data)
+
+	if err != nil {
+		close_func()
+		return nil, fmt.Errorf("Failed to load index from %s, %w", path, err)
+	}
+
+	idx.close_func = close_func
+	return idx, nil
+}
+
+// NewIndex() returns a new `Index` instance for the index data in 'data'.
+func NewIndex(data []byte) (*Index, error) {
+
+	if len(data) < HEADER_SIZE || string(data[0:len(MAGIC)]) != MAGIC {
+		return nil, fmt.Errorf("Invalid index header")
+	}
+
+	record_count := binary.LittleEndian.Uint64(data[8:16])
+	key_count := binary.LittleEndian.Uint64(data[16:24])
+	ids_offset := binary.LittleEndian.Uint64(data[24:32])
+	keys_offset := binary.LittleEndian.Uint64(data[32:40])
+	meta_offset := binary.LittleEndian.Uint64(data[40:48])
+
+	size := uint64(len(data))
+
+	// Check the counts before multiplying them so that large (corrupt) values can't overflow
+
+	if record_count > size/8 || key_count > size/uint64(KEY_ENTRY_SIZE) {
+		return nil, fmt.Errorf("Invalid index counts")
+	}
+
+	if ids_offset+record_count*8 > size || keys_offset+key_count*uint64(KEY_ENTRY_SIZE) > size || meta_offset > size {
+		return nil, fmt.Errorf("Invalid index offsets")
+	}
+
+	source, n, err := readString(data, int(meta_offset))
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read source, %w", err)
+	}
+
+	normalize, _, err := readString(data, int(meta_offset)+n)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read normalize, %w", err)
+	}
+
+	idx := &Index{
+		data:         data,
+		source:       source,
+		normalize:    normalize,
+		record_count: int(record_count),
+		key_count:    int(key_count),
+		ids_offset:   int(ids_offset),
+		keys_offset:  int(keys_offset),
+		mu:           new(sync.RWMutex),
+	}
+
+	return idx, nil
+}
+
+// Source() returns the name of the LoC data source for the records in the index.
+func (idx *Index) Source() string {
+	return idx.source
+}
+
+// Normalize() returns the normalizer specification (see `libraryofcongress.NewNormalizeFunc`) used to normalize the
+// labels in the index.
+func (idx *Index) Normalize() string {
+	return idx.normalize
+}
+
+// Count() returns the number of records in the index.
+func (idx *Index) Count() int {
+	return idx.record_count
+}
+
+// Close() releases the resources (for example, memory maps) used by the index. It waits for any queries that are in
+// progress to complete; queries made after the index has been closed return an error.
+func (idx *Index) Close() error {
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.closed = true
+	idx.data = nil
+
+	if idx.close_func == nil {
+		return nil
+	}
+
+	err := idx.close_func()
+	idx.close_func = nil
+
+	return err
+}
+
+// Record() returns the identifier and label for the i-th record (sorted by identifier) in the index.
+func (idx *Index) Record(i int) (string, string, error) {
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	if idx.closed {
+		return "", "", ErrClosed
+	}
+
+	if i < 0 || i >= idx.record_count {
+		return "", "", fmt.Errorf("Invalid record %d", i)
+	}
+
+	offset := int(binary.LittleEndian.Uint64(idx.data[idx.ids_offset+i*8:]))
+
+	id, n, err := readString(idx.data, offset)
+
+	if err != nil {
+		return "", "", fmt.Errorf("Failed to read identifier for record %d, %w", i, err)
+	}
+
+	label, _, err := readString(idx.data, offset+n)
+
+	if err != nil {
+		return "", "", fmt.Errorf("Failed to read label for record %d, %w", i, err)
+	}
+
+	return id, label, nil
+}
+
+// FindId() returns the positions of the records whose identifier is 'id'.
+func (idx *Index) FindId(id string) ([]int, error) {
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	if idx.closed {
+		return nil, ErrClosed
+	}
+
+	var err error
+
+	record_id := func(i int) string {
+
+		offset := int(binary.LittleEndian.Uint64(idx.data[idx.ids_offset+i*8:]))
+
+		v, _, read_err := readString(idx.data, offset)
+
+		if read_err != nil {
+			err = read_err
+		}
+
+		return v
+	}
+
+	i := sort.Search(idx.record_count, func(i int) bool {
+		return record_id(i) >= id
+	})
+
+	matches := make([]int, 0)
+
+	for j := i; j < idx.record_count && record_id(j) == id; j++ {
+		matches = append(matches, j)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read index, %w", err)
+	}
+
+	return matches, nil
+}
+
+// FindKey() returns the positions of the records whose normalized label is 'key'.
+func (idx *Index) FindKey(key string) ([]int, error) {
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	if idx.closed {
+		return nil, ErrClosed
+	}
+
+	var err error
+
+	key_bytes := []byte(key)
+
+	entry_key := func(i int) []byte {
+
+		entry := idx.keys_offset + i*KEY_ENTRY_SIZE
+		offset := int(binary.LittleEndian.Uint64(idx.data[entry:]))
+
+		v, _, read_err := readBytes(idx.data, offset)
+
+		if read_err != nil {
+			err = read_err
+		}
+
+		return v
+	}
+
+	i := sort.Search(idx.key_count, func(i int) bool {
+		return bytes.Compare(entry_key(i), key_bytes) >= 0
+	})
+
+	matches := make([]int, 0)
+
+	for j := i; j < idx.key_count && bytes.Equal(entry_key(j), key_bytes); j++ {
+		entry := idx.keys_offset + j*KEY_ENTRY_SIZE
+		matches = append(matches, int(binary.LittleEndian.Uint32(idx.data[entry+8:])))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read index, %w", err)
+	}
+
+	return matches, nil
+}
+
+// readString() returns the length-prefixed string at 'offset' in 'data' and the total number of bytes it occupies.
+func readString(data []byte, offset int) (string, int, error) {
+
+	b, n, err := readBytes(data, offset)
+
+	if err != nil {
+		return "", 0, err
+	}
+
+	return string(b), n, nil
+}
+
+// readBytes() returns the length-prefixed bytes at 'offset' in 'data' and the total number of bytes they occupy.
+func readBytes(data []byte, offset int) ([]byte, int, error) {
+
+	if offset < 0 || offset >= len(data) {
+		return nil, 0, fmt.Errorf("Invalid offset %d", offset)
+	}
+
+	length, n := binary.Uvarint(data[offset:])
+
+	if n <= 0 {
+		return nil, 0, fmt.Errorf("Invalid length at offset %d", offset)
+	}
+
+	start := offset + n
+
+	// Compare the length with the remaining data, rather than computing the end offset first, so that large
+	// (corrupt) lengths can't overflow
+
+	if length > uint64(len(data)-start) {
+		return nil, 0, fmt.Errorf("Invalid length at offset %d", offset)
+	}
+
+	end := start + int(length)
+
+	return data[start:end], n + int(length), nil
+}
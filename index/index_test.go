@@ -0,0 +1,258 @@
+package index
+
+import (
+	"context"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcsh"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIndexLookup(t *testing.T) {
+
+	ctx := context.Background()
+
+	rel_path := "../fixtures/lcsh.csv.bz2"
+	abs_path, err := filepath.Abs(rel_path)
+
+	if err != nil {
+		t.Fatalf("Failed to derive absolute path for %s, %v", rel_path, err)
+	}
+
+	source, err := lcsh.NewSubjectHeadingLookup(ctx, fmt.Sprintf("lcsh://file%s", abs_path))
+
+	if err != nil {
+		t.Fatalf("Failed to create source lookup, %v", err)
+	}
+
+	b, err := NewBuilder(lcsh.SOURCE, "")
+
+	if err != nil {
+		t.Fatalf("Failed to create builder, %v", err)
+	}
+
+	count := 0
+
+	err = libraryofcongress.Iterate(ctx, source, func(ctx context.Context, r libraryofcongress.Record) error {
+		b.Add(r.GetId(), r.GetLabel())
+		count += 1
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("Failed to iterate source lookup, %v", err)
+	}
+
+	index_path := filepath.Join(t.TempDir(), "lcsh.idx")
+
+	fh, err := os.Create(index_path)
+
+	if err != nil {
+		t.Fatalf("Failed to create index file, %v", err)
+	}
+
+	err = b.Write(fh)
+
+	if err != nil {
+		t.Fatalf("Failed to write index, %v", err)
+	}
+
+	err = fh.Close()
+
+	if err != nil {
+		t.Fatalf("Failed to close index file, %v", err)
+	}
+
+	l, err := libraryofcongress.NewLookup(ctx, fmt.Sprintf("lcshidx://%s", index_path))
+
+	if err != nil {
+		t.Fatalf("Failed to create index lookup, %v", err)
+	}
+
+	defer l.(*IndexLookup).Close()
+
+	tests := map[string]int{
+		"Airplanes":    1,
+		"Cooking":      2,
+		"sh2010007517": 2,
+		"sh85002782":   1,
+	}
+
+	for code, expected := range tests {
+
+		results, err := l.Find(ctx, code)
+
+		if err != nil {
+			t.Fatalf("Failed to find %s, %v", code, err)
+		}
+
+		if len(results) != expected {
+			t.Fatalf("Expected %d results for %s, got %d", expected, code, len(results))
+		}
+
+		for _, r := range results {
+
+			if _, ok := r.(*lcsh.SubjectHeading); !ok {
+				t.Fatalf("Unexpected record type %T for %s", r, code)
+			}
+		}
+	}
+
+	_, err = l.Find(ctx, "Zeppelins")
+
+	if !lcsh.IsNotFound(err) {
+		t.Fatalf("Expected NotFound error, got %v", err)
+	}
+
+	err = l.Append(ctx, &lcsh.SubjectHeading{Id: "sh00000000", Label: "Zeppelins"})
+
+	if err == nil {
+		t.Fatalf("Expected Append to fail")
+	}
+
+	iterated := 0
+
+	err = libraryofcongress.Iterate(ctx, l, func(ctx context.Context, r libraryofcongress.Record) error {
+		iterated += 1
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("Failed to iterate index lookup, %v", err)
+	}
+
+	if iterated != count {
+		t.Fatalf("Expected %d records, got %d", count, iterated)
+	}
+
+	_, err = libraryofcongress.NewLookup(ctx, fmt.Sprintf("lcnafidx://%s", index_path))
+
+	if err == nil {
+		t.Fatalf("Expected source mismatch to fail")
+	}
+}
+
+func TestNewIndexInvalid(t *testing.T) {
+
+	_, err := NewIndex([]byte("not an index"))
+
+	if err == nil {
+		t.Fatalf("Expected invalid index to fail")
+	}
+}
+
+func TestNewIndexCorrupt(t *testing.T) {
+
+	// A length prefix (the largest possible uvarint) which would overflow the end offset of the value
+
+	data := append([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}, []byte("abc")...)
+
+	_, _, err := readBytes(data, 0)
+
+	if err == nil {
+		t.Fatalf("Expected overflowing length to fail")
+	}
+
+	// Record and key counts which would overflow the table sizes
+
+	header := make([]byte, HEADER_SIZE)
+	copy(header, MAGIC)
+
+	for i := 8; i < 24; i++ {
+		header[i] = 0xff
+	}
+
+	_, err = NewIndex(header)
+
+	if err == nil {
+		t.Fatalf("Expected overflowing counts to fail")
+	}
+}
+
+func TestIndexClose(t *testing.T) {
+
+	b, err := NewBuilder(lcsh.SOURCE, "")
+
+	if err != nil {
+		t.Fatalf("Failed to create builder, %v", err)
+	}
+
+	for i := 0; i < 1000; i++ {
+		b.Add(fmt.Sprintf("sh%08d", i), fmt.Sprintf("Label %d", i))
+	}
+
+	index_path := filepath.Join(t.TempDir(), "lcsh.idx")
+
+	fh, err := os.Create(index_path)
+
+	if err != nil {
+		t.Fatalf("Failed to create index file, %v", err)
+	}
+
+	err = b.Write(fh)
+
+	if err != nil {
+		t.Fatalf("Failed to write index, %v", err)
+	}
+
+	err = fh.Close()
+
+	if err != nil {
+		t.Fatalf("Failed to close index file, %v", err)
+	}
+
+	idx, err := Open(index_path)
+
+	if err != nil {
+		t.Fatalf("Failed to open index, %v", err)
+	}
+
+	// Queries running concurrently with Close either succeed or fail with ErrClosed
+
+	errs := make(chan error, 4)
+
+	for w := 0; w < 4; w++ {
+
+		go func() {
+
+			for i := 0; ; i++ {
+
+				_, err := idx.FindId(fmt.Sprintf("sh%08d", i%1000))
+
+				if err != nil {
+
+					if err != ErrClosed {
+						errs <- err
+						return
+					}
+
+					errs <- nil
+					return
+				}
+			}
+		}()
+	}
+
+	err = idx.Close()
+
+	if err != nil {
+		t.Fatalf("Failed to close index, %v", err)
+	}
+
+	for w := 0; w < 4; w++ {
+
+		err := <-errs
+
+		if err != nil {
+			t.Fatalf("Unexpected error querying index, %v", err)
+		}
+	}
+
+	_, _, err = idx.Record(0)
+
+	if err != ErrClosed {
+		t.Fatalf("Expected ErrClosed, got %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+package index
+
+import (
+	"context"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcnaf"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcsh"
+	"net/url"
+)
+
+// type IndexLookup implements the `libraryofcongress.Lookup` and `libraryofcongress.IterableLookup` interfaces for
+// records stored in an index file. Index lookups are read-only.
+type IndexLookup struct {
+	libraryofcongress.Lookup
+	index     *Index
+	normalize libraryofcongress.NormalizeFunc
+}
+
+func init() {
+
+	ctx := context.Background()
+
+	libraryofcongress.RegisterLookup(ctx, "lcshidx", NewIndexLookup)
+	libraryofcongress.RegisterLookup(ctx, "lcnafidx", NewIndexLookup)
+}
+
+// NewIndexLookup() returns a new `IndexLookup` instance configured by 'uri' which is expected to take the form of:
+//
+//	lcnafidx:///path/to/lcnaf.idx
+//	lcshidx:///path/to/lcsh.idx
+//
+// The scheme must match the data source the index file was built from. Labels are normalized using the normalizers
+// recorded in the index file.
+func NewIndexLookup(ctx context.Context, uri string) (libraryofcongress.Lookup, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	path := u.Host + u.Path
+
+	if path == "" {
+		return nil, fmt.Errorf("Missing index path")
+	}
+
+	idx, err := Open(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open index, %w", err)
+	}
+
+	source := u.Scheme[0 : len(u.Scheme)-len("idx")]
+
+	if idx.Source() != source {
+		idx.Close()
+		return nil, fmt.Errorf("Index source (%s) does not match scheme (%s)", idx.Source(), u.Scheme)
+	}
+
+	return NewIndexLookupWithIndex(ctx, idx)
+}
+
+// NewIndexLookupWithIndex() returns a new `IndexLookup` instance for 'idx'.
+func NewIndexLookupWithIndex(ctx context.Context, idx *Index) (*IndexLookup, error) {
+
+	switch idx.Source() {
+	case lcsh.SOURCE, lcnaf.SOURCE:
+		// pass
+	default:
+		return nil, fmt.Errorf("Unsupported source, %s", idx.Source())
+	}
+
+	normalize, err := libraryofcongress.NewNormalizeFunc(idx.Normalize())
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create normalizer, %w", err)
+	}
+
+	l := &IndexLookup{
+		index:     idx,
+		normalize: normalize,
+	}
+
+	return l, nil
+}
+
+// Find() returns the records whose identifier, or normalized label, matches 'code'.
+func (l *IndexLookup) Find(ctx context.Context, code string) ([]interface{}, error) {
+
+	by_key, err := l.index.FindKey(l.normalize(code))
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find label, %w", err)
+	}
+
+	by_id, err := l.index.FindId(code)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find identifier, %w", err)
+	}
+
+	seen := make(map[int]bool)
+	results := make([]interface{}, 0)
+
+	for _, i := range append(by_id, by_key...) {
+
+		if seen[i] {
+			continue
+		}
+
+		seen[i] = true
+
+		r, err := l.record(i)
+
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, r)
+	}
+
+	if len(results) == 0 {
+		return nil, l.notFound(code)
+	}
+
+	return results, nil
+}
+
+// Append() returns an error because index lookups are read-only.
+func (l *IndexLookup) Append(ctx context.Context, data interface{}) error {
+	return fmt.Errorf("Index lookups are read-only")
+}
+
+// Iterate() invokes 'cb' for each record in the lookup. Records are returned sorted by identifier.
+func (l *IndexLookup) Iterate(ctx context.Context, cb libraryofcongress.IterateFunc) error {
+
+	for i := 0; i < l.index.Count(); i++ {
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			// pass
+		}
+
+		r, err := l.record(i)
+
+		if err != nil {
+			return err
+		}
+
+		err = cb(ctx, r.(libraryofcongress.Record))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close() releases the resources used by the underlying index. It is safe to call while other queries are in progress;
+// it waits for them to complete and any queries made afterwards return an error wrapping `ErrClosed`.
+func (l *IndexLookup) Close() error {
+	return l.index.Close()
+}
+
+// record() returns the i-th record in the index as an `lcsh.SubjectHeading` or `lcnaf.NamedAuthority` instance.
+func (l *IndexLookup) record(i int) (interface{}, error) {
+
+	id, label, err := l.index.Record(i)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read record, %w", err)
+	}
+
+	switch l.index.Source() {
+	case lcnaf.SOURCE:
+		return &lcnaf.NamedAuthority{Id: id, Label: label}, nil
+	default:
+		return &lcsh.SubjectHeading{Id: id, Label: label}, nil
+	}
+}
+
+// notFound() returns a source-specific `NotFound` error for 'code'.
+func (l *IndexLookup) notFound(code string) error {
+
+	switch l.index.Source() {
+	case lcnaf.SOURCE:
+		return lcnaf.NotFound{Code: code}
+	default:
+		return lcsh.NotFound{Code: code}
+	}
+}
@@ -0,0 +1,24 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris)
+
+package index
+
+import (
+	"os"
+)
+
+// mapFile() returns the contents of 'path' and a function to release them. On platforms without support
+// for memory maps the file is read in to memory.
+func mapFile(path string) ([]byte, func() error, error) {
+
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	close_func := func() error {
+		return nil
+	}
+
+	return data, close_func, nil
+}
@@ -0,0 +1,45 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package index
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// mapFile() returns the contents of 'path' as a read-only memory map and a function to release it.
+func mapFile(path string) ([]byte, func() error, error) {
+
+	fh, err := os.Open(path)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer fh.Close()
+
+	info, err := fh.Stat()
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to stat file, %w", err)
+	}
+
+	size := info.Size()
+
+	if size == 0 {
+		return nil, nil, fmt.Errorf("File is empty")
+	}
+
+	data, err := syscall.Mmap(int(fh.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to map file, %w", err)
+	}
+
+	close_func := func() error {
+		return syscall.Munmap(data)
+	}
+
+	return data, close_func, nil
+}
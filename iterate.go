@@ -0,0 +1,32 @@
+package libraryofcongress
+
+import (
+	"context"
+	"fmt"
+)
+
+// type IterateFunc is a function invoked for each record by the `Iterate` method of `IterableLookup` implementations.
+// If it returns an error then iteration stops and that error is returned by the `Iterate` method.
+type IterateFunc func(context.Context, Record) error
+
+// type IterableLookup is an optional interface, implemented by all the `Lookup` implementations in this package (and
+// its sub-packages), for enumerating all the unique records in a lookup.
+type IterableLookup interface {
+	Lookup
+	// Iterate() invokes an `IterateFunc` function for each unique record in the lookup. Records are not guaranteed to be
+	// returned in any particular order. Iteration stops if the context is cancelled.
+	Iterate(context.Context, IterateFunc) error
+}
+
+// Iterate() invokes 'cb' for each unique record in 'l'. It returns an error if 'l' does not implement the
+// `IterableLookup` interface.
+func Iterate(ctx context.Context, l Lookup, cb IterateFunc) error {
+
+	it, ok := l.(IterableLookup)
+
+	if !ok {
+		return fmt.Errorf("Lookup (%T) does not implement IterableLookup", l)
+	}
+
+	return it.Iterate(ctx, cb)
+}
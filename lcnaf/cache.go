@@ -0,0 +1,101 @@
+package lcnaf
+
+import (
+	"context"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/cache"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/table"
+	"io"
+	"sync/atomic"
+)
+
+// NewNamedAuthorityLookupFuncWithCache returns a `NamedAuthorityLookupFunc` function instance that, when invoked, will populate an
+// `lcnaf.NamedAuthorityLookup` instance with the records in the cache file, stored in 'cache_dir', for the (bzip2-compressed CSV) data
+// in 'r'. Cache files are keyed by the checksum of 'r' so if there is no cache file for the data, or it is invalid, the lookup
+// is populated by parsing 'r' and a new cache file is written. If 'r' does not implement `io.Seeker` then it is spooled to a
+// temporary file in 'cache_dir' in order to derive its checksum.
+func NewNamedAuthorityLookupFuncWithCache(ctx context.Context, r io.Reader, cache_dir string) NamedAuthorityLookupFunc {
+
+	// See notes in NewNamedAuthorityLookupFuncWithReader
+
+	invoked := int32(0)
+
+	lookup_func := func(ctx context.Context, l *NamedAuthorityLookup) error {
+
+		if !atomic.CompareAndSwapInt32(&invoked, 0, 1) {
+			return fmt.Errorf("Lookup func has already been invoked and its data consumed")
+		}
+
+		t := l.table()
+
+		// The source data is only copied if its checksum can't be derived in place
+
+		src, err := cache.OpenSource(ctx, cache_dir, r)
+
+		if err != nil {
+			return fmt.Errorf("Failed to open source data, %w", err)
+		}
+
+		defer src.Close()
+
+		checksum := src.Checksum
+		path := cache.Path(cache_dir, SOURCE, checksum)
+
+		err = cache.Read(ctx, path, SOURCE, checksum, func(id string, label string) error {
+			_, err := t.Add(id, label)
+			return err
+		})
+
+		if err == nil {
+			t.Sort()
+			return nil
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		// The cache file is missing or invalid so discard anything that was read from it and
+		// fall back to the source data
+
+		t.Reset()
+
+		err = t.Load(ctx, src.Reader, l.workers)
+
+		if err != nil {
+			return err
+		}
+
+		// Failing to write the cache file is not fatal; it will be tried again the next time
+		// a lookup is created.
+
+		writeCache(ctx, l, path, checksum)
+		return nil
+	}
+
+	return lookup_func
+}
+
+// writeCache() writes the records in 'l' to the cache file at 'path'.
+func writeCache(ctx context.Context, l *NamedAuthorityLookup, path string, checksum string) error {
+
+	wr, err := cache.NewWriter(path, SOURCE, checksum)
+
+	if err != nil {
+		return err
+	}
+
+	// The lookup is still being loaded when the cache is written so read from the
+	// table directly rather than waiting for the lookup to be ready.
+
+	err = l.table().Iterate(ctx, func(ctx context.Context, r table.Row) error {
+		return wr.Add(r.Id, r.Label)
+	})
+
+	if err != nil {
+		wr.Abort()
+		return err
+	}
+
+	return wr.Close()
+}
@@ -63,12 +63,23 @@ func OpenData(ctx context.Context, uri string) (io.ReadCloser, error) {
 
 	case "github":
 
-		rsp, err := http.Get(DATA_GITHUB)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, DATA_GITHUB, nil)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to create request for remote data from Github, %w", err)
+		}
+
+		rsp, err := http.DefaultClient.Do(req)
 
 		if err != nil {
 			return nil, fmt.Errorf("Failed to load remote data from Github, %w", err)
 		}
 
+		if rsp.StatusCode != http.StatusOK {
+			rsp.Body.Close()
+			return nil, fmt.Errorf("Failed to load remote data from Github, unexpected status %s", rsp.Status)
+		}
+
 		return rsp.Body, nil
 
 	case "file":
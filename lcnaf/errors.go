@@ -1,7 +1,9 @@
 package lcnaf
 
 import (
+	"errors"
 	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
 )
 
 // type NotFound is a struct for representing missing LCNAF records.
@@ -17,8 +19,18 @@ func (e NotFound) String() string {
 	return e.Error()
 }
 
-// type NotFound is a struct for representing LCNAF identifiers that return multiple records.
-type MultipleCandidates struct{ Code string }
+// Unwrap() returns the `libraryofcongress.NotFound` error corresponding to 'e' which allows 'e' to be matched
+// against `libraryofcongress.ErrNotFound` using `errors.Is`.
+func (e NotFound) Unwrap() error {
+	return libraryofcongress.NotFound{Code: e.Code, Source: SOURCE}
+}
+
+// type MultipleCandidates is a struct for representing LCNAF identifiers that return multiple records.
+type MultipleCandidates struct {
+	Code string
+	// Candidates is the list of records matching Code.
+	Candidates []*NamedAuthority
+}
 
 // Error() returns a stringified representation of 'e'.
 func (e MultipleCandidates) Error() string {
@@ -30,24 +42,33 @@ func (e MultipleCandidates) String() string {
 	return e.Error()
 }
 
-// IsNotFound returns a boolean value indicating whether 'e' is of type `NotFound`.
-func IsNotFound(e error) bool {
+// Unwrap() returns the `libraryofcongress.MultipleCandidates` error corresponding to 'e' which allows 'e' to be matched
+// against `libraryofcongress.ErrMultipleCandidates` using `errors.Is`.
+func (e MultipleCandidates) Unwrap() error {
 
-	switch e.(type) {
-	case NotFound, *NotFound:
-		return true
-	default:
-		return false
+	candidates := make([]interface{}, len(e.Candidates))
+
+	for idx, c := range e.Candidates {
+		candidates[idx] = c
 	}
+
+	return libraryofcongress.MultipleCandidates{Code: e.Code, Candidates: candidates}
 }
 
-// IsMultipleCandidates returns a boolean value indicating whether 'e' is of type `MultipleCandidates`.
+// IsNotFound returns a boolean value indicating whether 'e' is, or wraps, an error of type `NotFound`.
+func IsNotFound(e error) bool {
+
+	var v NotFound
+	var p *NotFound
+
+	return errors.As(e, &v) || errors.As(e, &p)
+}
+
+// IsMultipleCandidates returns a boolean value indicating whether 'e' is, or wraps, an error of type `MultipleCandidates`.
 func IsMultipleCandidates(e error) bool {
 
-	switch e.(type) {
-	case MultipleCandidates, *MultipleCandidates:
-		return true
-	default:
-		return false
-	}
+	var v MultipleCandidates
+	var p *MultipleCandidates
+
+	return errors.As(e, &v) || errors.As(e, &p)
 }
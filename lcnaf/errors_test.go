@@ -1,7 +1,9 @@
 package lcnaf
 
 import (
+	"errors"
 	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
 	"testing"
 )
 
@@ -13,6 +15,10 @@ func TestNotFound(t *testing.T) {
 		t.Fatalf("Expected error to be NotFound")
 	}
 
+	if !errors.Is(e, libraryofcongress.ErrNotFound) {
+		t.Fatalf("Expected error to match ErrNotFound")
+	}
+
 	e2 := fmt.Errorf("Testing")
 
 	if IsNotFound(e2) {
@@ -34,3 +40,46 @@ func TestMultipleCandidates(t *testing.T) {
 		t.Fatalf("Expected error to not be MultipleCandidates")
 	}
 }
+
+func TestWrappedErrors(t *testing.T) {
+
+	e := fmt.Errorf("Failed to find record, %w", NotFound{Code: "1234"})
+
+	if !IsNotFound(e) {
+		t.Fatalf("Expected wrapped error to be NotFound")
+	}
+
+	if !libraryofcongress.IsNotFound(e) {
+		t.Fatalf("Expected wrapped error to match libraryofcongress.ErrNotFound")
+	}
+
+	var nf libraryofcongress.NotFound
+
+	if !errors.As(e, &nf) {
+		t.Fatalf("Expected wrapped error to be convertible to libraryofcongress.NotFound")
+	}
+
+	if nf.Source != SOURCE {
+		t.Fatalf("Unexpected source, %s", nf.Source)
+	}
+
+	e2 := fmt.Errorf("Failed to find record, %w", &MultipleCandidates{Code: "1234", Candidates: []*NamedAuthority{&NamedAuthority{}, &NamedAuthority{}}})
+
+	if !IsMultipleCandidates(e2) {
+		t.Fatalf("Expected wrapped error to be MultipleCandidates")
+	}
+
+	var mc libraryofcongress.MultipleCandidates
+
+	if !errors.As(e2, &mc) {
+		t.Fatalf("Expected wrapped error to be convertible to libraryofcongress.MultipleCandidates")
+	}
+
+	if len(mc.Candidates) != 2 {
+		t.Fatalf("Unexpected candidates, %v", mc.Candidates)
+	}
+
+	if IsNotFound(e2) || libraryofcongress.IsNotFound(e2) {
+		t.Fatalf("Expected error to not be NotFound")
+	}
+}
@@ -0,0 +1,21 @@
+package lcnaf
+
+import (
+	"context"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/table"
+)
+
+// Iterate() invokes 'cb' for each unique record in the lookup. Records are returned in the order they were added to the lookup.
+func (l *NamedAuthorityLookup) Iterate(ctx context.Context, cb libraryofcongress.IterateFunc) error {
+
+	err := l.ready.Check(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	return l.table().Iterate(ctx, func(ctx context.Context, r table.Row) error {
+		return cb(ctx, &NamedAuthority{Id: r.Id, Label: r.Label})
+	})
+}
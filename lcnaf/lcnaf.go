@@ -5,6 +5,12 @@ import (
 	"fmt"
 )
 
+// SOURCE is the name used to identify LCNAF data and records.
+const SOURCE string = "lcnaf"
+
+// URI_TEMPLATE is the template used to derive the canonical id.loc.gov URI for a LCNAF identifier.
+const URI_TEMPLATE string = "http://id.loc.gov/authorities/names/%s"
+
 // NamedAuthority is a struct containing a subset of data for a LCNAF record.
 type NamedAuthority struct {
 	// Id is the unique identifier for this LCNAF record.
@@ -17,3 +23,23 @@ type NamedAuthority struct {
 func (na *NamedAuthority) String() string {
 	return fmt.Sprintf("%s %s", na.Id, na.Label)
 }
+
+// GetId() returns the unique identifier for this LCNAF record.
+func (na *NamedAuthority) GetId() string {
+	return na.Id
+}
+
+// GetLabel() returns the name (or title) for this LCNAF record.
+func (na *NamedAuthority) GetLabel() string {
+	return na.Label
+}
+
+// GetSource() returns the name of the data source for this LCNAF record.
+func (na *NamedAuthority) GetSource() string {
+	return SOURCE
+}
+
+// URI() returns the canonical id.loc.gov URI for this LCNAF record.
+func (na *NamedAuthority) URI() string {
+	return fmt.Sprintf(URI_TEMPLATE, na.Id)
+}
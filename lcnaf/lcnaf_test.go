@@ -15,3 +15,15 @@ func TestNamedAuthority(t *testing.T) {
 		t.Fatalf("Invalid stringification")
 	}
 }
+
+func TestNamedAuthorityURI(t *testing.T) {
+
+	na := NamedAuthority{
+		Id:    "n79100565",
+		Label: "Lindbergh, Charles A. (Charles Augustus), 1902-1974",
+	}
+
+	if na.URI() != "http://id.loc.gov/authorities/names/n79100565" {
+		t.Fatalf("Invalid URI: %s", na.URI())
+	}
+}
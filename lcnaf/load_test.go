@@ -0,0 +1,239 @@
+package lcnaf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNamedAuthorityLookupCancelled(t *testing.T) {
+
+	rel_path := "../fixtures/lcnaf.csv.bz2"
+	abs_path, err := filepath.Abs(rel_path)
+
+	if err != nil {
+		t.Fatalf("Failed to derive absolute path for %s, %v", rel_path, err)
+	}
+
+	uri := fmt.Sprintf("lcnaf://file%s", abs_path)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = NewNamedAuthorityLookup(ctx, uri)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled error, got %v", err)
+	}
+
+	// A failed load should not prevent a subsequent load from succeeding
+
+	ctx = context.Background()
+
+	l, err := NewNamedAuthorityLookup(ctx, uri)
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup after cancelled load, %v", err)
+	}
+
+	_, err = l.Find(ctx, "n79100565")
+
+	if err != nil {
+		t.Fatalf("Failed to find n79100565, %v", err)
+	}
+}
+
+func TestNamedAuthorityLookupFuncWithReaderConsumed(t *testing.T) {
+
+	ctx := context.Background()
+
+	r, err := os.Open("../fixtures/lcnaf.csv.bz2")
+
+	if err != nil {
+		t.Fatalf("Failed to open fixture data, %v", err)
+	}
+
+	defer r.Close()
+
+	lookup_func := NewNamedAuthorityLookupFuncWithReader(ctx, r)
+
+	_, err = NewNamedAuthorityLookupWithLookupFunc(ctx, lookup_func)
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	_, err = NewNamedAuthorityLookupWithLookupFunc(ctx, lookup_func)
+
+	if err == nil {
+		t.Fatalf("Expected an error invoking a consumed lookup func")
+	}
+}
+
+func TestNamedAuthorityLookupWorkers(t *testing.T) {
+
+	ctx := context.Background()
+
+	rel_path := "../fixtures/lcnaf.csv.bz2"
+	abs_path, err := filepath.Abs(rel_path)
+
+	if err != nil {
+		t.Fatalf("Failed to derive absolute path for %s, %v", rel_path, err)
+	}
+
+	sequential, err := NewNamedAuthorityLookup(ctx, fmt.Sprintf("lcnaf://file%s?workers=1", abs_path))
+
+	if err != nil {
+		t.Fatalf("Failed to create sequential lookup, %v", err)
+	}
+
+	parallel, err := NewNamedAuthorityLookup(ctx, fmt.Sprintf("lcnaf://file%s?workers=4", abs_path))
+
+	if err != nil {
+		t.Fatalf("Failed to create parallel lookup, %v", err)
+	}
+
+	count := 0
+
+	err = sequential.(*NamedAuthorityLookup).Iterate(ctx, func(ctx context.Context, r libraryofcongress.Record) error {
+
+		count += 1
+
+		for _, code := range []string{r.GetId(), r.GetLabel()} {
+
+			expected, err := sequential.Find(ctx, code)
+
+			if err != nil {
+				return fmt.Errorf("Failed to find '%s' in sequential lookup, %w", code, err)
+			}
+
+			results, err := parallel.Find(ctx, code)
+
+			if err != nil {
+				return fmt.Errorf("Failed to find '%s' in parallel lookup, %w", code, err)
+			}
+
+			if len(results) != len(expected) {
+				return fmt.Errorf("Expected %d results for '%s', got %d", len(expected), code, len(results))
+			}
+
+			for i, na := range results {
+
+				if na.(*NamedAuthority).String() != expected[i].(*NamedAuthority).String() {
+					return fmt.Errorf("Unexpected result %d for '%s', %s", i, code, na)
+				}
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count == 0 {
+		t.Fatalf("Sequential lookup has no records")
+	}
+}
+
+func TestNamedAuthorityLookupBackground(t *testing.T) {
+
+	ctx := context.Background()
+
+	r, err := os.Open("../fixtures/lcnaf.csv.bz2")
+
+	if err != nil {
+		t.Fatalf("Failed to open fixture data, %v", err)
+	}
+
+	defer r.Close()
+
+	// Block loading until 'release' is closed
+
+	release := make(chan struct{})
+
+	reader_func := NewNamedAuthorityLookupFuncWithReader(ctx, r)
+
+	lookup_func := func(ctx context.Context, l *NamedAuthorityLookup) error {
+		<-release
+		return reader_func(ctx, l)
+	}
+
+	opts := &NamedAuthorityLookupOptions{
+		Normalize:  libraryofcongress.NormalizeSubdivisions,
+		Background: true,
+	}
+
+	l, err := NewNamedAuthorityLookupWithLookupFuncAndOptions(ctx, lookup_func, opts)
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	_, err = l.Find(ctx, "n79100565")
+
+	if !libraryofcongress.IsNotReady(err) {
+		t.Fatalf("Expected NotReady error, got %v", err)
+	}
+
+	err = libraryofcongress.Iterate(ctx, l, func(ctx context.Context, r libraryofcongress.Record) error {
+		return nil
+	})
+
+	if !libraryofcongress.IsNotReady(err) {
+		t.Fatalf("Expected NotReady error iterating records, got %v", err)
+	}
+
+	close(release)
+
+	<-l.(libraryofcongress.ReadyLookup).Ready()
+
+	_, err = l.Find(ctx, "n79100565")
+
+	if err != nil {
+		t.Fatalf("Failed to find n79100565, %v", err)
+	}
+}
+
+func TestNamedAuthorityLookupBackgroundDetached(t *testing.T) {
+
+	rel_path := "../fixtures/lcnaf.csv.bz2"
+	abs_path, err := filepath.Abs(rel_path)
+
+	if err != nil {
+		t.Fatalf("Failed to derive absolute path for %s, %v", rel_path, err)
+	}
+
+	uri := fmt.Sprintf("lcnaf://file%s?background=true", abs_path)
+
+	// Background loading should not be tied to the context used to create the lookup
+
+	create_ctx, cancel := context.WithCancel(context.Background())
+
+	l, err := NewNamedAuthorityLookup(create_ctx, uri)
+
+	cancel()
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	ctx := context.Background()
+
+	err = libraryofcongress.Wait(ctx, l)
+
+	if err != nil {
+		t.Fatalf("Failed to load lookup after creation context was cancelled, %v", err)
+	}
+
+	_, err = l.Find(ctx, "Halstenberg, Friedrich")
+
+	if err != nil {
+		t.Fatalf("Failed to find Halstenberg, Friedrich, %v", err)
+	}
+}
@@ -83,6 +83,80 @@ func TestLCNAFLookup(t *testing.T) {
 			if a.Id != lcnaf_id {
 				t.Fatalf("Invalid match for '%s' using scheme '%s', expected '%s' but got '%s'", label, s, lcnaf_id, a.Id)
 			}
+
+			results, err = lu.Find(ctx, lcnaf_id)
+
+			if err != nil {
+				t.Fatalf("Unable to find '%s' using scheme '%s', %v", lcnaf_id, s, err)
+			}
+
+			if len(results) != 1 {
+				t.Fatalf("Invalid results for '%s' using scheme '%s'", s, lcnaf_id)
+			}
+
+			a = results[0].(*NamedAuthority)
+
+			if a.Label != label {
+				t.Fatalf("Invalid match for '%s' using scheme '%s', expected '%s' but got '%s'", lcnaf_id, s, label, a.Label)
+			}
 		}
 	}
 }
+
+func TestLCNAFLookupInstances(t *testing.T) {
+
+	ctx := context.Background()
+
+	rel_path := "../fixtures/lcnaf.csv.bz2"
+	abs_path, err := filepath.Abs(rel_path)
+
+	if err != nil {
+		t.Fatalf("Failed to derive absolute path for %s, %v", rel_path, err)
+	}
+
+	file_uri := fmt.Sprintf("lcnaf://file%s", abs_path)
+
+	lu_a, err := libraryofcongress.NewLookup(ctx, file_uri)
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup for '%s', %v", file_uri, err)
+	}
+
+	lu_b, err := libraryofcongress.NewLookup(ctx, file_uri)
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup for '%s', %v", file_uri, err)
+	}
+
+	na := &NamedAuthority{
+		Id:    "no2006016666",
+		Label: "Paige, Colin",
+	}
+
+	err = lu_a.Append(ctx, na)
+
+	if err != nil {
+		t.Fatalf("Failed to append record, %v", err)
+	}
+
+	for _, code := range []string{na.Label, na.Id} {
+
+		_, err = lu_a.Find(ctx, code)
+
+		if err != nil {
+			t.Fatalf("Failed to find appended record '%s', %v", code, err)
+		}
+
+		_, err = lu_b.Find(ctx, code)
+
+		if !IsNotFound(err) {
+			t.Fatalf("Expected '%s' to be absent from second lookup, %v", code, err)
+		}
+	}
+
+	_, err = lu_b.Find(ctx, "n79100565")
+
+	if err != nil {
+		t.Fatalf("Failed to find 'n79100565' in second lookup, %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package lcnaf
+
+import (
+	"context"
+	"fmt"
+)
+
+// Remove() removes all the records whose identifier is 'id' from the lookup. If there are no matching records
+// a `NotFound` error is returned.
+func (l *NamedAuthorityLookup) Remove(ctx context.Context, id string) error {
+
+	err := l.ready.Check(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.removeData(ctx, id)
+}
+
+// Update() replaces all the records whose identifier matches the identifier of 'data' with 'data', for example when
+// the label for a heading has been changed. If there are no matching records a `NotFound` error is returned.
+func (l *NamedAuthorityLookup) Update(ctx context.Context, data interface{}) error {
+
+	na, ok := data.(*NamedAuthority)
+
+	if !ok {
+		return fmt.Errorf("Unsupported record type %T", data)
+	}
+
+	err := l.ready.Check(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.replaceData(ctx, na.Id, na)
+}
+
+// Replace() removes all the records whose identifier is 'id' and appends 'data' in their place, for example when a
+// heading has been deprecated in favour of a new heading. If there are no records matching 'id' a `NotFound` error
+// is returned.
+func (l *NamedAuthorityLookup) Replace(ctx context.Context, id string, data interface{}) error {
+
+	na, ok := data.(*NamedAuthority)
+
+	if !ok {
+		return fmt.Errorf("Unsupported record type %T", data)
+	}
+
+	err := l.ready.Check(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.replaceData(ctx, id, na)
+}
@@ -0,0 +1,103 @@
+package lcnaf
+
+import (
+	"context"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"path/filepath"
+	"testing"
+)
+
+func TestMutableLookup(t *testing.T) {
+
+	ctx := context.Background()
+
+	rel_path := "../fixtures/lcnaf.csv.bz2"
+	abs_path, err := filepath.Abs(rel_path)
+
+	if err != nil {
+		t.Fatalf("Failed to derive absolute path for %s, %v", rel_path, err)
+	}
+
+	l, err := libraryofcongress.NewLookup(ctx, fmt.Sprintf("lcnaf://file%s", abs_path))
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	m, ok := l.(libraryofcongress.MutableLookup)
+
+	if !ok {
+		t.Fatalf("Lookup does not implement MutableLookup")
+	}
+
+	// Update (rename)
+
+	err = m.Update(ctx, &NamedAuthority{Id: "n79100565", Label: "Lindbergh, Charles A., 1902-1974"})
+
+	if err != nil {
+		t.Fatalf("Failed to update n79100565, %v", err)
+	}
+
+	_, err = l.Find(ctx, "Lindbergh, Charles A. (Charles Augustus), 1902-1974")
+
+	if !IsNotFound(err) {
+		t.Fatalf("Expected old label to be not found, got %v", err)
+	}
+
+	rsp, err := l.Find(ctx, "Lindbergh, Charles A., 1902-1974")
+
+	if err != nil {
+		t.Fatalf("Failed to find new label, %v", err)
+	}
+
+	if len(rsp) != 1 || rsp[0].(*NamedAuthority).Id != "n79100565" {
+		t.Fatalf("Unexpected results for new label, %v", rsp)
+	}
+
+	rsp, err = l.Find(ctx, "n79100565")
+
+	if err != nil || len(rsp) != 1 {
+		t.Fatalf("Unexpected results for n79100565, %v %v", rsp, err)
+	}
+
+	// Replace (deprecate)
+
+	err = m.Replace(ctx, "n79100565", &NamedAuthority{Id: "n00000001x", Label: "Lindbergh, Charles A., 1902-1974"})
+
+	if err != nil {
+		t.Fatalf("Failed to replace n79100565, %v", err)
+	}
+
+	_, err = l.Find(ctx, "n79100565")
+
+	if !IsNotFound(err) {
+		t.Fatalf("Expected replaced identifier to be not found, got %v", err)
+	}
+
+	rsp, err = l.Find(ctx, "Lindbergh, Charles A., 1902-1974")
+
+	if err != nil || len(rsp) != 1 || rsp[0].(*NamedAuthority).Id != "n00000001x" {
+		t.Fatalf("Unexpected results for replacement, %v %v", rsp, err)
+	}
+
+	// Remove
+
+	err = m.Remove(ctx, "n00000001x")
+
+	if err != nil {
+		t.Fatalf("Failed to remove n00000001x, %v", err)
+	}
+
+	_, err = l.Find(ctx, "Lindbergh, Charles A., 1902-1974")
+
+	if !IsNotFound(err) {
+		t.Fatalf("Expected removed record to be not found, got %v", err)
+	}
+
+	err = m.Remove(ctx, "n00000001x")
+
+	if !IsNotFound(err) {
+		t.Fatalf("Expected removing a missing record to fail with NotFound, got %v", err)
+	}
+}
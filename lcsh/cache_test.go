@@ -0,0 +1,117 @@
+package lcsh
+
+import (
+	"context"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/cache"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSubjectHeadingLookupCache(t *testing.T) {
+
+	ctx := context.Background()
+
+	rel_path := "../fixtures/lcsh.csv.bz2"
+	abs_path, err := filepath.Abs(rel_path)
+
+	if err != nil {
+		t.Fatalf("Failed to derive absolute path for %s, %v", rel_path, err)
+	}
+
+	cache_dir := t.TempDir()
+
+	uri := fmt.Sprintf("lcsh://file%s?cache=%s", abs_path, cache_dir)
+
+	find := func() {
+
+		l, err := NewSubjectHeadingLookup(ctx, uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create lookup, %v", err)
+		}
+
+		results, err := l.Find(ctx, "Cooking")
+
+		if err != nil {
+			t.Fatalf("Failed to find Cooking, %v", err)
+		}
+
+		if len(results) != 2 {
+			t.Fatalf("Expected 2 results, got %d", len(results))
+		}
+	}
+
+	// Populate the cache
+
+	find()
+
+	matches, err := filepath.Glob(filepath.Join(cache_dir, "*"))
+
+	if err != nil {
+		t.Fatalf("Failed to list cache directory, %v", err)
+	}
+
+	if len(matches) != 1 {
+		t.Fatalf("Expected exactly one cache file, got %v", matches)
+	}
+
+	// Load from the cache
+
+	find()
+
+	// Invalid cache files should be replaced
+
+	err = os.WriteFile(matches[0], []byte("invalid"), 0644)
+
+	if err != nil {
+		t.Fatalf("Failed to overwrite cache file, %v", err)
+	}
+
+	find()
+
+	info, err := os.Stat(matches[0])
+
+	if err != nil {
+		t.Fatalf("Failed to stat cache file, %v", err)
+	}
+
+	if info.Size() <= int64(len("invalid")) {
+		t.Fatalf("Expected invalid cache file to be replaced")
+	}
+
+	// Cache files with a corrupt length prefix should be replaced rather than causing a panic
+
+	data, err := os.ReadFile(matches[0])
+
+	if err != nil {
+		t.Fatalf("Failed to read cache file, %v", err)
+	}
+
+	// The length of the first record's identifier follows the cache header and the record marker
+
+	offset := len(cache.MAGIC) + 1 + len(SOURCE) + 1 + 64 + 1
+
+	corrupted := append([]byte{}, data[:offset]...)
+	corrupted = append(corrupted, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01)
+	corrupted = append(corrupted, data[offset+1:]...)
+
+	err = os.WriteFile(matches[0], corrupted, 0644)
+
+	if err != nil {
+		t.Fatalf("Failed to overwrite cache file, %v", err)
+	}
+
+	find()
+
+	replaced, err := os.ReadFile(matches[0])
+
+	if err != nil {
+		t.Fatalf("Failed to read cache file, %v", err)
+	}
+
+	if string(replaced) != string(data) {
+		t.Fatalf("Expected corrupt cache file to be replaced")
+	}
+}
@@ -0,0 +1,54 @@
+package lcsh
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindOne(t *testing.T) {
+
+	ctx := context.Background()
+
+	rel_path := "../fixtures/lcsh.csv.bz2"
+	abs_path, err := filepath.Abs(rel_path)
+
+	if err != nil {
+		t.Fatalf("Failed to derive absolute path for %s, %v", rel_path, err)
+	}
+
+	l, err := NewSubjectHeadingLookup(ctx, fmt.Sprintf("lcsh://file%s", abs_path))
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	sh_l := l.(*SubjectHeadingLookup)
+
+	sh, err := sh_l.FindOne(ctx, "Airplanes")
+
+	if err != nil {
+		t.Fatalf("Failed to find Airplanes, %v", err)
+	}
+
+	if sh.Id != "sh85002782" {
+		t.Fatalf("Unexpected record, %v", sh)
+	}
+
+	_, err = sh_l.FindOne(ctx, "Cooking")
+
+	if !IsMultipleCandidates(err) {
+		t.Fatalf("Expected MultipleCandidates error, got %v", err)
+	}
+
+	if len(err.(MultipleCandidates).Candidates) != 2 {
+		t.Fatalf("Expected 2 candidates")
+	}
+
+	_, err = sh_l.FindOne(ctx, "Unknown")
+
+	if !IsNotFound(err) {
+		t.Fatalf("Expected NotFound error, got %v", err)
+	}
+}
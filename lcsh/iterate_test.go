@@ -0,0 +1,55 @@
+package lcsh
+
+import (
+	"context"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"path/filepath"
+	"testing"
+)
+
+func TestIterate(t *testing.T) {
+
+	ctx := context.Background()
+
+	rel_path := "../fixtures/lcsh.csv.bz2"
+	abs_path, err := filepath.Abs(rel_path)
+
+	if err != nil {
+		t.Fatalf("Failed to derive absolute path for %s, %v", rel_path, err)
+	}
+
+	l, err := NewSubjectHeadingLookup(ctx, fmt.Sprintf("lcsh://file%s", abs_path))
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	seen := make(map[string]int)
+
+	cb := func(ctx context.Context, r libraryofcongress.Record) error {
+		seen[r.GetId()] += 1
+		return nil
+	}
+
+	err = libraryofcongress.Iterate(ctx, l, cb)
+
+	if err != nil {
+		t.Fatalf("Failed to iterate records, %v", err)
+	}
+
+	// Cooking (sh2010007517) is listed twice in the fixture data
+
+	if seen["sh85002782"] != 1 || seen["sh2010007517"] != 2 {
+		t.Fatalf("Unexpected records, %v", seen)
+	}
+
+	cancel_ctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	err = libraryofcongress.Iterate(cancel_ctx, l, cb)
+
+	if err != context.Canceled {
+		t.Fatalf("Expected iteration to be cancelled, got %v", err)
+	}
+}
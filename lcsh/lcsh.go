@@ -5,6 +5,12 @@ import (
 	"fmt"
 )
 
+// SOURCE is the name used to identify LCSH data and records.
+const SOURCE string = "lcsh"
+
+// URI_TEMPLATE is the template used to derive the canonical id.loc.gov URI for a LCSH identifier.
+const URI_TEMPLATE string = "http://id.loc.gov/authorities/subjects/%s"
+
 // SubjectHeading is a struct containing a subset of data for a LCSH record.
 type SubjectHeading struct {
 	// Id is the unique identifier for this LCSH record.
@@ -17,3 +23,23 @@ type SubjectHeading struct {
 func (sh *SubjectHeading) String() string {
 	return fmt.Sprintf("%s %s", sh.Id, sh.Label)
 }
+
+// GetId() returns the unique identifier for this LCSH record.
+func (sh *SubjectHeading) GetId() string {
+	return sh.Id
+}
+
+// GetLabel() returns the name (or title) for this LCSH record.
+func (sh *SubjectHeading) GetLabel() string {
+	return sh.Label
+}
+
+// GetSource() returns the name of the data source for this LCSH record.
+func (sh *SubjectHeading) GetSource() string {
+	return SOURCE
+}
+
+// URI() returns the canonical id.loc.gov URI for this LCSH record.
+func (sh *SubjectHeading) URI() string {
+	return fmt.Sprintf(URI_TEMPLATE, sh.Id)
+}
@@ -0,0 +1,473 @@
+package lcsh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestSubjectHeadingLookupCancelled(t *testing.T) {
+
+	rel_path := "../fixtures/lcsh.csv.bz2"
+	abs_path, err := filepath.Abs(rel_path)
+
+	if err != nil {
+		t.Fatalf("Failed to derive absolute path for %s, %v", rel_path, err)
+	}
+
+	uri := fmt.Sprintf("lcsh://file%s", abs_path)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = NewSubjectHeadingLookup(ctx, uri)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled error, got %v", err)
+	}
+
+	// A failed load should not prevent a subsequent load from succeeding
+
+	ctx = context.Background()
+
+	l, err := NewSubjectHeadingLookup(ctx, uri)
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup after cancelled load, %v", err)
+	}
+
+	_, err = l.Find(ctx, "Cooking")
+
+	if err != nil {
+		t.Fatalf("Failed to find Cooking, %v", err)
+	}
+}
+
+func TestSubjectHeadingLookupFuncWithReaderConsumed(t *testing.T) {
+
+	ctx := context.Background()
+
+	r, err := os.Open("../fixtures/lcsh.csv.bz2")
+
+	if err != nil {
+		t.Fatalf("Failed to open fixture data, %v", err)
+	}
+
+	defer r.Close()
+
+	lookup_func := NewSubjectHeadingLookupFuncWithReader(ctx, r)
+
+	_, err = NewSubjectHeadingLookupWithLookupFunc(ctx, lookup_func)
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	_, err = NewSubjectHeadingLookupWithLookupFunc(ctx, lookup_func)
+
+	if err == nil {
+		t.Fatalf("Expected an error invoking a consumed lookup func")
+	}
+}
+
+func TestSubjectHeadingLookupWorkers(t *testing.T) {
+
+	if testing.Short() {
+		t.Skip("Skipping comparison of embedded data loaded with different numbers of workers in short mode")
+	}
+
+	ctx := context.Background()
+
+	sequential, err := NewSubjectHeadingLookup(ctx, "lcsh://?workers=1")
+
+	if err != nil {
+		t.Fatalf("Failed to create sequential lookup, %v", err)
+	}
+
+	parallel, err := NewSubjectHeadingLookup(ctx, "lcsh://?workers=4")
+
+	if err != nil {
+		t.Fatalf("Failed to create parallel lookup, %v", err)
+	}
+
+	// Compare a sample of the records since finding every record in both lookups is slow
+
+	count := 0
+
+	err = sequential.(*SubjectHeadingLookup).Iterate(ctx, func(ctx context.Context, r libraryofcongress.Record) error {
+
+		count += 1
+
+		if count%50 != 0 {
+			return nil
+		}
+
+		for _, code := range []string{r.GetId(), r.GetLabel()} {
+
+			expected, err := sequential.Find(ctx, code)
+
+			if err != nil {
+				return fmt.Errorf("Failed to find '%s' in sequential lookup, %w", code, err)
+			}
+
+			results, err := parallel.Find(ctx, code)
+
+			if err != nil {
+				return fmt.Errorf("Failed to find '%s' in parallel lookup, %w", code, err)
+			}
+
+			if len(results) != len(expected) {
+				return fmt.Errorf("Expected %d results for '%s', got %d", len(expected), code, len(results))
+			}
+
+			for i, sh := range results {
+
+				if sh.(*SubjectHeading).String() != expected[i].(*SubjectHeading).String() {
+					return fmt.Errorf("Unexpected result %d for '%s', %s", i, code, sh)
+				}
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count == 0 {
+		t.Fatalf("Sequential lookup has no records")
+	}
+}
+
+func BenchmarkSubjectHeadingLookupWorkers(b *testing.B) {
+
+	ctx := context.Background()
+
+	for _, workers := range []int{1, 2, 4, 8} {
+
+		uri := fmt.Sprintf("lcsh://?workers=%d", workers)
+
+		b.Run(fmt.Sprintf("workers-%d", workers), func(b *testing.B) {
+
+			for i := 0; i < b.N; i++ {
+
+				_, err := NewSubjectHeadingLookup(ctx, uri)
+
+				if err != nil {
+					b.Fatalf("Failed to create lookup for '%s', %v", uri, err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkSubjectHeadingLookupMemory(b *testing.B) {
+
+	ctx := context.Background()
+
+	for i := 0; i < b.N; i++ {
+
+		var before runtime.MemStats
+		var after runtime.MemStats
+
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+
+		l, err := NewSubjectHeadingLookup(ctx, "lcsh://")
+
+		if err != nil {
+			b.Fatalf("Failed to create lookup, %v", err)
+		}
+
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+
+		count := 0
+
+		err = l.(*SubjectHeadingLookup).Iterate(ctx, func(ctx context.Context, r libraryofcongress.Record) error {
+			count += 1
+			return nil
+		})
+
+		if err != nil {
+			b.Fatalf("Failed to iterate lookup, %v", err)
+		}
+
+		heap := float64(after.HeapAlloc) - float64(before.HeapAlloc)
+
+		b.ReportMetric(heap/(1024*1024), "heap-MB")
+		b.ReportMetric(heap/float64(count), "heap-bytes/record")
+
+		runtime.KeepAlive(l)
+	}
+}
+
+func TestSubjectHeadingLookupBackground(t *testing.T) {
+
+	ctx := context.Background()
+
+	r, err := os.Open("../fixtures/lcsh.csv.bz2")
+
+	if err != nil {
+		t.Fatalf("Failed to open fixture data, %v", err)
+	}
+
+	defer r.Close()
+
+	// Block loading until 'release' is closed
+
+	release := make(chan struct{})
+
+	reader_func := NewSubjectHeadingLookupFuncWithReader(ctx, r)
+
+	lookup_func := func(ctx context.Context, l *SubjectHeadingLookup) error {
+		<-release
+		return reader_func(ctx, l)
+	}
+
+	opts := &SubjectHeadingLookupOptions{
+		Normalize:  libraryofcongress.NormalizeSubdivisions,
+		Background: true,
+	}
+
+	l, err := NewSubjectHeadingLookupWithLookupFuncAndOptions(ctx, lookup_func, opts)
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	sh_l := l.(*SubjectHeadingLookup)
+
+	select {
+	case <-sh_l.Ready():
+		t.Fatalf("Expected lookup to not be ready")
+	default:
+		// pass
+	}
+
+	_, err = l.Find(ctx, "Cooking")
+
+	if !libraryofcongress.IsNotReady(err) {
+		t.Fatalf("Expected NotReady error, got %v", err)
+	}
+
+	err = l.Append(ctx, &SubjectHeading{Id: "sh00000001", Label: "Tacos"})
+
+	if !libraryofcongress.IsNotReady(err) {
+		t.Fatalf("Expected NotReady error appending record, got %v", err)
+	}
+
+	close(release)
+
+	err = libraryofcongress.Wait(ctx, l)
+
+	if err != nil {
+		t.Fatalf("Failed to load lookup, %v", err)
+	}
+
+	_, err = l.Find(ctx, "Cooking")
+
+	if err != nil {
+		t.Fatalf("Failed to find Cooking, %v", err)
+	}
+}
+
+func TestSubjectHeadingLookupBackgroundWait(t *testing.T) {
+
+	ctx := context.Background()
+
+	r, err := os.Open("../fixtures/lcsh.csv.bz2")
+
+	if err != nil {
+		t.Fatalf("Failed to open fixture data, %v", err)
+	}
+
+	defer r.Close()
+
+	release := make(chan struct{})
+
+	reader_func := NewSubjectHeadingLookupFuncWithReader(ctx, r)
+
+	lookup_func := func(ctx context.Context, l *SubjectHeadingLookup) error {
+		<-release
+		return reader_func(ctx, l)
+	}
+
+	opts := &SubjectHeadingLookupOptions{
+		Normalize:  libraryofcongress.NormalizeSubdivisions,
+		Background: true,
+		Wait:       true,
+	}
+
+	l, err := NewSubjectHeadingLookupWithLookupFuncAndOptions(ctx, lookup_func, opts)
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	// Find should respect its context while waiting for the lookup to load
+
+	timeout_ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	_, err = l.Find(timeout_ctx, "Cooking")
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded error, got %v", err)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+	}()
+
+	_, err = l.Find(ctx, "Cooking")
+
+	if err != nil {
+		t.Fatalf("Failed to find Cooking, %v", err)
+	}
+}
+
+func TestSubjectHeadingLookupBackgroundFailed(t *testing.T) {
+
+	ctx := context.Background()
+
+	load_err := fmt.Errorf("Failed to read data")
+
+	lookup_func := func(ctx context.Context, l *SubjectHeadingLookup) error {
+		return load_err
+	}
+
+	opts := &SubjectHeadingLookupOptions{
+		Normalize:  libraryofcongress.NormalizeSubdivisions,
+		Background: true,
+	}
+
+	l, err := NewSubjectHeadingLookupWithLookupFuncAndOptions(ctx, lookup_func, opts)
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	err = libraryofcongress.Wait(ctx, l)
+
+	if !errors.Is(err, load_err) {
+		t.Fatalf("Expected load error waiting for lookup, got %v", err)
+	}
+
+	_, err = l.Find(ctx, "Cooking")
+
+	if !errors.Is(err, load_err) {
+		t.Fatalf("Expected load error finding record, got %v", err)
+	}
+}
+
+func TestSubjectHeadingLookupBackgroundURI(t *testing.T) {
+
+	ctx := context.Background()
+
+	rel_path := "../fixtures/lcsh.csv.bz2"
+	abs_path, err := filepath.Abs(rel_path)
+
+	if err != nil {
+		t.Fatalf("Failed to derive absolute path for %s, %v", rel_path, err)
+	}
+
+	uri := fmt.Sprintf("lcsh://file%s?background=true&wait=true", abs_path)
+
+	l, err := NewSubjectHeadingLookup(ctx, uri)
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	_, err = l.Find(ctx, "Cooking")
+
+	if err != nil {
+		t.Fatalf("Failed to find Cooking, %v", err)
+	}
+
+	_, err = NewSubjectHeadingLookup(ctx, fmt.Sprintf("lcsh://file%s?background=maybe", abs_path))
+
+	if err == nil {
+		t.Fatalf("Expected an error for an invalid background parameter")
+	}
+}
+
+func TestSubjectHeadingLookupBackgroundDetached(t *testing.T) {
+
+	rel_path := "../fixtures/lcsh.csv.bz2"
+	abs_path, err := filepath.Abs(rel_path)
+
+	if err != nil {
+		t.Fatalf("Failed to derive absolute path for %s, %v", rel_path, err)
+	}
+
+	uri := fmt.Sprintf("lcsh://file%s?background=true", abs_path)
+
+	// Background loading should not be tied to the context used to create the lookup
+
+	create_ctx, cancel := context.WithCancel(context.Background())
+
+	l, err := NewSubjectHeadingLookup(create_ctx, uri)
+
+	cancel()
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	ctx := context.Background()
+
+	err = libraryofcongress.Wait(ctx, l)
+
+	if err != nil {
+		t.Fatalf("Failed to load lookup after creation context was cancelled, %v", err)
+	}
+
+	_, err = l.Find(ctx, "Cooking")
+
+	if err != nil {
+		t.Fatalf("Failed to find Cooking, %v", err)
+	}
+}
+
+func TestSubjectHeadingLookupBackgroundClose(t *testing.T) {
+
+	ctx := context.Background()
+
+	lookup_func := func(ctx context.Context, l *SubjectHeadingLookup) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	opts := &SubjectHeadingLookupOptions{
+		Normalize:  libraryofcongress.NormalizeSubdivisions,
+		Background: true,
+	}
+
+	l, err := NewSubjectHeadingLookupWithLookupFuncAndOptions(ctx, lookup_func, opts)
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	err = l.(*SubjectHeadingLookup).Close()
+
+	if err != nil {
+		t.Fatalf("Failed to close lookup, %v", err)
+	}
+
+	err = libraryofcongress.Wait(ctx, l)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled error after closing lookup, got %v", err)
+	}
+}
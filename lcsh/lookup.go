@@ -1,106 +1,192 @@
 package lcsh
 
 import (
-	"compress/bzip2"
 	"context"
 	"fmt"
-	"github.com/sfomuseum/go-csvdict"
 	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/cache"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/table"
 	"io"
-	"strings"
+	"net/url"
+	"strconv"
 	"sync"
 	"sync/atomic"
 )
 
-var lookup_table *sync.Map
-var lookup_idx int64
-
-var lookup_init sync.Once
-var lookup_init_err error
-
-type SubjectHeadingLookupFunc func(context.Context)
+// type SubjectHeadingLookupFunc is a function used to populate the lookup table for a `SubjectHeadingLookup` instance.
+type SubjectHeadingLookupFunc func(context.Context, *SubjectHeadingLookup) error
 
+// type SubjectHeadingLookup implements the `libraryofcongress.Lookup` interface for LCSH data. Each instance
+// maintains its own lookup table so it is possible to have multiple instances, derived from different data
+// sources, in the same process.
 type SubjectHeadingLookup struct {
 	libraryofcongress.Lookup
+	// current is the lookup table currently in use, stored as a `*table.Table`. It is replaced atomically when
+	// the lookup is reloaded.
+	current *atomic.Value
+	// normalize is the function used to normalize labels, and the codes used to query them.
+	normalize libraryofcongress.NormalizeFunc
+	// workers is the number of workers used to load data.
+	workers int
+	// mu is used to serialize changes (appends, removals, updates) made after the lookup has been loaded.
+	mu *sync.Mutex
+	// ready tracks whether the lookup has finished loading.
+	ready *libraryofcongress.Readiness
+	// reload_mu is used to serialize reloads of the lookup.
+	reload_mu *sync.Mutex
+	// cancel stops loading data in the background. It is nil if the data was not loaded in the background.
+	cancel context.CancelFunc
+}
+
+// type SubjectHeadingLookupOptions defines configuration options for `SubjectHeadingLookup` instances.
+type SubjectHeadingLookupOptions struct {
+	// Normalize is the function used to normalize labels, and the codes used to query them, at index and query time.
+	Normalize libraryofcongress.NormalizeFunc
+	// Cache is the (optional) path to a directory where a cache of the records derived from the source data is stored.
+	Cache string
+	// Workers is the number of workers used to load data. If less than 1 then `libraryofcongress.DefaultWorkers()` is used.
+	Workers int
+	// Background signals that data should be loaded in the background rather than before the lookup is returned.
+	Background bool
+	// Wait signals that queries made while data is being loaded in the background should wait for loading to complete
+	// rather than returning a `libraryofcongress.NotReady` error.
+	Wait bool
 }
 
 func init() {
 	ctx := context.Background()
 	libraryofcongress.RegisterLookup(ctx, "lcsh", NewSubjectHeadingLookup)
-
-	lookup_idx = int64(0)
 }
 
 func NewSubjectHeadingLookup(ctx context.Context, uri string) (libraryofcongress.Lookup, error) {
 
-	r, err := OpenData(ctx, uri)
+	opts, err := NewSubjectHeadingLookupOptions(ctx, uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to derive lookup options from '%s', %w", uri, err)
+	}
+
+	// Data loaded in the background is read after this function returns so it can not be opened using 'ctx',
+	// which may be scoped to a request or have a timeout. See notes in NewSubjectHeadingLookupWithLookupFuncAndOptions.
+
+	data_ctx := ctx
+
+	if opts.Background {
+		data_ctx = context.Background()
+	}
+
+	r, err := OpenData(data_ctx, uri)
 
 	if err != nil {
 		return nil, fmt.Errorf("Failed to open data for '%s', %w", uri, err)
 	}
 
-	defer r.Close()
+	lookup_func := newLookupFunc(data_ctx, r, opts.Cache)
+	return NewSubjectHeadingLookupWithLookupFuncAndOptions(ctx, lookup_func, opts)
+}
+
+// newLookupFunc() returns a `SubjectHeadingLookupFunc` function instance that will populate a `SubjectHeadingLookup` instance with
+// the data in 'r', using the cache stored in 'cache_dir' if not empty. The data may be loaded in the background so 'r'
+// is closed when the lookup func completes rather than when this function returns.
+func newLookupFunc(ctx context.Context, r io.ReadCloser, cache_dir string) SubjectHeadingLookupFunc {
 
-	lookup_func := NewSubjectHeadingLookupFuncWithReader(ctx, r)
-	return NewSubjectHeadingLookupWithLookupFunc(ctx, lookup_func)
+	var lookup_func SubjectHeadingLookupFunc
+
+	if cache_dir != "" {
+		lookup_func = NewSubjectHeadingLookupFuncWithCache(ctx, r, cache_dir)
+	} else {
+		lookup_func = NewSubjectHeadingLookupFuncWithReader(ctx, r)
+	}
+
+	close_func := func(ctx context.Context, l *SubjectHeadingLookup) error {
+		defer r.Close()
+		return lookup_func(ctx, l)
+	}
+
+	return close_func
 }
 
-// NewSubjectHeadingLookup will return an `SubjectHeadingLookupFunc` function instance that, when invoked, will populate an `lcsh.SubjectHeadingsLookup` instance with data stored in `r`.
-// `r` will be closed when the `SubjectHeadingLookupFunc` function instance is invoked.
-// It is assumed that the data in `r` will be formatted in the same way as the procompiled (embedded) data stored in `data/sfomuseum.json`.
-func NewSubjectHeadingLookupFuncWithReader(ctx context.Context, r io.ReadCloser) SubjectHeadingLookupFunc {
+// NewSubjectHeadingLookupOptions returns a new `SubjectHeadingLookupOptions` instance derived from 'uri'. Labels are normalized using the
+// comma-separated list of normalizers defined in the 'normalize' query parameter (or `libraryofcongress.DEFAULT_NORMALIZER`
+// if empty). If the 'cache' query parameter is present then records are cached in (and loaded from) that directory. The number
+// of workers used to load data is defined by the 'workers' query parameter (or `libraryofcongress.DefaultWorkers()` if empty).
+// If the 'background' query parameter is true then data is loaded in the background and if the 'wait' query parameter is
+// true then queries made while data is being loaded wait for loading to complete.
+func NewSubjectHeadingLookupOptions(ctx context.Context, uri string) (*SubjectHeadingLookupOptions, error) {
 
-	fh := bzip2.NewReader(r)
+	u, err := url.Parse(uri)
 
-	csv_r, err := csvdict.NewReader(fh)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	normalize, err := libraryofcongress.NewNormalizeFunc(q.Get(libraryofcongress.NORMALIZE_PARAMETER))
 
 	if err != nil {
+		return nil, fmt.Errorf("Failed to create normalizer, %w", err)
+	}
 
-		lookup_func := func(ctx context.Context) {
-			lookup_init_err = fmt.Errorf("Failed to create CSV reader, %w", err)
+	workers, err := libraryofcongress.NewWorkers(q.Get(libraryofcongress.WORKERS_PARAMETER))
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to derive workers, %w", err)
+	}
+
+	background := false
+	wait := false
+
+	if q.Has(libraryofcongress.BACKGROUND_PARAMETER) {
+
+		v, err := strconv.ParseBool(q.Get(libraryofcongress.BACKGROUND_PARAMETER))
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid ?background= parameter, %w", err)
 		}
 
-		return lookup_func
+		background = v
 	}
 
-	lookup_func := func(ctx context.Context) {
+	if q.Has(libraryofcongress.WAIT_PARAMETER) {
 
-		table := new(sync.Map)
+		v, err := strconv.ParseBool(q.Get(libraryofcongress.WAIT_PARAMETER))
 
-		for {
+		if err != nil {
+			return nil, fmt.Errorf("Invalid ?wait= parameter, %w", err)
+		}
 
-			select {
-			case <-ctx.Done():
-				return
-			default:
-				// pass
-			}
+		wait = v
+	}
 
-			row, err := csv_r.Read()
+	opts := &SubjectHeadingLookupOptions{
+		Normalize:  normalize,
+		Cache:      q.Get(cache.CACHE_PARAMETER),
+		Workers:    workers,
+		Background: background,
+		Wait:       wait,
+	}
 
-			if err == io.EOF {
-				break
-			}
+	return opts, nil
+}
+
+// NewSubjectHeadingLookup will return an `SubjectHeadingLookupFunc` function instance that, when invoked, will populate an `lcsh.SubjectHeadingsLookup` instance with data stored in `r`.
+// `r` will be closed when the `SubjectHeadingLookupFunc` function instance is invoked.
+// It is assumed that the data in `r` will be formatted in the same way as the procompiled (embedded) data stored in `data/sfomuseum.json`.
+func NewSubjectHeadingLookupFuncWithReader(ctx context.Context, r io.ReadCloser) SubjectHeadingLookupFunc {
 
-			if err != nil {
-				lookup_init_err = fmt.Errorf("Failed to read row, %w", err)
-				return
-			}
+	// The data in 'r' can only be read once so keep track of whether the lookup func has already
+	// been invoked. Callers wanting to retry a failed load should open the data again.
 
-			sh := &SubjectHeading{
-				Id:    row["id"],
-				Label: row["label"],
-			}
+	invoked := int32(0)
 
-			err = appendData(ctx, table, sh)
+	lookup_func := func(ctx context.Context, l *SubjectHeadingLookup) error {
 
-			if err != nil {
-				lookup_init_err = fmt.Errorf("Failed to append row (%s), %w", sh, err)
-				return
-			}
+		if !atomic.CompareAndSwapInt32(&invoked, 0, 1) {
+			return fmt.Errorf("Lookup func has already been invoked and its data consumed")
 		}
 
-		lookup_table = table
+		return l.table().Load(ctx, r, l.workers)
 	}
 
 	return lookup_func
@@ -109,103 +195,202 @@ func NewSubjectHeadingLookupFuncWithReader(ctx context.Context, r io.ReadCloser)
 // NewSubjectHeadingLookupWithLookupFunc will return an `lcsh.SubjectHeadingsLookup` instance derived by data compiled using `lookup_func`.
 func NewSubjectHeadingLookupWithLookupFunc(ctx context.Context, lookup_func SubjectHeadingLookupFunc) (libraryofcongress.Lookup, error) {
 
-	fn := func() {
-		lookup_func(ctx)
+	opts := &SubjectHeadingLookupOptions{
+		Normalize: libraryofcongress.NormalizeSubdivisions,
+	}
+
+	return NewSubjectHeadingLookupWithLookupFuncAndOptions(ctx, lookup_func, opts)
+}
+
+// NewSubjectHeadingLookupWithLookupFuncAndOptions will return an `SubjectHeadingLookup` instance derived by data compiled using `lookup_func` and configured using 'opts'.
+func NewSubjectHeadingLookupWithLookupFuncAndOptions(ctx context.Context, lookup_func SubjectHeadingLookupFunc, opts *SubjectHeadingLookupOptions) (libraryofcongress.Lookup, error) {
+
+	normalize := opts.Normalize
+
+	if normalize == nil {
+		normalize = libraryofcongress.NoNormalize
+	}
+
+	l := newSubjectHeadingLookup(normalize, opts.Workers, opts.Wait)
+
+	if opts.Background {
+
+		// Loading continues after this function returns so it is not tied to 'ctx', which may be scoped to a
+		// request or have a timeout, and is stopped by calling the lookup's Close method instead.
+
+		load_ctx, cancel := context.WithCancel(context.Background())
+		l.cancel = cancel
+
+		go l.load(load_ctx, lookup_func)
+		return l, nil
 	}
 
-	lookup_init.Do(fn)
+	err := lookup_func(ctx, l)
 
-	if lookup_init_err != nil {
-		return nil, lookup_init_err
+	if err != nil {
+		return nil, err
 	}
 
-	l := SubjectHeadingLookup{}
-	return &l, nil
+	l.ready.Done(nil)
+	return l, nil
 }
 
-func (l *SubjectHeadingLookup) Find(ctx context.Context, code string) ([]interface{}, error) {
+// newSubjectHeadingLookup() returns a new `SubjectHeadingLookup` instance, with an empty lookup table, whose labels are normalized
+// using 'normalize' and whose data is loaded using 'workers' workers. If 'wait' is true then queries made while the
+// lookup is being loaded wait for loading to complete rather than returning a `libraryofcongress.NotReady` error.
+func newSubjectHeadingLookup(normalize libraryofcongress.NormalizeFunc, workers int, wait bool) *SubjectHeadingLookup {
+
+	l := &SubjectHeadingLookup{
+		current:   new(atomic.Value),
+		normalize: normalize,
+		workers:   workers,
+		mu:        new(sync.Mutex),
+		ready:     libraryofcongress.NewReadiness(SOURCE, wait),
+		reload_mu: new(sync.Mutex),
+	}
 
-	pointers, ok := lookup_table.Load(code)
+	l.current.Store(table.NewTable(normalize, true))
+	return l
+}
 
-	if !ok {
+// load() populates 'l' using 'lookup_func' in the background, signaling when it has completed. If loading fails
+// then any records that were loaded are discarded.
+func (l *SubjectHeadingLookup) load(ctx context.Context, lookup_func SubjectHeadingLookupFunc) {
 
-		// START OF hack to account for the difference in syntax between SFOM and LoC
+	err := lookup_func(ctx, l)
 
-		if strings.Contains(code, " -- ") {
-			code = strings.Replace(code, " -- ", "--", -1)
-			return l.Find(ctx, code)
-		}
+	if err != nil {
+		l.table().Reset()
+	}
 
-		// END OF hack to account for the difference in syntax between SFOM and LoC
+	l.ready.Done(err)
+}
 
-		return nil, NotFound{code}
+// Close() stops loading data in the background, if it is still in progress, in which case queries will return an error
+// wrapping `context.Canceled`. Lookups which were not loaded in the background, or have finished loading, are unaffected.
+func (l *SubjectHeadingLookup) Close() error {
+
+	if l.cancel != nil {
+		l.cancel()
 	}
 
-	subject_headers := make([]interface{}, 0)
+	return nil
+}
 
-	for _, p := range pointers.([]string) {
+// Ready() returns a channel which is closed when the lookup has finished loading (successfully or not).
+func (l *SubjectHeadingLookup) Ready() <-chan struct{} {
+	return l.ready.Ready()
+}
 
-		if !strings.HasPrefix(p, "pointer:") {
-			return nil, fmt.Errorf("Invalid pointer, '%s'", p)
-		}
+// Wait() blocks until the lookup has finished loading, returning the error (if any) encountered loading it, or until 'ctx' is cancelled.
+func (l *SubjectHeadingLookup) Wait(ctx context.Context) error {
+	return l.ready.Wait(ctx)
+}
 
-		row, ok := lookup_table.Load(p)
+// table() returns the lookup table currently in use.
+func (l *SubjectHeadingLookup) table() *table.Table {
+	return l.current.Load().(*table.Table)
+}
 
-		if !ok {
-			return nil, fmt.Errorf("Invalid pointer, '%s'", p)
-		}
+func (l *SubjectHeadingLookup) Find(ctx context.Context, code string) ([]interface{}, error) {
+
+	err := l.ready.Check(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	t := l.table()
+
+	rows := t.Find(code)
+
+	if len(rows) == 0 {
+		return nil, NotFound{code}
+	}
+
+	subject_headers := make([]interface{}, len(rows))
 
-		subject_headers = append(subject_headers, row.(*SubjectHeading))
+	for idx, r := range rows {
+		subject_headers[idx] = &SubjectHeading{Id: r.Id, Label: r.Label}
 	}
 
 	return subject_headers, nil
 }
 
-func (l *SubjectHeadingLookup) Append(ctx context.Context, data interface{}) error {
-	return appendData(ctx, lookup_table, data.(*SubjectHeading))
+// FindOne() searches for a given LoC identifier (or label) and returns exactly one record. If there are no matching
+// records a `NotFound` error is returned. If there is more than one matching record a `MultipleCandidates` error,
+// containing the list of matching records, is returned.
+func (l *SubjectHeadingLookup) FindOne(ctx context.Context, code string) (*SubjectHeading, error) {
+
+	results, err := l.Find(ctx, code)
+
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]*SubjectHeading, len(results))
+
+	for idx, r := range results {
+		candidates[idx] = r.(*SubjectHeading)
+	}
+
+	switch len(candidates) {
+	case 0:
+		return nil, NotFound{code}
+	case 1:
+		return candidates[0], nil
+	default:
+		return nil, MultipleCandidates{Code: code, Candidates: candidates}
+	}
 }
 
-func appendData(ctx context.Context, table *sync.Map, data *SubjectHeading) error {
+func (l *SubjectHeadingLookup) Append(ctx context.Context, data interface{}) error {
+
+	sh, ok := data.(*SubjectHeading)
 
-	idx := atomic.AddInt64(&lookup_idx, 1)
+	if !ok {
+		return fmt.Errorf("Unsupported record type %T", data)
+	}
 
-	pointer := fmt.Sprintf("pointer:%d", idx)
-	table.Store(pointer, data)
+	err := l.ready.Check(ctx)
 
-	possible_codes := []string{
-		data.Id,
-		data.Label,
+	if err != nil {
+		return err
 	}
 
-	for _, code := range possible_codes {
+	l.mu.Lock()
+	defer l.mu.Unlock()
 
-		if code == "" {
-			continue
-		}
+	return l.appendData(ctx, sh)
+}
 
-		pointers := make([]string, 0)
-		has_pointer := false
+// appendData() adds 'sh' to the lookup table.
+func (l *SubjectHeadingLookup) appendData(ctx context.Context, sh *SubjectHeading) error {
+	return l.table().Insert(sh.Id, sh.Label)
+}
 
-		others, ok := table.Load(code)
+// replaceData() removes all the records whose identifier is 'id' from the lookup table and adds 'sh' in their place. If
+// 'sh' can not be added then the lookup table is left unchanged.
+func (l *SubjectHeadingLookup) replaceData(ctx context.Context, id string, sh *SubjectHeading) error {
 
-		if ok {
-			pointers = others.([]string)
-		}
+	removed, err := l.table().Replace(id, sh.Id, sh.Label)
+
+	if err != nil {
+		return err
+	}
 
-		for _, dupe := range pointers {
+	if removed == 0 {
+		return NotFound{id}
+	}
 
-			if dupe == pointer {
-				has_pointer = true
-				break
-			}
-		}
+	return nil
+}
 
-		if has_pointer {
-			continue
-		}
+// removeData() removes all the records whose identifier is 'id' from the lookup table.
+func (l *SubjectHeadingLookup) removeData(ctx context.Context, id string) error {
 
-		pointers = append(pointers, pointer)
-		table.Store(code, pointers)
+	if l.table().Remove(id) == 0 {
+		return NotFound{id}
 	}
 
 	return nil
@@ -90,3 +90,100 @@ func TestLCSHLookup(t *testing.T) {
 		}
 	}
 }
+
+func TestLCSHLookupInstances(t *testing.T) {
+
+	ctx := context.Background()
+
+	rel_path := "../fixtures/lcsh.csv.bz2"
+	abs_path, err := filepath.Abs(rel_path)
+
+	if err != nil {
+		t.Fatalf("Failed to derive absolute path for %s, %v", rel_path, err)
+	}
+
+	file_uri := fmt.Sprintf("lcsh://file%s", abs_path)
+
+	lu_a, err := libraryofcongress.NewLookup(ctx, file_uri)
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup for '%s', %v", file_uri, err)
+	}
+
+	lu_b, err := libraryofcongress.NewLookup(ctx, file_uri)
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup for '%s', %v", file_uri, err)
+	}
+
+	sh := &SubjectHeading{
+		Id:    "sfom000001",
+		Label: "Airplanes -- Seats",
+	}
+
+	err = lu_a.Append(ctx, sh)
+
+	if err != nil {
+		t.Fatalf("Failed to append record, %v", err)
+	}
+
+	_, err = lu_a.Find(ctx, sh.Label)
+
+	if err != nil {
+		t.Fatalf("Failed to find appended record, %v", err)
+	}
+
+	_, err = lu_b.Find(ctx, sh.Label)
+
+	if !IsNotFound(err) {
+		t.Fatalf("Expected appended record to be absent from second lookup, %v", err)
+	}
+
+	_, err = lu_b.Find(ctx, "Airplanes")
+
+	if err != nil {
+		t.Fatalf("Failed to find 'Airplanes' in second lookup, %v", err)
+	}
+}
+
+func TestLCSHLookupNormalize(t *testing.T) {
+
+	ctx := context.Background()
+
+	rel_path := "../fixtures/lcsh.csv.bz2"
+	abs_path, err := filepath.Abs(rel_path)
+
+	if err != nil {
+		t.Fatalf("Failed to derive absolute path for %s, %v", rel_path, err)
+	}
+
+	file_uri := fmt.Sprintf("lcsh://file%s?normalize=casefold,whitespace,subdivisions", abs_path)
+
+	lu, err := libraryofcongress.NewLookup(ctx, file_uri)
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup for '%s', %v", file_uri, err)
+	}
+
+	tests := map[string]string{
+		"airplanes":                    "Airplanes",
+		"  BOEING   airplanes ":        "Boeing airplanes",
+		"aeronautics -- popular works": "Aeronautics--Popular works",
+		"Aeronautics — Popular works":  "Aeronautics--Popular works",
+	}
+
+	for code, label := range tests {
+
+		results, err := lu.Find(ctx, code)
+
+		if err != nil {
+			t.Fatalf("Unable to find '%s', %v", code, err)
+		}
+
+		sh := results[0].(*SubjectHeading)
+
+		if sh.Label != label {
+			t.Fatalf("Unexpected label for '%s', expected '%s' but got '%s'", code, label, sh.Label)
+		}
+	}
+}
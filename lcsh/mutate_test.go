@@ -0,0 +1,119 @@
+package lcsh
+
+import (
+	"context"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/table"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMutableLookup(t *testing.T) {
+
+	ctx := context.Background()
+
+	rel_path := "../fixtures/lcsh.csv.bz2"
+	abs_path, err := filepath.Abs(rel_path)
+
+	if err != nil {
+		t.Fatalf("Failed to derive absolute path for %s, %v", rel_path, err)
+	}
+
+	l, err := libraryofcongress.NewLookup(ctx, fmt.Sprintf("lcsh://file%s", abs_path))
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	m, ok := l.(libraryofcongress.MutableLookup)
+
+	if !ok {
+		t.Fatalf("Lookup does not implement MutableLookup")
+	}
+
+	// Update (rename)
+
+	err = m.Update(ctx, &SubjectHeading{Id: "sh85002782", Label: "Aeroplanes"})
+
+	if err != nil {
+		t.Fatalf("Failed to update sh85002782, %v", err)
+	}
+
+	_, err = l.Find(ctx, "Airplanes")
+
+	if !IsNotFound(err) {
+		t.Fatalf("Expected old label to be not found, got %v", err)
+	}
+
+	rsp, err := l.Find(ctx, "Aeroplanes")
+
+	if err != nil {
+		t.Fatalf("Failed to find new label, %v", err)
+	}
+
+	if len(rsp) != 1 || rsp[0].(*SubjectHeading).Id != "sh85002782" {
+		t.Fatalf("Unexpected results for new label, %v", rsp)
+	}
+
+	rsp, err = l.Find(ctx, "sh85002782")
+
+	if err != nil || len(rsp) != 1 {
+		t.Fatalf("Unexpected results for sh85002782, %v %v", rsp, err)
+	}
+
+	// Replace (deprecate)
+
+	err = m.Replace(ctx, "sh85002782", &SubjectHeading{Id: "sh00000001x", Label: "Aeroplanes"})
+
+	if err != nil {
+		t.Fatalf("Failed to replace sh85002782, %v", err)
+	}
+
+	_, err = l.Find(ctx, "sh85002782")
+
+	if !IsNotFound(err) {
+		t.Fatalf("Expected replaced identifier to be not found, got %v", err)
+	}
+
+	rsp, err = l.Find(ctx, "Aeroplanes")
+
+	if err != nil || len(rsp) != 1 || rsp[0].(*SubjectHeading).Id != "sh00000001x" {
+		t.Fatalf("Unexpected results for replacement, %v %v", rsp, err)
+	}
+
+	// Remove
+
+	err = m.Remove(ctx, "sh00000001x")
+
+	if err != nil {
+		t.Fatalf("Failed to remove sh00000001x, %v", err)
+	}
+
+	_, err = l.Find(ctx, "Aeroplanes")
+
+	if !IsNotFound(err) {
+		t.Fatalf("Expected removed record to be not found, got %v", err)
+	}
+
+	err = m.Remove(ctx, "sh00000001x")
+
+	if !IsNotFound(err) {
+		t.Fatalf("Expected removing a missing record to fail with NotFound, got %v", err)
+	}
+
+	// Failed updates leave the original record in place
+
+	err = m.Update(ctx, &SubjectHeading{Id: "sh2010007517", Label: strings.Repeat("x", table.CHUNK_SIZE+1)})
+
+	if err == nil {
+		t.Fatalf("Expected updating a record with an oversized label to fail")
+	}
+
+	_, err = l.Find(ctx, "sh2010007517")
+
+	if err != nil {
+		t.Fatalf("Expected failed update to leave sh2010007517 in place, %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package lcsh
+
+import (
+	"context"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/cache"
+	"net/url"
+)
+
+// Reload() loads the data for 'uri', which may be any URI accepted by `OpenData`, in to a new lookup table and then
+// atomically replaces the lookup's current table with it. Queries made while the new table is being loaded, including
+// those which are still in progress when the tables are swapped, use the current table. If the 'cache' query parameter
+// is present in 'uri' then records are cached in (and loaded from) that directory. The lookup's normalizer and number of
+// workers are not changed. Any changes (appends, removals, updates) made to the lookup since it was last loaded are
+// discarded. If the lookup is being loaded in the background Reload() waits for that to complete first. If that load
+// failed then a successful reload recovers the lookup. If the new data can not be loaded then an error is returned and
+// the current table remains in use.
+func (l *SubjectHeadingLookup) Reload(ctx context.Context, uri string) error {
+
+	l.reload_mu.Lock()
+	defer l.reload_mu.Unlock()
+
+	// A failed load can be recovered by reloading the lookup so only give up if 'ctx' has been cancelled
+
+	err := l.ready.Wait(ctx)
+
+	if err != nil && ctx.Err() != nil {
+		return err
+	}
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	r, err := OpenData(ctx, uri)
+
+	if err != nil {
+		return fmt.Errorf("Failed to open data for '%s', %w", uri, err)
+	}
+
+	staging := newSubjectHeadingLookup(l.normalize, l.workers, false)
+
+	lookup_func := newLookupFunc(ctx, r, u.Query().Get(cache.CACHE_PARAMETER))
+
+	err = lookup_func(ctx, staging)
+
+	if err != nil {
+		return fmt.Errorf("Failed to load data for '%s', %w", uri, err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.current.Store(staging.table())
+	l.ready.Succeed()
+
+	return nil
+}
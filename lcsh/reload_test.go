@@ -0,0 +1,132 @@
+package lcsh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"path/filepath"
+	"testing"
+)
+
+func TestSubjectHeadingLookupReload(t *testing.T) {
+
+	ctx := context.Background()
+
+	rel_path := "../fixtures/lcsh.csv.bz2"
+	abs_path, err := filepath.Abs(rel_path)
+
+	if err != nil {
+		t.Fatalf("Failed to derive absolute path for %s, %v", rel_path, err)
+	}
+
+	uri := fmt.Sprintf("lcsh://file%s", abs_path)
+
+	l, err := NewSubjectHeadingLookup(ctx, uri)
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	err = l.Append(ctx, &SubjectHeading{Id: "sfom0001", Label: "Airports"})
+
+	if err != nil {
+		t.Fatalf("Failed to append record, %v", err)
+	}
+
+	// Simulate a query which is still in progress when the lookup is reloaded
+
+	sh_l := l.(*SubjectHeadingLookup)
+	previous := sh_l.table()
+
+	err = libraryofcongress.Reload(ctx, l, uri)
+
+	if err != nil {
+		t.Fatalf("Failed to reload lookup, %v", err)
+	}
+
+	if len(previous.Find("Airports")) != 1 {
+		t.Fatalf("Expected previous table to be unchanged")
+	}
+
+	// Changes made since the lookup was loaded are discarded
+
+	_, err = l.Find(ctx, "Airports")
+
+	if !libraryofcongress.IsNotFound(err) {
+		t.Fatalf("Expected Airports to not be found after reload, %v", err)
+	}
+
+	_, err = l.Find(ctx, "Cooking")
+
+	if err != nil {
+		t.Fatalf("Failed to find Cooking after reload, %v", err)
+	}
+
+	// Failed reloads leave the current table in place
+
+	err = sh_l.Reload(ctx, fmt.Sprintf("lcsh://file%s", filepath.Join(t.TempDir(), "missing.csv.bz2")))
+
+	if err == nil {
+		t.Fatalf("Expected an error reloading missing data")
+	}
+
+	_, err = l.Find(ctx, "Cooking")
+
+	if err != nil {
+		t.Fatalf("Failed to find Cooking after failed reload, %v", err)
+	}
+}
+
+func TestSubjectHeadingLookupReloadAfterFailedLoad(t *testing.T) {
+
+	ctx := context.Background()
+
+	rel_path := "../fixtures/lcsh.csv.bz2"
+	abs_path, err := filepath.Abs(rel_path)
+
+	if err != nil {
+		t.Fatalf("Failed to derive absolute path for %s, %v", rel_path, err)
+	}
+
+	load_err := fmt.Errorf("Failed to read data")
+
+	lookup_func := func(ctx context.Context, l *SubjectHeadingLookup) error {
+		return load_err
+	}
+
+	opts := &SubjectHeadingLookupOptions{
+		Normalize:  libraryofcongress.NormalizeSubdivisions,
+		Background: true,
+	}
+
+	l, err := NewSubjectHeadingLookupWithLookupFuncAndOptions(ctx, lookup_func, opts)
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	err = libraryofcongress.Wait(ctx, l)
+
+	if !errors.Is(err, load_err) {
+		t.Fatalf("Expected load error waiting for lookup, got %v", err)
+	}
+
+	err = libraryofcongress.Reload(ctx, l, fmt.Sprintf("lcsh://file%s", abs_path))
+
+	if err != nil {
+		t.Fatalf("Failed to reload lookup after failed load, %v", err)
+	}
+
+	err = libraryofcongress.Wait(ctx, l)
+
+	if err != nil {
+		t.Fatalf("Expected lookup to be ready after reload, %v", err)
+	}
+
+	_, err = l.Find(ctx, "Cooking")
+
+	if err != nil {
+		t.Fatalf("Failed to find Cooking after reload, %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package multi
+
+import (
+	"context"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+)
+
+// Iterate() invokes 'cb' for each unique record in each of the child lookups, in the order they were defined. As with
+// `Find`, records with the same source and identifier as a record in a previous child lookup are skipped; rather than
+// keeping track of every record returned so far this is determined by querying the previous child lookups, which keeps
+// memory usage constant for large lookups. It returns an error if any of the child lookups do not implement the
+// `libraryofcongress.IterableLookup` interface.
+func (l *MultiLookup) Iterate(ctx context.Context, cb libraryofcongress.IterateFunc) error {
+
+	for _, c := range l.children {
+
+		_, ok := c.Lookup.(libraryofcongress.IterableLookup)
+
+		if !ok {
+			return fmt.Errorf("Lookup for '%s' (%T) does not implement IterableLookup", c.URI, c.Lookup)
+		}
+	}
+
+	for idx, c := range l.children {
+
+		previous := l.children[:idx]
+
+		child_cb := func(ctx context.Context, r libraryofcongress.Record) error {
+
+			for _, p := range previous {
+
+				exists, err := hasRecord(ctx, p, r.GetId(), r.GetSource())
+
+				if err != nil {
+					return err
+				}
+
+				if exists {
+					return nil
+				}
+			}
+
+			return cb(ctx, r)
+		}
+
+		err := libraryofcongress.Iterate(ctx, c.Lookup, child_cb)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// hasRecord() returns a boolean value indicating whether the child lookup 'c' has a record whose identifier is 'id'
+// and, if 'source' is not empty, whose source is 'source'.
+func hasRecord(ctx context.Context, c *Child, id string, source string) (bool, error) {
+
+	results, err := c.Lookup.Find(ctx, id)
+
+	if err != nil {
+
+		if libraryofcongress.IsNotFound(err) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("Failed to find '%s' in '%s', %w", id, c.URI, err)
+	}
+
+	for _, r := range results {
+
+		rec, ok := r.(libraryofcongress.Record)
+
+		if !ok || rec.GetId() != id {
+			continue
+		}
+
+		if source == "" || rec.GetSource() == source {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,53 @@
+package multi
+
+import (
+	"context"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcnaf"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcsh"
+	"testing"
+)
+
+func TestIterate(t *testing.T) {
+
+	ctx := context.Background()
+
+	lcsh_uri := fixtureURI(t, lcsh.SOURCE)
+	other_uri := lcsh_uri + "?normalize=casefold"
+	lcnaf_uri := fixtureURI(t, lcnaf.SOURCE)
+
+	l, err := libraryofcongress.NewLookup(ctx, multiURI("", lcsh_uri, lcnaf_uri, other_uri))
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	seen := make(map[string]int)
+
+	cb := func(ctx context.Context, r libraryofcongress.Record) error {
+		seen[r.GetSource()+":"+r.GetId()] += 1
+		return nil
+	}
+
+	err = libraryofcongress.Iterate(ctx, l, cb)
+
+	if err != nil {
+		t.Fatalf("Failed to iterate records, %v", err)
+	}
+
+	// Cooking (sh2010007517) is listed twice in the LCSH fixture data but the records in the
+	// third lookup, which are the same as the first, should be skipped
+
+	if seen["lcsh:sh85002782"] != 1 || seen["lcsh:sh2010007517"] != 2 || seen["lcnaf:n94099999"] != 1 {
+		t.Fatalf("Unexpected records, %v", seen)
+	}
+
+	cancel_ctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	err = libraryofcongress.Iterate(cancel_ctx, l, cb)
+
+	if err != context.Canceled {
+		t.Fatalf("Expected iteration to be cancelled, got %v", err)
+	}
+}
@@ -0,0 +1,440 @@
+// Package multi provides a `libraryofcongress.Lookup` implementation which queries several other lookups together.
+package multi
+
+import (
+	"context"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"io"
+	"net/url"
+	"sync"
+)
+
+// POLICY_ALL is the default policy which returns the (de-duplicated) results from all the child lookups.
+const POLICY_ALL string = "all"
+
+// POLICY_FIRST is a policy which returns the results from the first child lookup, in the order they were defined, with
+// any matching records.
+const POLICY_FIRST string = "first"
+
+// URI_PARAMETER is the name of the (repeatable) query parameter used by `multi://` URIs to define child lookup URIs.
+const URI_PARAMETER string = "uri"
+
+// POLICY_PARAMETER is the name of the query parameter used by `multi://` URIs to define the policy for merging results.
+const POLICY_PARAMETER string = "policy"
+
+// type MultiLookup is a struct which implements the `libraryofcongress.Lookup` interface by querying several child
+// lookups concurrently and merging their results.
+type MultiLookup struct {
+	libraryofcongress.Lookup
+	children []*Child
+	policy   string
+	ready    chan struct{}
+}
+
+// type Child is a struct containing a child lookup, and the URI used to create it, for a `MultiLookup` instance.
+type Child struct {
+	// URI is the URI used to create the child lookup. It is used to tag the records returned by the lookup.
+	URI string
+	// Lookup is the child lookup.
+	Lookup libraryofcongress.Lookup
+}
+
+// type MultiLookupOptions defines configuration options for `MultiLookup` instances.
+type MultiLookupOptions struct {
+	// Policy is the policy used to merge the results from the child lookups. Valid options are `POLICY_ALL` and
+	// `POLICY_FIRST`. Default is `POLICY_ALL`.
+	Policy string
+}
+
+// type response is a struct containing the results (or error) returned by a child lookup.
+type response struct {
+	// idx is the index of the child lookup in a `MultiLookup` instance's list of children.
+	idx     int
+	results []interface{}
+	err     error
+}
+
+func init() {
+	ctx := context.Background()
+	libraryofcongress.RegisterLookup(ctx, "multi", NewMultiLookup)
+}
+
+// NewMultiLookup() returns a new `MultiLookup` instance derived from 'uri' which is expected to take the form of:
+//
+//	multi://?uri={CHILD_URI}&uri={CHILD_URI}&policy={POLICY}
+//
+// Where {CHILD_URI} is a URL-encoded `libraryofcongress.Lookup` URI and {POLICY} is an optional policy for merging
+// results. The child lookups are created concurrently. If any of them fail to be created then the ones that were
+// created are closed (see `Close`) before an error is returned.
+func NewMultiLookup(ctx context.Context, uri string) (libraryofcongress.Lookup, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	child_uris := q[URI_PARAMETER]
+
+	if len(child_uris) == 0 {
+		return nil, fmt.Errorf("Missing ?%s= parameter", URI_PARAMETER)
+	}
+
+	opts := &MultiLookupOptions{
+		Policy: q.Get(POLICY_PARAMETER),
+	}
+
+	// Validate the options before creating (and loading) any child lookups
+
+	_, err = derivePolicy(opts.Policy)
+
+	if err != nil {
+		return nil, err
+	}
+
+	children := make([]*Child, len(child_uris))
+	errs := make([]error, len(child_uris))
+
+	wg := new(sync.WaitGroup)
+
+	for idx, child_uri := range child_uris {
+
+		wg.Add(1)
+
+		go func(idx int, child_uri string) {
+
+			defer wg.Done()
+
+			l, err := libraryofcongress.NewLookup(ctx, child_uri)
+
+			if err != nil {
+				errs[idx] = fmt.Errorf("Failed to create lookup for '%s', %w", child_uri, err)
+				return
+			}
+
+			children[idx] = &Child{
+				URI:    child_uri,
+				Lookup: l,
+			}
+
+		}(idx, child_uri)
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+
+		if err != nil {
+			closeChildren(children)
+			return nil, err
+		}
+	}
+
+	l, err := NewMultiLookupWithChildren(ctx, children, opts)
+
+	if err != nil {
+		closeChildren(children)
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// NewMultiLookupWithChildren() returns a new `MultiLookup` instance for 'children' configured using 'opts'. The order
+// of 'children' determines the order in which results are merged and, when using `POLICY_FIRST`, which results are returned.
+func NewMultiLookupWithChildren(ctx context.Context, children []*Child, opts *MultiLookupOptions) (libraryofcongress.Lookup, error) {
+
+	if len(children) == 0 {
+		return nil, fmt.Errorf("No child lookups defined")
+	}
+
+	policy, err := derivePolicy(opts.Policy)
+
+	if err != nil {
+		return nil, err
+	}
+
+	l := &MultiLookup{
+		children: children,
+		policy:   policy,
+		ready:    make(chan struct{}),
+	}
+
+	go l.waitForChildren()
+
+	return l, nil
+}
+
+// derivePolicy() returns the policy for 'policy', which may be empty, or an error if it is not a valid policy.
+func derivePolicy(policy string) (string, error) {
+
+	switch policy {
+	case "":
+		return POLICY_ALL, nil
+	case POLICY_ALL, POLICY_FIRST:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("Invalid policy '%s'", policy)
+	}
+}
+
+// Find() queries each of the child lookups for 'code' concurrently and returns their merged results. The records returned
+// by the child lookups are returned unchanged so, for example, LCSH records are still `*lcsh.SubjectHeading` instances
+// whose source is available using the `libraryofcongress.Record` interface's `GetSource` method. Use `FindResults` to
+// determine which child lookup returned a record. See `FindResults` for details about how results are merged.
+func (l *MultiLookup) Find(ctx context.Context, code string) ([]interface{}, error) {
+
+	results, err := l.FindResults(ctx, code)
+
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]interface{}, len(results))
+
+	for idx, r := range results {
+		records[idx] = r.Record
+	}
+
+	return records, nil
+}
+
+// FindResults() queries each of the child lookups for 'code' concurrently and returns their merged results as `Result`
+// instances tagged with the URI of the child lookup that returned them. Records are de-duplicated using their source
+// and identifier; when the same record is returned by more than one child lookup the first child, in the order they
+// were defined, wins. If none of the child lookups have any matching records a `libraryofcongress.NotFound` error is
+// returned. If any other error is returned by a child lookup whose results are needed (which is all of them when using
+// `POLICY_ALL`) then an error wrapping it is returned.
+func (l *MultiLookup) FindResults(ctx context.Context, code string) ([]*Result, error) {
+
+	// Child lookups whose results are no longer needed are cancelled
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Buffered so that goroutines whose responses are never read don't leak
+
+	responses_ch := make(chan *response, len(l.children))
+
+	for idx, c := range l.children {
+
+		go func(idx int, c *Child) {
+
+			results, err := c.Lookup.Find(ctx, code)
+
+			responses_ch <- &response{
+				idx:     idx,
+				results: results,
+				err:     err,
+			}
+
+		}(idx, c)
+	}
+
+	responses := make([]*response, len(l.children))
+
+	// next is the index of the first child lookup whose response has not been considered by POLICY_FIRST
+
+	next := 0
+
+	for i := 0; i < len(l.children); i++ {
+
+		var rsp *response
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case rsp = <-responses_ch:
+			// pass
+		}
+
+		if rsp.err != nil && !libraryofcongress.IsNotFound(rsp.err) {
+			rsp.err = fmt.Errorf("Failed to find '%s' in '%s', %w", code, l.children[rsp.idx].URI, rsp.err)
+		}
+
+		responses[rsp.idx] = rsp
+
+		if l.policy != POLICY_FIRST {
+			continue
+		}
+
+		for next < len(responses) && responses[next] != nil {
+
+			rsp := responses[next]
+
+			if rsp.err != nil && !libraryofcongress.IsNotFound(rsp.err) {
+				return nil, rsp.err
+			}
+
+			if rsp.err == nil && len(rsp.results) > 0 {
+				return l.merge(responses[next : next+1])
+			}
+
+			next += 1
+		}
+	}
+
+	if l.policy == POLICY_FIRST {
+		return nil, libraryofcongress.NotFound{Code: code}
+	}
+
+	for _, rsp := range responses {
+
+		if rsp.err != nil && !libraryofcongress.IsNotFound(rsp.err) {
+			return nil, rsp.err
+		}
+	}
+
+	results, err := l.merge(responses)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, libraryofcongress.NotFound{Code: code}
+	}
+
+	return results, nil
+}
+
+// Append() appends 'data' to the first child lookup, in the order they were defined, which accepts it. Since lookups
+// reject records they don't support this means that, for example, `lcsh.SubjectHeading` records are appended to the
+// first `lcsh://` child lookup. If 'data' is a `Result` instance (returned by `FindResults`) then its record is appended.
+func (l *MultiLookup) Append(ctx context.Context, data interface{}) error {
+
+	r, ok := data.(*Result)
+
+	if ok {
+		data = r.Record
+	}
+
+	errs := make([]error, 0)
+
+	for _, c := range l.children {
+
+		err := c.Lookup.Append(ctx, data)
+
+		if err == nil {
+			return nil
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", c.URI, err))
+	}
+
+	return fmt.Errorf("None of the child lookups accepted record (%T), %v", data, errs)
+}
+
+// Children() returns the child lookups for 'l'.
+func (l *MultiLookup) Children() []*Child {
+	return l.children
+}
+
+// Close() closes each of the child lookups which implement the `io.Closer` interface, returning the first error
+// encountered closing them.
+func (l *MultiLookup) Close() error {
+	return closeChildren(l.children)
+}
+
+// Ready() returns a channel which is closed when all the child lookups have finished loading.
+func (l *MultiLookup) Ready() <-chan struct{} {
+	return l.ready
+}
+
+// Wait() blocks until all the child lookups have finished loading, returning the first error encountered loading them,
+// or until 'ctx' is cancelled.
+func (l *MultiLookup) Wait(ctx context.Context) error {
+
+	for _, c := range l.children {
+
+		err := libraryofcongress.Wait(ctx, c.Lookup)
+
+		if err != nil {
+			return fmt.Errorf("Failed to wait for '%s', %w", c.URI, err)
+		}
+	}
+
+	return nil
+}
+
+// waitForChildren() closes the channel returned by `Ready` once all the child lookups have finished loading.
+func (l *MultiLookup) waitForChildren() {
+
+	for _, c := range l.children {
+
+		r, ok := c.Lookup.(libraryofcongress.ReadyLookup)
+
+		if ok {
+			<-r.Ready()
+		}
+	}
+
+	close(l.ready)
+}
+
+// closeChildren() closes each of the (non-nil) child lookups in 'children' which implement the `io.Closer` interface,
+// returning the first error encountered closing them.
+func closeChildren(children []*Child) error {
+
+	var first_err error
+
+	for _, c := range children {
+
+		if c == nil {
+			continue
+		}
+
+		cl, ok := c.Lookup.(io.Closer)
+
+		if !ok {
+			continue
+		}
+
+		err := cl.Close()
+
+		if err != nil && first_err == nil {
+			first_err = fmt.Errorf("Failed to close '%s', %w", c.URI, err)
+		}
+	}
+
+	return first_err
+}
+
+// merge() returns the de-duplicated records in 'responses', wrapped in `Result` instances, in the order they were returned.
+func (l *MultiLookup) merge(responses []*response) ([]*Result, error) {
+
+	results := make([]*Result, 0)
+	seen := make(map[string]bool)
+
+	for _, rsp := range responses {
+
+		if rsp.err != nil {
+			continue
+		}
+
+		child_uri := l.children[rsp.idx].URI
+
+		for _, r := range rsp.results {
+
+			rec, ok := r.(libraryofcongress.Record)
+
+			if !ok {
+				return nil, fmt.Errorf("Lookup for '%s' returned unsupported record type %T", child_uri, r)
+			}
+
+			key := fmt.Sprintf("%s:%s", rec.GetSource(), rec.GetId())
+
+			if seen[key] {
+				continue
+			}
+
+			seen[key] = true
+			results = append(results, NewResult(rec, child_uri))
+		}
+	}
+
+	return results, nil
+}
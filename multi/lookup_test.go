@@ -0,0 +1,391 @@
+package multi
+
+import (
+	"context"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/batch"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcnaf"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcsh"
+	"net/url"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+// closed is the number of `closerLookup` instances which have been closed.
+var closed int32
+
+// type closerLookup is a struct implementing the `libraryofcongress.Lookup` and `io.Closer` interfaces which
+// counts how many times it has been closed.
+type closerLookup struct {
+	libraryofcongress.Lookup
+}
+
+func (l *closerLookup) Close() error {
+	atomic.AddInt32(&closed, 1)
+	return nil
+}
+
+func init() {
+	ctx := context.Background()
+	libraryofcongress.RegisterLookup(ctx, "closer", func(ctx context.Context, uri string) (libraryofcongress.Lookup, error) {
+		return &closerLookup{}, nil
+	})
+}
+
+// fixtureURI() returns a lookup URI for the fixture data for 'source'.
+func fixtureURI(t *testing.T, source string) string {
+
+	rel_path := fmt.Sprintf("../fixtures/%s.csv.bz2", source)
+	abs_path, err := filepath.Abs(rel_path)
+
+	if err != nil {
+		t.Fatalf("Failed to derive absolute path for %s, %v", rel_path, err)
+	}
+
+	return fmt.Sprintf("%s://file%s", source, abs_path)
+}
+
+// multiURI() returns a `multi://` URI for 'child_uris' using 'policy'.
+func multiURI(policy string, child_uris ...string) string {
+
+	q := url.Values{}
+
+	for _, child_uri := range child_uris {
+		q.Add(URI_PARAMETER, child_uri)
+	}
+
+	if policy != "" {
+		q.Set(POLICY_PARAMETER, policy)
+	}
+
+	return fmt.Sprintf("multi://?%s", q.Encode())
+}
+
+func TestMultiLookup(t *testing.T) {
+
+	ctx := context.Background()
+
+	lcsh_uri := fixtureURI(t, lcsh.SOURCE)
+	lcnaf_uri := fixtureURI(t, lcnaf.SOURCE)
+
+	l, err := libraryofcongress.NewLookup(ctx, multiURI("", lcsh_uri, lcnaf_uri))
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	tests := map[string][2]string{
+		"Cooking":         {lcsh.SOURCE, lcsh_uri},
+		"Birkan, Kaarin":  {lcnaf.SOURCE, lcnaf_uri},
+		"Devorin, Lonyah": {lcnaf.SOURCE, lcnaf_uri},
+	}
+
+	for code, expected := range tests {
+
+		results, err := l.(*MultiLookup).FindResults(ctx, code)
+
+		if err != nil {
+			t.Fatalf("Failed to find '%s', %v", code, err)
+		}
+
+		for _, r := range results {
+
+			if r.Record.GetSource() != expected[0] {
+				t.Fatalf("Unexpected source for '%s', %s", code, r.Record.GetSource())
+			}
+
+			if r.Lookup != expected[1] {
+				t.Fatalf("Unexpected lookup for '%s', %s", code, r.Lookup)
+			}
+
+			if r.Record.GetLabel() != code {
+				t.Fatalf("Unexpected label for '%s', %s", code, r.Record.GetLabel())
+			}
+		}
+
+		// Find returns the records from the child lookups unchanged
+
+		records, err := l.Find(ctx, code)
+
+		if err != nil {
+			t.Fatalf("Failed to find '%s', %v", code, err)
+		}
+
+		if len(records) != len(results) {
+			t.Fatalf("Expected %d records for '%s', got %d", len(results), code, len(records))
+		}
+
+		for _, r := range records {
+
+			switch r.(type) {
+			case *lcsh.SubjectHeading, *lcnaf.NamedAuthority:
+				// pass
+			default:
+				t.Fatalf("Unexpected record type for '%s', %T", code, r)
+			}
+		}
+	}
+
+	typed := libraryofcongress.NewTypedLookupWithLookup[*lcsh.SubjectHeading](l)
+
+	sh, err := typed.Find(ctx, "Cooking")
+
+	if err != nil {
+		t.Fatalf("Failed to find Cooking using typed lookup, %v", err)
+	}
+
+	if len(sh) != 2 || sh[0].Id != "sh2010007517" {
+		t.Fatalf("Unexpected typed results for Cooking, %v", sh)
+	}
+
+	rsp := batch.ResolveOne(ctx, l, "Tampa Joe")
+
+	if len(rsp.URIs) != 1 || rsp.URIs[0] != "http://id.loc.gov/authorities/names/n94099999" {
+		t.Fatalf("Unexpected URIs for Tampa Joe, %v", rsp.URIs)
+	}
+
+	_, err = l.Find(ctx, "Aeroplanes")
+
+	if !libraryofcongress.IsNotFound(err) {
+		t.Fatalf("Expected not found error, got %v", err)
+	}
+}
+
+func TestMultiLookupDuplicates(t *testing.T) {
+
+	ctx := context.Background()
+
+	lcsh_uri := fixtureURI(t, lcsh.SOURCE)
+	other_uri := lcsh_uri + "?normalize=casefold"
+
+	single, err := libraryofcongress.NewLookup(ctx, lcsh_uri)
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	l, err := libraryofcongress.NewLookup(ctx, multiURI(POLICY_ALL, lcsh_uri, other_uri))
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	expected, err := single.Find(ctx, "Cooking")
+
+	if err != nil {
+		t.Fatalf("Failed to find Cooking, %v", err)
+	}
+
+	results, err := l.(*MultiLookup).FindResults(ctx, "Cooking")
+
+	if err != nil {
+		t.Fatalf("Failed to find Cooking, %v", err)
+	}
+
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got %d", len(expected), len(results))
+	}
+
+	for _, r := range results {
+
+		if r.Lookup != lcsh_uri {
+			t.Fatalf("Expected result from first lookup, got %s", r.Lookup)
+		}
+	}
+
+	// Only the second lookup matches lower-case labels
+
+	results, err = l.(*MultiLookup).FindResults(ctx, "cooking")
+
+	if err != nil {
+		t.Fatalf("Failed to find cooking, %v", err)
+	}
+
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got %d", len(expected), len(results))
+	}
+
+	for _, r := range results {
+
+		if r.Lookup != other_uri {
+			t.Fatalf("Expected result from second lookup, got %s", r.Lookup)
+		}
+	}
+}
+
+func TestMultiLookupFirst(t *testing.T) {
+
+	ctx := context.Background()
+
+	lcsh_uri := fixtureURI(t, lcsh.SOURCE)
+	other_uri := lcsh_uri + "?normalize=casefold"
+	lcnaf_uri := fixtureURI(t, lcnaf.SOURCE)
+
+	l, err := libraryofcongress.NewLookup(ctx, multiURI(POLICY_FIRST, lcnaf_uri, other_uri, lcsh_uri))
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	results, err := l.(*MultiLookup).FindResults(ctx, "Cooking")
+
+	if err != nil {
+		t.Fatalf("Failed to find Cooking, %v", err)
+	}
+
+	if len(results) == 0 {
+		t.Fatalf("Expected results for Cooking")
+	}
+
+	for _, r := range results {
+
+		if r.Lookup != other_uri {
+			t.Fatalf("Expected result from second lookup, got %s", r.Lookup)
+		}
+	}
+
+	results, err = l.(*MultiLookup).FindResults(ctx, "Tampa Joe")
+
+	if err != nil {
+		t.Fatalf("Failed to find Tampa Joe, %v", err)
+	}
+
+	if len(results) != 1 || results[0].Lookup != lcnaf_uri {
+		t.Fatalf("Unexpected results for Tampa Joe, %v", results)
+	}
+
+	_, err = l.Find(ctx, "Aeroplanes")
+
+	if !libraryofcongress.IsNotFound(err) {
+		t.Fatalf("Expected not found error, got %v", err)
+	}
+}
+
+func TestMultiLookupAppend(t *testing.T) {
+
+	ctx := context.Background()
+
+	lcsh_uri := fixtureURI(t, lcsh.SOURCE)
+	lcnaf_uri := fixtureURI(t, lcnaf.SOURCE)
+
+	l, err := libraryofcongress.NewLookup(ctx, multiURI("", lcsh_uri, lcnaf_uri))
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	na := &lcnaf.NamedAuthority{
+		Id:    "n00000000",
+		Label: "Lindbergh, Charles",
+	}
+
+	err = l.Append(ctx, na)
+
+	if err != nil {
+		t.Fatalf("Failed to append record, %v", err)
+	}
+
+	results, err := l.(*MultiLookup).FindResults(ctx, na.Label)
+
+	if err != nil {
+		t.Fatalf("Failed to find appended record, %v", err)
+	}
+
+	if len(results) != 1 || results[0].Lookup != lcnaf_uri {
+		t.Fatalf("Unexpected results for appended record, %v", results)
+	}
+
+	err = l.Append(ctx, "Lindbergh, Charles")
+
+	if err == nil {
+		t.Fatalf("Expected unsupported record to fail")
+	}
+}
+
+func TestMultiLookupWait(t *testing.T) {
+
+	ctx := context.Background()
+
+	lcsh_uri := fixtureURI(t, lcsh.SOURCE) + "?background=true"
+
+	l, err := libraryofcongress.NewLookup(ctx, multiURI("", lcsh_uri))
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	err = libraryofcongress.Wait(ctx, l)
+
+	if err != nil {
+		t.Fatalf("Failed to wait for lookup, %v", err)
+	}
+
+	<-l.(libraryofcongress.ReadyLookup).Ready()
+
+	_, err = l.Find(ctx, "Cooking")
+
+	if err != nil {
+		t.Fatalf("Failed to find Cooking, %v", err)
+	}
+}
+
+func TestMultiLookupInvalid(t *testing.T) {
+
+	ctx := context.Background()
+
+	lcsh_uri := fixtureURI(t, lcsh.SOURCE)
+
+	uris := []string{
+		"multi://",
+		multiURI("best", lcsh_uri),
+		multiURI("", lcsh_uri, "lcsh://file/does/not/exist.csv.bz2"),
+	}
+
+	for _, uri := range uris {
+
+		_, err := libraryofcongress.NewLookup(ctx, uri)
+
+		if err == nil {
+			t.Fatalf("Expected '%s' to fail", uri)
+		}
+	}
+}
+
+func TestMultiLookupClose(t *testing.T) {
+
+	ctx := context.Background()
+
+	atomic.StoreInt32(&closed, 0)
+
+	uri := multiURI("", "closer://", "closer://", "lcsh://file/does/not/exist.csv.bz2")
+
+	_, err := libraryofcongress.NewLookup(ctx, uri)
+
+	if err == nil {
+		t.Fatalf("Expected '%s' to fail", uri)
+	}
+
+	if atomic.LoadInt32(&closed) != 2 {
+		t.Fatalf("Expected 2 child lookups to be closed, got %d", atomic.LoadInt32(&closed))
+	}
+
+	atomic.StoreInt32(&closed, 0)
+
+	l, err := libraryofcongress.NewLookup(ctx, multiURI("", "closer://", fixtureURI(t, lcsh.SOURCE)))
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	err = l.(*MultiLookup).Close()
+
+	if err != nil {
+		t.Fatalf("Failed to close lookup, %v", err)
+	}
+
+	if atomic.LoadInt32(&closed) != 1 {
+		t.Fatalf("Expected 1 child lookup to be closed, got %d", atomic.LoadInt32(&closed))
+	}
+}
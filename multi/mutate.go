@@ -0,0 +1,124 @@
+package multi
+
+import (
+	"context"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+)
+
+// Remove() removes the records whose identifier is 'id' from all the child lookups which contain them. Child lookups
+// which do not implement the `libraryofcongress.MutableLookup` interface (for example the read-only index lookups) are
+// skipped. If none of the child lookups contain a matching record a `libraryofcongress.NotFound` error is returned.
+func (l *MultiLookup) Remove(ctx context.Context, id string) error {
+
+	removed := 0
+
+	for _, c := range l.children {
+
+		m, ok := c.Lookup.(libraryofcongress.MutableLookup)
+
+		if !ok {
+			continue
+		}
+
+		err := m.Remove(ctx, id)
+
+		if err != nil {
+
+			if libraryofcongress.IsNotFound(err) {
+				continue
+			}
+
+			return fmt.Errorf("Failed to remove '%s' from '%s', %w", id, c.URI, err)
+		}
+
+		removed += 1
+	}
+
+	if removed == 0 {
+		return libraryofcongress.NotFound{Code: id}
+	}
+
+	return nil
+}
+
+// Update() updates the records whose source and identifier match 'data' in all the child lookups which contain them.
+// Child lookups which do not implement the `libraryofcongress.MutableLookup` interface are skipped. If 'data' is a
+// `Result` instance then its record is used. If none of the child lookups contain a matching record a
+// `libraryofcongress.NotFound` error is returned.
+func (l *MultiLookup) Update(ctx context.Context, data interface{}) error {
+
+	r, ok := data.(*Result)
+
+	if ok {
+		data = r.Record
+	}
+
+	rec, ok := data.(libraryofcongress.Record)
+
+	if !ok {
+		return fmt.Errorf("Unsupported record type %T", data)
+	}
+
+	return l.mutate(ctx, rec.GetId(), rec.GetSource(), func(m libraryofcongress.MutableLookup) error {
+		return m.Update(ctx, data)
+	})
+}
+
+// Replace() removes the records whose identifier is 'id' and appends 'data' in their place in all the child lookups
+// which contain them. Since the record is replaced in the child lookups which contained the original records 'data'
+// must be a type those lookups support. Child lookups which do not implement the `libraryofcongress.MutableLookup`
+// interface are skipped. If 'data' is a `Result` instance then its record is used. If none of the child lookups contain
+// a record matching 'id' a `libraryofcongress.NotFound` error is returned.
+func (l *MultiLookup) Replace(ctx context.Context, id string, data interface{}) error {
+
+	r, ok := data.(*Result)
+
+	if ok {
+		data = r.Record
+	}
+
+	return l.mutate(ctx, id, "", func(m libraryofcongress.MutableLookup) error {
+		return m.Replace(ctx, id, data)
+	})
+}
+
+// mutate() invokes 'fn' for each child lookup implementing the `libraryofcongress.MutableLookup` interface which has a
+// record whose identifier is 'id' and, if 'source' is not empty, whose source is 'source'.
+func (l *MultiLookup) mutate(ctx context.Context, id string, source string, fn func(libraryofcongress.MutableLookup) error) error {
+
+	changed := 0
+
+	for _, c := range l.children {
+
+		m, ok := c.Lookup.(libraryofcongress.MutableLookup)
+
+		if !ok {
+			continue
+		}
+
+		exists, err := hasRecord(ctx, c, id, source)
+
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			continue
+		}
+
+		err = fn(m)
+
+		if err != nil {
+			return fmt.Errorf("Failed to change '%s' in '%s', %w", id, c.URI, err)
+		}
+
+		changed += 1
+	}
+
+	if changed == 0 {
+		return libraryofcongress.NotFound{Code: id}
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package multi
+
+import (
+	"context"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcnaf"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcsh"
+	"testing"
+)
+
+func TestMutate(t *testing.T) {
+
+	ctx := context.Background()
+
+	lcsh_uri := fixtureURI(t, lcsh.SOURCE)
+	lcnaf_uri := fixtureURI(t, lcnaf.SOURCE)
+
+	l, err := libraryofcongress.NewLookup(ctx, multiURI("", lcsh_uri, lcnaf_uri))
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	m, ok := l.(libraryofcongress.MutableLookup)
+
+	if !ok {
+		t.Fatalf("Lookup does not implement MutableLookup")
+	}
+
+	// Update
+
+	err = m.Update(ctx, &lcsh.SubjectHeading{Id: "sh85002782", Label: "Aeroplanes"})
+
+	if err != nil {
+		t.Fatalf("Failed to update record, %v", err)
+	}
+
+	r, err := libraryofcongress.FindOne(ctx, l, "Aeroplanes")
+
+	if err != nil || r.(*lcsh.SubjectHeading).Id != "sh85002782" {
+		t.Fatalf("Failed to find updated record, %v", err)
+	}
+
+	// Records are matched using their source as well as their identifier
+
+	err = m.Update(ctx, &lcnaf.NamedAuthority{Id: "sh85002782", Label: "Aeroplanes"})
+
+	if !libraryofcongress.IsNotFound(err) {
+		t.Fatalf("Expected not found error updating record with the wrong source, got %v", err)
+	}
+
+	// Replace
+
+	err = m.Replace(ctx, "n94099999", &lcnaf.NamedAuthority{Id: "n94099998", Label: "Joe, Tampa"})
+
+	if err != nil {
+		t.Fatalf("Failed to replace record, %v", err)
+	}
+
+	_, err = l.Find(ctx, "Tampa Joe")
+
+	if !libraryofcongress.IsNotFound(err) {
+		t.Fatalf("Expected replaced record to be removed, got %v", err)
+	}
+
+	_, err = libraryofcongress.FindOne(ctx, l, "Joe, Tampa")
+
+	if err != nil {
+		t.Fatalf("Failed to find replacement record, %v", err)
+	}
+
+	// Remove
+
+	err = m.Remove(ctx, "n94099998")
+
+	if err != nil {
+		t.Fatalf("Failed to remove record, %v", err)
+	}
+
+	_, err = l.Find(ctx, "n94099998")
+
+	if !libraryofcongress.IsNotFound(err) {
+		t.Fatalf("Expected removed record to be missing, got %v", err)
+	}
+
+	err = m.Remove(ctx, "n94099998")
+
+	if !libraryofcongress.IsNotFound(err) {
+		t.Fatalf("Expected not found error removing missing record, got %v", err)
+	}
+}
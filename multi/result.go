@@ -0,0 +1,27 @@
+package multi
+
+import (
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+)
+
+// type Result is a struct containing a record returned by one of the child lookups of a `MultiLookup` instance and the
+// URI of the lookup that returned it. Results are returned by the `MultiLookup.FindResults` method; the `Find` method
+// returns the records themselves.
+type Result struct {
+	// Record is the record, unchanged, returned by the child lookup. The LoC data source for the record is available
+	// using its `GetSource` method.
+	Record libraryofcongress.Record
+	// Lookup is the URI of the child lookup which returned the record.
+	Lookup string
+}
+
+// NewResult() returns a new `Result` instance for 'rec' returned by the child lookup for 'lookup_uri'.
+func NewResult(rec libraryofcongress.Record, lookup_uri string) *Result {
+
+	r := &Result{
+		Record: rec,
+		Lookup: lookup_uri,
+	}
+
+	return r
+}
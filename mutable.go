@@ -0,0 +1,18 @@
+package libraryofcongress
+
+import (
+	"context"
+)
+
+// type MutableLookup is an optional interface, implemented by all the `Lookup` implementations in this package (and
+// its sub-packages) except the read-only index lookups, for changing records after a lookup has been created. For example, to apply LoC change feeds
+// for deprecated and renamed headings without reloading all the data.
+type MutableLookup interface {
+	Lookup
+	// Remove() removes the record(s) with a given LoC identifier.
+	Remove(context.Context, string) error
+	// Update() replaces the record(s) whose identifier matches the identifier of a given record with that record.
+	Update(context.Context, interface{}) error
+	// Replace() removes the record(s) with a given LoC identifier and adds a new record in their place.
+	Replace(context.Context, string, interface{}) error
+}
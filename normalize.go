@@ -0,0 +1,177 @@
+package libraryofcongress
+
+import (
+	"fmt"
+	"golang.org/x/text/unicode/norm"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// NORMALIZE_PARAMETER is the name of the query parameter used by `Lookup` URIs to define the normalizers applied to labels.
+const NORMALIZE_PARAMETER string = "normalize"
+
+// DEFAULT_NORMALIZER is the default normalizer specification used by `Lookup` implementations. It accounts for the
+// difference in syntax between SFO Museum and LoC subdivisions ("Aeronautics -- Popular works" versus "Aeronautics--Popular works").
+const DEFAULT_NORMALIZER string = "subdivisions"
+
+// NO_NORMALIZER is a normalizer specification indicating that labels should not be normalized.
+const NO_NORMALIZER string = "none"
+
+// type NormalizeFunc is a function used to normalize labels (and the strings used to query them) before they are
+// indexed or looked up. Normalized values are only ever used as keys; the original labels are always preserved on records.
+type NormalizeFunc func(string) string
+
+var normalizers = map[string]NormalizeFunc{
+	"casefold":     CaseFold,
+	"nfc":          NFC,
+	"nfd":          NFD,
+	"diacritics":   StripDiacritics,
+	"whitespace":   CollapseWhitespace,
+	"period":       TrimTrailingPeriod,
+	"subdivisions": NormalizeSubdivisions,
+}
+
+var normalizers_mu = new(sync.RWMutex)
+
+var re_subdivision = regexp.MustCompile(`\s*(?:--|—)\s*`)
+
+// RegisterNormalizeFunc() associates 'name' with 'fn' in the internal list of available normalizers.
+func RegisterNormalizeFunc(name string, fn NormalizeFunc) error {
+
+	normalizers_mu.Lock()
+	defer normalizers_mu.Unlock()
+
+	_, exists := normalizers[name]
+
+	if exists || name == NO_NORMALIZER {
+		return fmt.Errorf("Normalizer '%s' is already registered", name)
+	}
+
+	normalizers[name] = fn
+	return nil
+}
+
+// Normalizers() returns the sorted list of registered normalizer names.
+func Normalizers() []string {
+
+	normalizers_mu.RLock()
+	defer normalizers_mu.RUnlock()
+
+	names := make([]string, 0)
+
+	for name, _ := range normalizers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// NewNormalizeFunc() returns a `NormalizeFunc` which applies the normalizers listed in 'spec', a comma-separated list
+// of normalizer names, in order. If 'spec' is empty then `DEFAULT_NORMALIZER` is used. If 'spec' is "none" then the
+// returned function will return strings unaltered.
+func NewNormalizeFunc(spec string) (NormalizeFunc, error) {
+
+	if spec == "" {
+		spec = DEFAULT_NORMALIZER
+	}
+
+	if spec == NO_NORMALIZER {
+		return NoNormalize, nil
+	}
+
+	normalizers_mu.RLock()
+	defer normalizers_mu.RUnlock()
+
+	funcs := make([]NormalizeFunc, 0)
+
+	for _, name := range strings.Split(spec, ",") {
+
+		name = strings.TrimSpace(name)
+
+		if name == "" {
+			continue
+		}
+
+		fn, ok := normalizers[name]
+
+		if !ok {
+			return nil, fmt.Errorf("Unknown normalizer '%s'", name)
+		}
+
+		funcs = append(funcs, fn)
+	}
+
+	return ChainNormalizeFuncs(funcs...), nil
+}
+
+// ChainNormalizeFuncs() returns a `NormalizeFunc` which applies each of 'funcs' in order.
+func ChainNormalizeFuncs(funcs ...NormalizeFunc) NormalizeFunc {
+
+	fn := func(s string) string {
+
+		for _, f := range funcs {
+			s = f(s)
+		}
+
+		return s
+	}
+
+	return fn
+}
+
+// NoNormalize() returns 's' unaltered.
+func NoNormalize(s string) string {
+	return s
+}
+
+// CaseFold() returns a lower-cased copy of 's'.
+func CaseFold(s string) string {
+	return strings.ToLower(s)
+}
+
+// NFC() returns the Unicode Normalization Form C (canonical composition) of 's'.
+func NFC(s string) string {
+	return norm.NFC.String(s)
+}
+
+// NFD() returns the Unicode Normalization Form D (canonical decomposition) of 's'.
+func NFD(s string) string {
+	return norm.NFD.String(s)
+}
+
+// StripDiacritics() returns a copy of 's' with all non-spacing marks (accents, umlauts, etc.) removed.
+func StripDiacritics(s string) string {
+
+	s = norm.NFD.String(s)
+
+	s = strings.Map(func(r rune) rune {
+
+		if unicode.Is(unicode.Mn, r) {
+			return -1
+		}
+
+		return r
+	}, s)
+
+	return norm.NFC.String(s)
+}
+
+// CollapseWhitespace() returns a copy of 's' with leading and trailing whitespace removed and all other runs of whitespace replaced by a single space.
+func CollapseWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// TrimTrailingPeriod() returns a copy of 's' with a trailing period removed.
+func TrimTrailingPeriod(s string) string {
+	s = strings.TrimRightFunc(s, unicode.IsSpace)
+	return strings.TrimSuffix(s, ".")
+}
+
+// NormalizeSubdivisions() returns a copy of 's' with subdivision separators (for example " -- " or "—") replaced by the LoC "--" syntax.
+func NormalizeSubdivisions(s string) string {
+	return re_subdivision.ReplaceAllString(s, "--")
+}
@@ -0,0 +1,55 @@
+package libraryofcongress
+
+import (
+	"testing"
+)
+
+func TestNormalizeFunc(t *testing.T) {
+
+	tests := map[string]map[string]string{
+		"": map[string]string{
+			"Aeronautics -- Popular works": "Aeronautics--Popular works",
+			"Aeronautics--Popular works":   "Aeronautics--Popular works",
+			"Aeronautics — Popular works":  "Aeronautics--Popular works",
+		},
+		"none": map[string]string{
+			"Aeronautics -- Popular works": "Aeronautics -- Popular works",
+		},
+		"casefold,whitespace,period": map[string]string{
+			"  Brockmann,   Lester C. ": "brockmann, lester c",
+		},
+		"diacritics": map[string]string{
+			"Veränderungen über den Priestermarsch": "Veranderungen uber den Priestermarsch",
+		},
+		"nfc": map[string]string{
+			"Veränderungen": "Veränderungen",
+		},
+		"nfd": map[string]string{
+			"Veränderungen": "Veränderungen",
+		},
+	}
+
+	for spec, candidates := range tests {
+
+		fn, err := NewNormalizeFunc(spec)
+
+		if err != nil {
+			t.Fatalf("Failed to create normalizer for '%s', %v", spec, err)
+		}
+
+		for input, expected := range candidates {
+
+			output := fn(input)
+
+			if output != expected {
+				t.Fatalf("Unexpected output for '%s' using '%s', expected '%s' but got '%s'", input, spec, expected, output)
+			}
+		}
+	}
+
+	_, err := NewNormalizeFunc("casefold,bogus")
+
+	if err == nil {
+		t.Fatalf("Expected unknown normalizer to trigger an error")
+	}
+}
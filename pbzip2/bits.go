@@ -0,0 +1,106 @@
+package pbzip2
+
+// type bitWriter is a struct used to assemble sequences of bits which are not (necessarily) aligned on byte boundaries.
+type bitWriter struct {
+	buf   []byte
+	nbits int64
+}
+
+// newBitWriter() returns a new `bitWriter` instance with enough capacity for 'size' bytes.
+func newBitWriter(size int) *bitWriter {
+
+	w := &bitWriter{
+		buf: make([]byte, 0, size),
+	}
+
+	return w
+}
+
+// writeBits() writes the 'n' lowest bits of 'v', most significant bit first.
+func (w *bitWriter) writeBits(v uint64, n int) {
+
+	for n > 0 {
+
+		used := int(w.nbits % 8)
+
+		if used == 0 {
+			w.buf = append(w.buf, 0)
+		}
+
+		free := 8 - used
+		take := n
+
+		if take > free {
+			take = free
+		}
+
+		chunk := byte((v >> uint(n-take)) & (1<<uint(take) - 1))
+		w.buf[len(w.buf)-1] |= chunk << uint(free-take)
+
+		w.nbits += int64(take)
+		n -= take
+	}
+}
+
+// copyBits() writes the bits in 'src' starting at bit offset 'from' and ending at (but not including) bit offset 'to'.
+func (w *bitWriter) copyBits(src []byte, from int64, to int64) {
+
+	// Fast path for when both the source and the destination are byte-aligned
+
+	if from%8 == 0 && w.nbits%8 == 0 {
+
+		whole := (to - from) / 8
+		w.buf = append(w.buf, src[from/8:from/8+whole]...)
+		w.nbits += whole * 8
+		from += whole * 8
+	}
+
+	for ; from+8 <= to; from += 8 {
+		w.writeBits(uint64(byteAt(src, from)), 8)
+	}
+
+	if from < to {
+		n := int(to - from)
+		w.writeBits(readBits(src, from, n), n)
+	}
+}
+
+// bytes() returns the bits written so far, padded with zeros to a byte boundary.
+func (w *bitWriter) bytes() []byte {
+	return w.buf
+}
+
+// byteAt() returns the 8 bits in 'src' starting at bit offset 'offset'. Bits beyond the end of 'src' are treated as zero.
+func byteAt(src []byte, offset int64) byte {
+
+	i := offset / 8
+	shift := uint(offset % 8)
+
+	if shift == 0 {
+		return src[i]
+	}
+
+	b := src[i] << shift
+
+	if i+1 < int64(len(src)) {
+		b |= src[i+1] >> (8 - shift)
+	}
+
+	return b
+}
+
+// readBits() returns the 'n' (<= 64) bits in 'src' starting at bit offset 'offset'.
+func readBits(src []byte, offset int64, n int) uint64 {
+
+	v := uint64(0)
+
+	for i := 0; i < n; i++ {
+
+		pos := offset + int64(i)
+		bit := (src[pos/8] >> (7 - uint(pos%8))) & 1
+
+		v = v<<1 | uint64(bit)
+	}
+
+	return v
+}
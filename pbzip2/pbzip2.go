@@ -0,0 +1,576 @@
+// Package pbzip2 implements a bzip2 decompressor which decodes the blocks in a bzip2 stream in parallel.
+//
+// Each block in a bzip2 stream is compressed independently of the others and starts with a (48-bit) magic number
+// but, unlike the stream itself, blocks are not aligned on byte boundaries. This package scans the compressed data
+// for block boundaries, re-wraps each block as a standalone single-block bzip2 stream and decodes those streams
+// concurrently using the standard library's `compress/bzip2` package, returning the decompressed data in order.
+package pbzip2
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"context"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+)
+
+// BLOCK_MAGIC is the 48-bit magic number that starts each compressed block in a bzip2 stream.
+const BLOCK_MAGIC uint64 = 0x314159265359
+
+// EOS_MAGIC is the 48-bit magic number that marks the end of a bzip2 stream.
+const EOS_MAGIC uint64 = 0x177245385090
+
+// MAGIC_BITS is the length, in bits, of the BLOCK_MAGIC and EOS_MAGIC magic numbers.
+const MAGIC_BITS int = 48
+
+// MAX_MERGE is the maximum number of following blocks that a block which fails to decode will be merged with. See notes in readBlock.
+const MAX_MERGE int = 4
+
+// STREAM_HEADER is the header used for the single-block bzip2 streams that each block is re-wrapped as. It uses the
+// largest block size (900k) so that it can accommodate blocks from streams using any compression level.
+const STREAM_HEADER string = "BZh9"
+
+const magic_mask uint64 = 1<<uint(MAGIC_BITS) - 1
+
+// type block is a struct containing the bits of a single compressed block, starting with its BLOCK_MAGIC magic number.
+type block struct {
+	bits  []byte
+	nbits int64
+	// last is a boolean flag indicating whether the block is the last block in its stream.
+	last bool
+	// stream_crc is the combined checksum, read from the end-of-stream trailer, of the stream that the block is the last block of.
+	stream_crc uint32
+}
+
+// type job is a struct containing a block to decode and the results of decoding it.
+type job struct {
+	block *block
+	data  []byte
+	err   error
+	done  chan bool
+}
+
+// type reader is a struct that implements the `io.ReadCloser` interface for decompressing bzip2 data in parallel.
+type reader struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	scan_ctx    context.Context
+	scan_cancel context.CancelFunc
+	scan_done   chan struct{}
+	src         io.Reader
+	queue       chan *job
+	jobs        chan *job
+	buf         []byte
+	err         error
+	scan_err    error
+	// crc is the combined checksum of the blocks read so far from the current stream.
+	crc uint32
+	// seeker and offset are used to rewind 'src' if the combined checksum of a stream doesn't match its trailer. If
+	// 'src' does not implement `io.Seeker` then seeker is nil.
+	seeker io.Seeker
+	offset int64
+	// emitted is the number of decompressed bytes returned so far and hash is their checksum.
+	emitted int64
+	hash    hash.Hash32
+	// fallback is the `compress/bzip2` reader used once the combined checksum of a stream doesn't match its trailer.
+	fallback io.Reader
+}
+
+// NewReader() returns an `io.ReadCloser` which decompresses the bzip2 data in 'r' using 'workers' goroutines to decode
+// blocks in parallel. If 'workers' is less than 2 then the data is decompressed sequentially using the standard library's
+// `compress/bzip2` package. The combined checksum of each stream is verified against its end-of-stream trailer; if they
+// don't match and 'r' implements `io.Seeker` then 'r' is rewound and the rest of the data is decompressed sequentially
+// using the `compress/bzip2` package, otherwise an error is returned. Callers should invoke the `Close` method once they
+// are done reading data in order to stop any pending work.
+func NewReader(ctx context.Context, r io.Reader, workers int) io.ReadCloser {
+
+	if workers < 2 {
+		return io.NopCloser(&contextReader{ctx: ctx, r: bzip2.NewReader(r)})
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	scan_ctx, scan_cancel := context.WithCancel(ctx)
+
+	z := &reader{
+		ctx:         ctx,
+		cancel:      cancel,
+		scan_ctx:    scan_ctx,
+		scan_cancel: scan_cancel,
+		scan_done:   make(chan struct{}),
+		src:         r,
+		queue:       make(chan *job, workers*2),
+		jobs:        make(chan *job, workers),
+	}
+
+	s, ok := r.(io.Seeker)
+
+	if ok {
+
+		offset, err := s.Seek(0, io.SeekCurrent)
+
+		if err == nil {
+			z.seeker = s
+			z.offset = offset
+			z.hash = crc32.NewIEEE()
+		}
+	}
+
+	for i := 0; i < workers; i++ {
+
+		go func() {
+
+			for j := range z.jobs {
+				j.data, j.err = decodeBlock(j.block)
+				close(j.done)
+			}
+		}()
+	}
+
+	go z.scan(r)
+
+	return z
+}
+
+// Read() reads decompressed data, in order, into 'p'.
+func (z *reader) Read(p []byte) (int, error) {
+
+	for len(z.buf) == 0 {
+
+		if z.err != nil {
+			return 0, z.err
+		}
+
+		if z.fallback != nil {
+			return z.fallback.Read(p)
+		}
+
+		var j *job
+		var ok bool
+
+		select {
+		case <-z.ctx.Done():
+			z.err = z.ctx.Err()
+			continue
+		case j, ok = <-z.queue:
+			// pass
+		}
+
+		if !ok {
+
+			z.err = z.scan_err
+
+			if z.err == nil {
+				z.err = io.EOF
+			}
+
+			continue
+		}
+
+		b, data, err := z.readBlock(j)
+
+		if err != nil {
+			z.err = err
+			continue
+		}
+
+		z.crc = (z.crc<<1 | z.crc>>31) ^ b.checksum()
+
+		if b.last {
+
+			crc := z.crc
+			z.crc = 0
+
+			if crc != b.stream_crc {
+				z.err = z.fallBack()
+				continue
+			}
+		}
+
+		z.buf = data
+	}
+
+	n := copy(p, z.buf)
+	z.buf = z.buf[n:]
+
+	z.emitted += int64(n)
+
+	if z.hash != nil {
+		z.hash.Write(p[:n])
+	}
+
+	return n, nil
+}
+
+// Close() stops any pending work.
+func (z *reader) Close() error {
+	z.cancel()
+	return nil
+}
+
+// fallBack() stops scanning for blocks, rewinds the compressed data and replaces the parallel decoder with a sequential
+// `compress/bzip2` reader positioned after the data that has already been returned. It returns an error if the compressed
+// data can't be rewound or if the data that has already been returned doesn't match the sequentially decoded data.
+func (z *reader) fallBack() error {
+
+	mismatch := bzip2.StructuralError("stream checksum mismatch")
+
+	if z.seeker == nil {
+		return mismatch
+	}
+
+	z.scan_cancel()
+	<-z.scan_done
+
+	_, err := z.seeker.Seek(z.offset, io.SeekStart)
+
+	if err != nil {
+		return fmt.Errorf("Failed to rewind compressed data, %w", err)
+	}
+
+	r := &contextReader{ctx: z.ctx, r: bzip2.NewReader(z.src)}
+
+	h := crc32.NewIEEE()
+
+	_, err = io.CopyN(h, r, z.emitted)
+
+	if err != nil {
+		return fmt.Errorf("Failed to decompress data, %w", err)
+	}
+
+	if h.Sum32() != z.hash.Sum32() {
+		return mismatch
+	}
+
+	z.buf = nil
+	z.fallback = r
+
+	return nil
+}
+
+// readBlock() waits for 'j' to be decoded and returns the block that was decoded (which will differ from the block in
+// 'j' if it was merged with the blocks that follow it) and its data.
+func (z *reader) readBlock(j *job) (*block, []byte, error) {
+
+	select {
+	case <-z.ctx.Done():
+		return nil, nil, z.ctx.Err()
+	case <-j.done:
+		// pass
+	}
+
+	if j.err == nil {
+		return j.block, j.data, nil
+	}
+
+	// Block boundaries are found by looking for the BLOCK_MAGIC magic number which may, very occasionally, also
+	// occur by chance in the compressed data itself. If that happens the real block will have been split in two
+	// and neither half will decode (they fail their checksums) so try merging a failed block with the blocks that
+	// follow it before giving up.
+
+	b := j.block
+
+	for i := 0; i < MAX_MERGE; i++ {
+
+		var next *job
+		var ok bool
+
+		select {
+		case <-z.ctx.Done():
+			return nil, nil, z.ctx.Err()
+		case next, ok = <-z.queue:
+			// pass
+		}
+
+		if !ok {
+			break
+		}
+
+		b = mergeBlocks(b, next.block)
+
+		data, err := decodeBlock(b)
+
+		if err == nil {
+			return b, data, nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("Failed to decode block, %w", j.err)
+}
+
+// scan() reads the compressed data in 'r', splits it in to blocks and dispatches those blocks to be decoded.
+func (z *reader) scan(r io.Reader) {
+
+	defer func() {
+		close(z.queue)
+		close(z.jobs)
+		close(z.scan_done)
+	}()
+
+	br := bufio.NewReaderSize(r, 1024*1024)
+
+	header := make([]byte, len(STREAM_HEADER))
+
+	_, err := io.ReadFull(br, header)
+
+	if err != nil || !isStreamHeader(header) {
+		z.scan_err = bzip2.StructuralError("bad magic value")
+		return
+	}
+
+	// 'data' contains the compressed bytes that have been read but not yet dispatched as part of a
+	// block; 'base' is the absolute offset (in bits) of the first bit in 'data'
+
+	data := make([]byte, 0, 1024*1024)
+	base := int64(len(header) * 8)
+	pos := base
+
+	block_start := int64(-1)
+	register := uint64(0)
+
+	// The EOS_MAGIC magic number may also occur by chance in the compressed data so its occurrences inside a block
+	// are only candidates for the end of the stream until the next block (or the end of the data) is reached
+
+	eos := make([]int64, 0)
+
+	// dispatchBlock() dispatches the block starting at 'block_start' and ending at 'end'. If 'last' is true then the
+	// block is the last block in its stream and is followed by an end-of-stream trailer starting at 'end'.
+
+	dispatchBlock := func(end int64, last bool) bool {
+
+		w := newBitWriter(int((end-block_start)/8) + 1)
+		w.copyBits(data, block_start-base, end-base)
+
+		b := &block{
+			bits:  w.bytes(),
+			nbits: w.nbits,
+			last:  last,
+		}
+
+		if last {
+			b.stream_crc = uint32(readBits(data, end-base+int64(MAGIC_BITS), 32))
+		}
+
+		return z.dispatch(b)
+	}
+
+	for {
+
+		if pos%(1024*1024*8) == 0 && z.scan_ctx.Err() != nil {
+			z.scan_err = z.scan_ctx.Err()
+			return
+		}
+
+		c, err := br.ReadByte()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			z.scan_err = fmt.Errorf("Failed to read compressed data, %w", err)
+			return
+		}
+
+		data = append(data, c)
+
+		for i := 7; i >= 0; i-- {
+
+			register = register<<1 | uint64((c>>uint(i))&1)
+			pos += 1
+
+			magic := register & magic_mask
+
+			if magic != BLOCK_MAGIC && magic != EOS_MAGIC {
+				continue
+			}
+
+			start := pos - int64(MAGIC_BITS)
+
+			if start < base {
+				continue
+			}
+
+			if magic == EOS_MAGIC {
+
+				// Outside of a block this is the end of a stream without any blocks which can be ignored
+
+				if block_start >= 0 {
+					eos = append(eos, start)
+				}
+
+				continue
+			}
+
+			if block_start >= 0 {
+
+				// The block ends at the first candidate end-of-stream magic number which is followed by its
+				// trailer, padding and the header of a new stream that this block magic number is the start of
+
+				end := start
+				last := false
+
+				for _, e := range eos {
+
+					next := streamEnd(e)
+
+					if start == next+int64(len(STREAM_HEADER)*8) && isStreamHeader(data[(next-base)/8:(start-base)/8]) {
+						end = e
+						last = true
+						break
+					}
+				}
+
+				if !dispatchBlock(end, last) {
+					return
+				}
+			}
+
+			block_start = start
+			eos = eos[:0]
+
+			// Discard the data that has already been dispatched
+
+			trim := (start - base) / 8
+
+			data = data[:copy(data, data[trim:])]
+			base += trim * 8
+		}
+
+		// Outside of a block only the last few bytes (which may contain the start of a magic number) need to be kept
+
+		if block_start < 0 && len(data) > 16 {
+
+			trim := int64(len(data) - 8)
+
+			data = data[:copy(data, data[trim:])]
+			base += trim * 8
+		}
+	}
+
+	if block_start < 0 {
+		return
+	}
+
+	// The last block ends at the candidate end-of-stream magic number whose trailer ends at the end of the data
+
+	for _, e := range eos {
+
+		if streamEnd(e) == pos {
+			dispatchBlock(e, true)
+			return
+		}
+	}
+
+	z.scan_err = io.ErrUnexpectedEOF
+}
+
+// dispatch() queues 'b' to be decoded, returning false if the reader has been closed or its context cancelled.
+func (z *reader) dispatch(b *block) bool {
+
+	j := &job{
+		block: b,
+		done:  make(chan bool),
+	}
+
+	select {
+	case <-z.scan_ctx.Done():
+		z.scan_err = z.scan_ctx.Err()
+		return false
+	case z.queue <- j:
+		// pass
+	}
+
+	select {
+	case <-z.scan_ctx.Done():
+		z.scan_err = z.scan_ctx.Err()
+		return false
+	case z.jobs <- j:
+		// pass
+	}
+
+	return true
+}
+
+// decodeBlock() decompresses 'b' by re-wrapping it as a standalone bzip2 stream.
+func decodeBlock(b *block) ([]byte, error) {
+
+	if b.nbits < int64(MAGIC_BITS+32) {
+		return nil, bzip2.StructuralError("short block")
+	}
+
+	w := newBitWriter(len(b.bits) + 16)
+
+	w.copyBits([]byte(STREAM_HEADER), 0, int64(len(STREAM_HEADER)*8))
+	w.copyBits(b.bits, 0, b.nbits)
+	w.writeBits(EOS_MAGIC, MAGIC_BITS)
+
+	// The checksum of a single-block stream is the checksum of its block
+
+	w.writeBits(uint64(b.checksum()), 32)
+
+	var out bytes.Buffer
+	out.Grow(1024 * 1024)
+
+	_, err := io.Copy(&out, bzip2.NewReader(bytes.NewReader(w.bytes())))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
+
+// checksum() returns the checksum of the (uncompressed) data in 'b', which immediately follows its block magic number.
+func (b *block) checksum() uint32 {
+	return uint32(readBits(b.bits, int64(MAGIC_BITS), 32))
+}
+
+// mergeBlocks() returns a new block containing the bits of 'a' followed by the bits of 'b'.
+func mergeBlocks(a *block, b *block) *block {
+
+	w := newBitWriter(len(a.bits) + len(b.bits))
+	w.copyBits(a.bits, 0, a.nbits)
+	w.copyBits(b.bits, 0, b.nbits)
+
+	m := &block{
+		bits:       w.bytes(),
+		nbits:      w.nbits,
+		last:       b.last,
+		stream_crc: b.stream_crc,
+	}
+
+	return m
+}
+
+// streamEnd() returns the offset (in bits) of the end of a stream whose end-of-stream magic number starts at offset
+// 'eos'. A stream ends with the magic number, the stream's combined checksum and padding to the next byte boundary.
+func streamEnd(eos int64) int64 {
+	return (eos + int64(MAGIC_BITS) + 32 + 7) / 8 * 8
+}
+
+// isStreamHeader() returns a boolean value indicating whether 'b' is a valid bzip2 stream header.
+func isStreamHeader(b []byte) bool {
+	return len(b) == 4 && b[0] == 'B' && b[1] == 'Z' && b[2] == 'h' && b[3] >= '1' && b[3] <= '9'
+}
+
+// type contextReader is a struct that implements the `io.Reader` interface and stops reading from
+// an underlying `io.Reader` once its context has been cancelled.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+// Read() reads from the underlying reader, returning the context's error if it has been cancelled.
+func (cr *contextReader) Read(p []byte) (int, error) {
+
+	err := cr.ctx.Err()
+
+	if err != nil {
+		return 0, err
+	}
+
+	return cr.r.Read(p)
+}
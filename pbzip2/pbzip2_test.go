@@ -0,0 +1,441 @@
+package pbzip2
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestReader(t *testing.T) {
+
+	ctx := context.Background()
+
+	paths := []string{
+		"../fixtures/lcsh.csv.bz2",
+		"../data/lcsh.csv.bz2",
+	}
+
+	for _, path := range paths {
+
+		body, err := os.ReadFile(path)
+
+		if err != nil {
+			t.Fatalf("Failed to read %s, %v", path, err)
+		}
+
+		expected, err := io.ReadAll(bzip2.NewReader(bytes.NewReader(body)))
+
+		if err != nil {
+			t.Fatalf("Failed to decompress %s, %v", path, err)
+		}
+
+		for _, workers := range []int{1, 2, 4} {
+
+			r := NewReader(ctx, bytes.NewReader(body), workers)
+
+			data, err := io.ReadAll(r)
+
+			if err != nil {
+				t.Fatalf("Failed to decompress %s with %d workers, %v", path, workers, err)
+			}
+
+			r.Close()
+
+			if !bytes.Equal(data, expected) {
+				t.Fatalf("Unexpected output decompressing %s with %d workers (%d bytes, expected %d)", path, workers, len(data), len(expected))
+			}
+		}
+	}
+}
+
+func TestReaderConcatenatedStreams(t *testing.T) {
+
+	ctx := context.Background()
+
+	body, err := os.ReadFile("../fixtures/lcsh.csv.bz2")
+
+	if err != nil {
+		t.Fatalf("Failed to read fixture, %v", err)
+	}
+
+	expected, err := io.ReadAll(bzip2.NewReader(bytes.NewReader(body)))
+
+	if err != nil {
+		t.Fatalf("Failed to decompress fixture, %v", err)
+	}
+
+	concatenated := append(append([]byte{}, body...), body...)
+
+	data, err := io.ReadAll(NewReader(ctx, bytes.NewReader(concatenated), 2))
+
+	if err != nil {
+		t.Fatalf("Failed to decompress concatenated streams, %v", err)
+	}
+
+	if !bytes.Equal(data, append(expected, expected...)) {
+		t.Fatalf("Unexpected output decompressing concatenated streams")
+	}
+}
+
+func TestReaderInvalid(t *testing.T) {
+
+	ctx := context.Background()
+
+	_, err := io.ReadAll(NewReader(ctx, bytes.NewReader([]byte("not bzip2 data")), 2))
+
+	if err == nil {
+		t.Fatalf("Expected an error decompressing invalid data")
+	}
+
+	body, err := os.ReadFile("../fixtures/lcsh.csv.bz2")
+
+	if err != nil {
+		t.Fatalf("Failed to read fixture, %v", err)
+	}
+
+	_, err = io.ReadAll(NewReader(ctx, bytes.NewReader(body[:len(body)/2]), 2))
+
+	if err == nil {
+		t.Fatalf("Expected an error decompressing truncated data")
+	}
+}
+
+func TestReaderCancelled(t *testing.T) {
+
+	body, err := os.ReadFile("../data/lcsh.csv.bz2")
+
+	if err != nil {
+		t.Fatalf("Failed to read data, %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	r := NewReader(ctx, bytes.NewReader(body), 2)
+	defer r.Close()
+
+	_, err = r.Read(make([]byte, 1024))
+
+	if err != nil {
+		t.Fatalf("Failed to read data, %v", err)
+	}
+
+	cancel()
+
+	_, err = io.ReadAll(r)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled error, got %v", err)
+	}
+}
+
+func TestMergeBlocks(t *testing.T) {
+
+	body, err := os.ReadFile("../fixtures/lcsh.csv.bz2")
+
+	if err != nil {
+		t.Fatalf("Failed to read fixture, %v", err)
+	}
+
+	expected, err := io.ReadAll(bzip2.NewReader(bytes.NewReader(body)))
+
+	if err != nil {
+		t.Fatalf("Failed to decompress fixture, %v", err)
+	}
+
+	// The fixture contains a single block which starts immediately after the stream header and ends at the
+	// end-of-stream magic number
+
+	start := int64(len(STREAM_HEADER) * 8)
+	end := int64(-1)
+
+	for i := start; i+int64(MAGIC_BITS) <= int64(len(body)*8); i++ {
+
+		if readBits(body, i, MAGIC_BITS) == EOS_MAGIC {
+			end = i
+		}
+	}
+
+	if end == -1 {
+		t.Fatalf("Failed to find end of stream")
+	}
+
+	newBlock := func(from int64, to int64) *block {
+
+		w := newBitWriter(0)
+		w.copyBits(body, from, to)
+
+		return &block{bits: w.bytes(), nbits: w.nbits}
+	}
+
+	whole := newBlock(start, end)
+
+	data, err := decodeBlock(whole)
+
+	if err != nil {
+		t.Fatalf("Failed to decode block, %v", err)
+	}
+
+	if !bytes.Equal(data, expected) {
+		t.Fatalf("Unexpected output decoding block")
+	}
+
+	// Simulate a block that has been split in two by a false positive match for the block magic number
+
+	split := start + (end-start)/2 + 3
+
+	first := &job{block: newBlock(start, split), done: make(chan bool)}
+	second := &job{block: newBlock(split, end), done: make(chan bool)}
+
+	for _, j := range []*job{first, second} {
+		j.data, j.err = decodeBlock(j.block)
+		close(j.done)
+	}
+
+	if first.err == nil {
+		t.Fatalf("Expected an error decoding a partial block")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	z := &reader{
+		ctx:    ctx,
+		cancel: cancel,
+		queue:  make(chan *job, 1),
+	}
+
+	z.queue <- second
+	close(z.queue)
+
+	_, data, err = z.readBlock(first)
+
+	if err != nil {
+		t.Fatalf("Failed to read merged block, %v", err)
+	}
+
+	if !bytes.Equal(data, expected) {
+		t.Fatalf("Unexpected output reading merged block")
+	}
+}
+
+func TestScanEndOfStream(t *testing.T) {
+
+	// A stream with two (fake) blocks, the first of which contains a false positive match for the end-of-stream
+	// magic number, followed by the real end-of-stream trailer
+
+	w := newBitWriter(0)
+	w.copyBits([]byte(STREAM_HEADER), 0, int64(len(STREAM_HEADER)*8))
+
+	w.writeBits(BLOCK_MAGIC, MAGIC_BITS)
+	w.writeBits(0x11111111, 32)
+	w.writeBits(0, 20)
+	w.writeBits(EOS_MAGIC, MAGIC_BITS)
+	w.writeBits(0, 30)
+
+	w.writeBits(BLOCK_MAGIC, MAGIC_BITS)
+	w.writeBits(0x22222222, 32)
+	w.writeBits(0, 17)
+	w.writeBits(EOS_MAGIC, MAGIC_BITS)
+	w.writeBits(0x33333333, 32)
+
+	body := w.bytes()
+
+	scan := func(body []byte) ([]*block, error) {
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		z := &reader{
+			ctx:         ctx,
+			cancel:      cancel,
+			scan_ctx:    ctx,
+			scan_cancel: cancel,
+			scan_done:   make(chan struct{}),
+			queue:       make(chan *job, 4),
+			jobs:        make(chan *job, 4),
+		}
+
+		z.scan(bytes.NewReader(body))
+
+		blocks := make([]*block, 0)
+
+		for j := range z.queue {
+			blocks = append(blocks, j.block)
+		}
+
+		return blocks, z.scan_err
+	}
+
+	blocks, err := scan(body)
+
+	if err != nil {
+		t.Fatalf("Failed to scan stream, %v", err)
+	}
+
+	if len(blocks) != 2 {
+		t.Fatalf("Expected 2 blocks, got %d", len(blocks))
+	}
+
+	if blocks[0].nbits != int64(MAGIC_BITS+32+20+MAGIC_BITS+30) || blocks[0].last {
+		t.Fatalf("Unexpected first block (%d bits, last %t)", blocks[0].nbits, blocks[0].last)
+	}
+
+	if blocks[0].checksum() != 0x11111111 {
+		t.Fatalf("Unexpected checksum for first block, %x", blocks[0].checksum())
+	}
+
+	if blocks[1].nbits != int64(MAGIC_BITS+32+17) || !blocks[1].last || blocks[1].stream_crc != 0x33333333 {
+		t.Fatalf("Unexpected second block (%d bits, last %t, stream checksum %x)", blocks[1].nbits, blocks[1].last, blocks[1].stream_crc)
+	}
+
+	_, err = scan(body[:len(body)-1])
+
+	if err != io.ErrUnexpectedEOF {
+		t.Fatalf("Expected io.ErrUnexpectedEOF scanning truncated stream, got %v", err)
+	}
+}
+
+func TestReaderChecksumMismatch(t *testing.T) {
+
+	ctx := context.Background()
+
+	body, err := os.ReadFile("../fixtures/lcsh.csv.bz2")
+
+	if err != nil {
+		t.Fatalf("Failed to read fixture, %v", err)
+	}
+
+	// Corrupt the stream's combined checksum, which immediately follows the end-of-stream magic number
+
+	end := int64(-1)
+
+	for i := int64(len(STREAM_HEADER) * 8); i+int64(MAGIC_BITS) <= int64(len(body)*8); i++ {
+
+		if readBits(body, i, MAGIC_BITS) == EOS_MAGIC {
+			end = i
+		}
+	}
+
+	if end == -1 {
+		t.Fatalf("Failed to find end of stream")
+	}
+
+	crc_pos := end + int64(MAGIC_BITS)
+
+	corrupted := append([]byte{}, body...)
+	corrupted[crc_pos/8] ^= 1 << (7 - uint(crc_pos%8))
+
+	readers := map[string]io.Reader{
+		"seekable":     bytes.NewReader(corrupted),
+		"not seekable": struct{ io.Reader }{bytes.NewReader(corrupted)},
+	}
+
+	for label, r := range readers {
+
+		_, err := io.ReadAll(NewReader(ctx, r, 2))
+
+		var structural_err bzip2.StructuralError
+
+		if !errors.As(err, &structural_err) {
+			t.Fatalf("Expected a structural error decompressing corrupted data (%s), got %v", label, err)
+		}
+	}
+}
+
+func TestReaderFallBack(t *testing.T) {
+
+	ctx := context.Background()
+
+	body, err := os.ReadFile("../data/lcsh.csv.bz2")
+
+	if err != nil {
+		t.Fatalf("Failed to read data, %v", err)
+	}
+
+	expected, err := io.ReadAll(bzip2.NewReader(bytes.NewReader(body)))
+
+	if err != nil {
+		t.Fatalf("Failed to decompress data, %v", err)
+	}
+
+	z := NewReader(ctx, bytes.NewReader(body), 2).(*reader)
+	defer z.Close()
+
+	head := make([]byte, 1024*1024)
+
+	_, err = io.ReadFull(z, head)
+
+	if err != nil {
+		t.Fatalf("Failed to read data, %v", err)
+	}
+
+	err = z.fallBack()
+
+	if err != nil {
+		t.Fatalf("Failed to fall back to sequential reader, %v", err)
+	}
+
+	tail, err := io.ReadAll(z)
+
+	if err != nil {
+		t.Fatalf("Failed to read data after falling back, %v", err)
+	}
+
+	if !bytes.Equal(append(head, tail...), expected) {
+		t.Fatalf("Unexpected output after falling back")
+	}
+
+	// Falling back fails if the data that has already been returned doesn't match
+
+	z = NewReader(ctx, bytes.NewReader(body), 2).(*reader)
+	defer z.Close()
+
+	_, err = io.ReadFull(z, head)
+
+	if err != nil {
+		t.Fatalf("Failed to read data, %v", err)
+	}
+
+	z.hash.Write([]byte("corrupted"))
+
+	err = z.fallBack()
+
+	if err == nil {
+		t.Fatalf("Expected falling back to fail")
+	}
+}
+
+func BenchmarkReader(b *testing.B) {
+
+	ctx := context.Background()
+
+	body, err := os.ReadFile("../data/lcsh.csv.bz2")
+
+	if err != nil {
+		b.Fatalf("Failed to read data, %v", err)
+	}
+
+	for _, workers := range []int{1, 2, 4, 8} {
+
+		b.Run(fmt.Sprintf("workers-%d", workers), func(b *testing.B) {
+
+			for i := 0; i < b.N; i++ {
+
+				r := NewReader(ctx, bytes.NewReader(body), workers)
+
+				_, err := io.Copy(io.Discard, r)
+
+				if err != nil {
+					b.Fatalf("Failed to decompress data, %v", err)
+				}
+
+				r.Close()
+			}
+		})
+	}
+}
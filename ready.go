@@ -0,0 +1,145 @@
+package libraryofcongress
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// BACKGROUND_PARAMETER is the name of the query parameter used by `Lookup` URIs to load data in the background.
+const BACKGROUND_PARAMETER string = "background"
+
+// WAIT_PARAMETER is the name of the query parameter used by `Lookup` URIs to signal that queries made while data is
+// being loaded in the background should wait for loading to complete rather than returning a `NotReady` error.
+const WAIT_PARAMETER string = "wait"
+
+// type ReadyLookup is an optional interface, implemented by `Lookup` implementations which can load their data in
+// the background, for determining whether a lookup has finished loading.
+type ReadyLookup interface {
+	Lookup
+	// Ready() returns a channel which is closed when the lookup has finished loading (successfully or not).
+	Ready() <-chan struct{}
+	// Wait() blocks until the lookup has finished loading, returning the error (if any) encountered loading it,
+	// or until the context is cancelled.
+	Wait(context.Context) error
+}
+
+// Wait() blocks until 'l' has finished loading, or 'ctx' is cancelled. If 'l' does not implement the `ReadyLookup`
+// interface then it is assumed to have been loaded when it was created and Wait() returns immediately.
+func Wait(ctx context.Context, l Lookup) error {
+
+	r, ok := l.(ReadyLookup)
+
+	if !ok {
+		return nil
+	}
+
+	return r.Wait(ctx)
+}
+
+// type Readiness is a struct for tracking whether the data for a lookup has finished loading. It is meant to be
+// embedded in `Lookup` implementations which can load their data in the background.
+type Readiness struct {
+	// source is the (optional) name of the LoC data source being loaded.
+	source string
+	// wait signals that Check() should wait for loading to complete rather than returning a `NotReady` error.
+	wait  bool
+	ready chan struct{}
+	once  *sync.Once
+	// mu guards 'err' which may be cleared, by `Succeed`, after loading has completed.
+	mu  *sync.RWMutex
+	err error
+}
+
+// NewReadiness() returns a new `Readiness` instance for tracking the loading of data for 'source'. If 'wait' is true
+// then the `Check` method will wait for loading to complete rather than returning a `NotReady` error.
+func NewReadiness(source string, wait bool) *Readiness {
+
+	r := &Readiness{
+		source: source,
+		wait:   wait,
+		ready:  make(chan struct{}),
+		once:   new(sync.Once),
+		mu:     new(sync.RWMutex),
+	}
+
+	return r
+}
+
+// Done() signals that loading has completed. If 'err' is not nil then loading is considered to have failed and 'err'
+// is returned by any subsequent calls to `Wait` or `Check`. Only the first call to Done() has any effect.
+func (r *Readiness) Done(err error) {
+
+	r.once.Do(func() {
+		r.mu.Lock()
+		r.err = err
+		r.mu.Unlock()
+		close(r.ready)
+	})
+}
+
+// Succeed() signals that loading has completed successfully, clearing any error previously passed to `Done`. Unlike
+// `Done` it may be called more than once, for example when a lookup whose data could not be loaded is reloaded.
+func (r *Readiness) Succeed() {
+
+	r.mu.Lock()
+	r.err = nil
+	r.mu.Unlock()
+
+	r.Done(nil)
+}
+
+// Ready() returns a channel which is closed when loading has completed.
+func (r *Readiness) Ready() <-chan struct{} {
+	return r.ready
+}
+
+// Wait() blocks until loading has completed, returning the error (if any) encountered loading data, or until 'ctx'
+// is cancelled in which case `ctx.Err()` is returned.
+func (r *Readiness) Wait(ctx context.Context) error {
+
+	select {
+	case <-r.ready:
+		return r.result()
+	default:
+		// pass
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-r.ready:
+		return r.result()
+	}
+}
+
+// Check() returns nil if loading has completed successfully. If loading is still in progress then it returns a
+// `NotReady` error or, if 'r' was created with 'wait' set to true, the result of calling `Wait`.
+func (r *Readiness) Check(ctx context.Context) error {
+
+	select {
+	case <-r.ready:
+		return r.result()
+	default:
+		// pass
+	}
+
+	if !r.wait {
+		return NotReady{Source: r.source}
+	}
+
+	return r.Wait(ctx)
+}
+
+// result() returns the error (if any) encountered loading data. It should only be called once loading has completed.
+func (r *Readiness) result() error {
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.err != nil {
+		return fmt.Errorf("Failed to load lookup, %w", r.err)
+	}
+
+	return nil
+}
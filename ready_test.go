@@ -0,0 +1,129 @@
+package libraryofcongress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestReadiness(t *testing.T) {
+
+	ctx := context.Background()
+
+	r := NewReadiness("lcsh", false)
+
+	select {
+	case <-r.Ready():
+		t.Fatalf("Expected readiness to not be ready")
+	default:
+		// pass
+	}
+
+	err := r.Check(ctx)
+
+	if !IsNotReady(err) {
+		t.Fatalf("Expected NotReady error, got %v", err)
+	}
+
+	timeout_ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	err = r.Wait(timeout_ctx)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded error, got %v", err)
+	}
+
+	r.Done(nil)
+	r.Done(fmt.Errorf("Ignored"))
+
+	<-r.Ready()
+
+	err = r.Check(ctx)
+
+	if err != nil {
+		t.Fatalf("Expected readiness to be ready, %v", err)
+	}
+
+	err = r.Wait(ctx)
+
+	if err != nil {
+		t.Fatalf("Expected readiness to be ready, %v", err)
+	}
+}
+
+func TestReadinessWait(t *testing.T) {
+
+	ctx := context.Background()
+
+	r := NewReadiness("lcsh", true)
+
+	load_err := fmt.Errorf("Failed to read data")
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		r.Done(load_err)
+	}()
+
+	err := r.Check(ctx)
+
+	if !errors.Is(err, load_err) {
+		t.Fatalf("Expected load error, got %v", err)
+	}
+
+	if IsNotReady(err) {
+		t.Fatalf("Expected error to not be NotReady")
+	}
+}
+
+func TestWait(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Lookups which don't implement ReadyLookup are always ready
+
+	err := Wait(ctx, &TestLookup{})
+
+	if err != nil {
+		t.Fatalf("Expected lookup to be ready, %v", err)
+	}
+}
+
+func TestReadinessSucceed(t *testing.T) {
+
+	ctx := context.Background()
+
+	load_err := fmt.Errorf("Failed to load data")
+
+	r := NewReadiness("lcsh", false)
+	r.Done(load_err)
+
+	err := r.Check(ctx)
+
+	if !errors.Is(err, load_err) {
+		t.Fatalf("Expected load error, got %v", err)
+	}
+
+	r.Succeed()
+
+	err = r.Check(ctx)
+
+	if err != nil {
+		t.Fatalf("Expected readiness to be ready after succeeding, %v", err)
+	}
+
+	// Succeed can also be used instead of Done
+
+	r = NewReadiness("lcsh", false)
+	r.Succeed()
+	r.Succeed()
+
+	err = r.Wait(ctx)
+
+	if err != nil {
+		t.Fatalf("Expected readiness to be ready, %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package reconcile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IDENTIFIER_SPACE is the URI identifying the space of identifiers returned by the service.
+const IDENTIFIER_SPACE string = "http://id.loc.gov/authorities/"
+
+// SCHEMA_SPACE is the URI identifying the space of types used by the service.
+const SCHEMA_SPACE string = "http://id.loc.gov/authorities/"
+
+// type Manifest is a struct describing the service manifest returned by a reconciliation service.
+type Manifest struct {
+	Versions        []string       `json:"versions"`
+	Name            string         `json:"name"`
+	IdentifierSpace string         `json:"identifierSpace"`
+	SchemaSpace     string         `json:"schemaSpace"`
+	DefaultTypes    []*Type        `json:"defaultTypes"`
+	View            *View          `json:"view"`
+	Preview         *Preview       `json:"preview"`
+	Suggest         *SuggestConfig `json:"suggest"`
+}
+
+// type View is a struct describing the URL template used to view reconciled entities.
+type View struct {
+	URL string `json:"url"`
+}
+
+// type Preview is a struct describing the URL template, and dimensions, used to preview reconciled entities.
+type Preview struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// type SuggestConfig is a struct describing the suggest services provided by a reconciliation service.
+type SuggestConfig struct {
+	Entity *SuggestService `json:"entity"`
+}
+
+// type SuggestService is a struct describing an individual suggest service.
+type SuggestService struct {
+	ServiceURL  string `json:"service_url"`
+	ServicePath string `json:"service_path"`
+}
+
+// Manifest() returns the service manifest for 's' using 'root_url' as the URL prefix for the view, preview and suggest
+// endpoints. For example if 'root_url' is "http://localhost:8080/reconcile" the preview endpoint will be
+// "http://localhost:8080/reconcile/preview".
+func (s *Service) Manifest(root_url string) *Manifest {
+
+	root_url = strings.TrimRight(root_url, "/")
+
+	m := &Manifest{
+		Versions:        []string{API_VERSION},
+		Name:            s.name,
+		IdentifierSpace: IDENTIFIER_SPACE,
+		SchemaSpace:     SCHEMA_SPACE,
+		DefaultTypes:    s.Types(),
+		View: &View{
+			URL: fmt.Sprintf("%s/view/{{id}}", root_url),
+		},
+		Preview: &Preview{
+			URL:    fmt.Sprintf("%s/preview?id={{id}}", root_url),
+			Width:  400,
+			Height: 100,
+		},
+		Suggest: &SuggestConfig{
+			Entity: &SuggestService{
+				ServiceURL:  root_url,
+				ServicePath: "/suggest/entity",
+			},
+		},
+	}
+
+	return m
+}
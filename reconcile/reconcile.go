@@ -0,0 +1,61 @@
+// Package reconcile implements the W3C Reconciliation Service API (https://reconciliation-api.github.io/specs/0.2/),
+// used by tools like OpenRefine, on top of `libraryofcongress.Lookup` instances.
+package reconcile
+
+// API_VERSION is the version of the Reconciliation Service API implemented by this package.
+const API_VERSION string = "0.2"
+
+// DEFAULT_LIMIT is the default maximum number of candidates returned for a reconciliation query.
+const DEFAULT_LIMIT int = 5
+
+// type Type is a struct describing a reconciliation type.
+type Type struct {
+	// Id is the unique identifier for the type (for example "lcsh").
+	Id string `json:"id"`
+	// Name is the human-readable name for the type.
+	Name string `json:"name"`
+}
+
+// type Query is a struct describing a single reconciliation query.
+type Query struct {
+	// Query is the string to reconcile.
+	Query string `json:"query"`
+	// Type is the (optional) type to restrict candidates to. Valid options are "lcsh" and "lcnaf".
+	Type string `json:"type,omitempty"`
+	// Limit is the (optional) maximum number of candidates to return.
+	Limit int `json:"limit,omitempty"`
+}
+
+// type Candidate is a struct describing a possible match for a reconciliation query.
+type Candidate struct {
+	// Id is the LoC identifier for the candidate.
+	Id string `json:"id"`
+	// Name is the (LoC) label for the candidate.
+	Name string `json:"name"`
+	// Type is the list of types the candidate belongs to.
+	Type []*Type `json:"type"`
+	// Score is a number between 0 and 100 indicating how similar the candidate is to the query.
+	Score float64 `json:"score"`
+	// Match is a boolean value indicating whether the candidate is considered an unambiguous match for the query.
+	Match bool `json:"match"`
+}
+
+// type Results is a struct containing the candidates for a reconciliation query.
+type Results struct {
+	Result []*Candidate `json:"result"`
+}
+
+// type SuggestResult is a struct describing a single "suggest" result.
+type SuggestResult struct {
+	// Id is the LoC identifier for the result.
+	Id string `json:"id"`
+	// Name is the (LoC) label for the result.
+	Name string `json:"name"`
+	// Description is a short description of the result.
+	Description string `json:"description,omitempty"`
+}
+
+// type SuggestResults is a struct containing the results for a "suggest" query.
+type SuggestResults struct {
+	Result []*SuggestResult `json:"result"`
+}
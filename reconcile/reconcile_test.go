@@ -0,0 +1,148 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	_ "github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcsh"
+	_ "github.com/sfomuseum/go-sfomuseum-libraryofcongress/sqlite"
+	"path/filepath"
+	"testing"
+)
+
+func TestScore(t *testing.T) {
+
+	tests := map[string]float64{
+		"Airplanes":  100,
+		"airplanes.": 100,
+		"AIRPLANES ": 100,
+		"Aéroplanes": 80,
+		"Planes":     66.67,
+	}
+
+	for q, expected := range tests {
+
+		score := Score(q, "Airplanes")
+
+		if score != expected {
+			t.Fatalf("Unexpected score for '%s': %f (expected %f)", q, score, expected)
+		}
+	}
+}
+
+func TestServiceCandidates(t *testing.T) {
+
+	ctx := context.Background()
+
+	rel_path := "../fixtures/lcsh.csv.bz2"
+	abs_path, err := filepath.Abs(rel_path)
+
+	if err != nil {
+		t.Fatalf("Failed to derive absolute path for %s, %v", rel_path, err)
+	}
+
+	l, err := libraryofcongress.NewLookup(ctx, fmt.Sprintf("lcsh://file%s", abs_path))
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	s, err := NewService(ctx, l)
+
+	if err != nil {
+		t.Fatalf("Failed to create service, %v", err)
+	}
+
+	candidates, err := s.Candidates(ctx, &Query{Query: "Airplanes", Type: "lcsh"})
+
+	if err != nil {
+		t.Fatalf("Failed to derive candidates, %v", err)
+	}
+
+	if len(candidates) != 1 {
+		t.Fatalf("Expected 1 candidate, got %d", len(candidates))
+	}
+
+	if candidates[0].Id != "sh85002782" || !candidates[0].Match {
+		t.Fatalf("Unexpected candidate, %v", candidates[0])
+	}
+
+	candidates, err = s.Candidates(ctx, &Query{Query: "Airplanes", Type: "lcnaf"})
+
+	if err != nil {
+		t.Fatalf("Failed to derive candidates, %v", err)
+	}
+
+	if len(candidates) != 0 {
+		t.Fatalf("Expected 0 candidates, got %d", len(candidates))
+	}
+
+	candidates, err = s.Candidates(ctx, &Query{Query: "Airplanes", Type: "unknown"})
+
+	if err != nil {
+		t.Fatalf("Failed to derive candidates for unsupported type, %v", err)
+	}
+
+	if len(candidates) != 0 {
+		t.Fatalf("Expected 0 candidates for unsupported type, got %d", len(candidates))
+	}
+
+	queries := map[string]*Query{
+		"q0": &Query{Query: "Airplanes", Type: "lcsh"},
+		"q1": &Query{Query: "Airplanes", Type: "unknown"},
+	}
+
+	results, err := s.Reconcile(ctx, queries)
+
+	if err != nil {
+		t.Fatalf("Failed to reconcile queries, %v", err)
+	}
+
+	if len(results["q0"].Result) != 1 {
+		t.Fatalf("Expected 1 candidate for q0, got %d", len(results["q0"].Result))
+	}
+
+	if len(results["q1"].Result) != 0 {
+		t.Fatalf("Expected 0 candidates for q1, got %d", len(results["q1"].Result))
+	}
+}
+
+func TestServiceRecordSQLite(t *testing.T) {
+
+	ctx := context.Background()
+
+	rel_path := "../fixtures/libraryofcongress.db"
+	abs_path, err := filepath.Abs(rel_path)
+
+	if err != nil {
+		t.Fatalf("Failed to derive absolute path for %s, %v", rel_path, err)
+	}
+
+	l, err := libraryofcongress.NewLookup(ctx, fmt.Sprintf("sqlite://%s", abs_path))
+
+	if err != nil {
+		t.Fatalf("Failed to create lookup, %v", err)
+	}
+
+	s, err := NewService(ctx, l)
+
+	if err != nil {
+		t.Fatalf("Failed to create service, %v", err)
+	}
+
+	r, err := s.Record(ctx, "sh00000011")
+
+	if err != nil {
+		t.Fatalf("Failed to retrieve record, %v", err)
+	}
+
+	if r.GetLabel() != "ActionScript (Computer program language)" {
+		t.Fatalf("Unexpected record, %v", r)
+	}
+
+	_, err = s.Record(ctx, "sh00000000")
+
+	if err == nil {
+		t.Fatalf("Expected missing record to fail")
+	}
+}
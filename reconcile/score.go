@@ -0,0 +1,85 @@
+package reconcile
+
+import (
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"math"
+)
+
+// normalize is the function used to normalize queries and labels before they are compared.
+var normalize = libraryofcongress.ChainNormalizeFuncs(
+	libraryofcongress.CaseFold,
+	libraryofcongress.StripDiacritics,
+	libraryofcongress.CollapseWhitespace,
+	libraryofcongress.TrimTrailingPeriod,
+	libraryofcongress.NormalizeSubdivisions,
+)
+
+// Score() returns a number between 0 and 100 indicating how similar 'label' is to 'query'. Both values are normalized
+// (case, diacritics, whitespace, trailing periods and subdivision separators) and then compared using their Levenshtein
+// distance relative to the length of the longer value.
+func Score(query string, label string) float64 {
+
+	a := []rune(normalize(query))
+	b := []rune(normalize(label))
+
+	max_len := len(a)
+
+	if len(b) > max_len {
+		max_len = len(b)
+	}
+
+	if max_len == 0 {
+		return 0
+	}
+
+	d := levenshtein(a, b)
+
+	score := 100.0 * (1.0 - float64(d)/float64(max_len))
+	return math.Round(score*100) / 100
+}
+
+// levenshtein() returns the Levenshtein (edit) distance between 'a' and 'b'.
+func levenshtein(a []rune, b []rune) int {
+
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+
+		curr[0] = i
+
+		for j := 1; j <= len(b); j++ {
+
+			cost := 1
+
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = minimum(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+// minimum() returns the smallest of 'values'.
+func minimum(values ...int) int {
+
+	m := values[0]
+
+	for _, v := range values[1:] {
+
+		if v < m {
+			m = v
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,342 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aaronland/go-pagination"
+	"github.com/aaronland/go-pagination/countable"
+	loc_database "github.com/sfomuseum/go-libraryofcongress-database"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcnaf"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcsh"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/sqlite/tables"
+	"sort"
+	"strings"
+)
+
+// DEFAULT_NAME is the default name for reconciliation services.
+const DEFAULT_NAME string = "SFO Museum Library of Congress reconciliation service"
+
+// type Searcher is an optional interface that `libraryofcongress.Lookup` implementations may implement to provide
+// full-text candidates and suggestions in addition to those returned by the `Find` method. It is implemented by
+// `sqlite.SQLiteLookup` when the underlying database has a "search" table. Lookups which don't implement it only
+// return candidates and suggestions for queries which `Find` matches exactly; there are no partial or prefix matches.
+type Searcher interface {
+	// Search() returns the records matching a full-text query.
+	Search(context.Context, string, pagination.Options) ([]*loc_database.QueryResult, pagination.Results, error)
+}
+
+// type Service implements the W3C Reconciliation Service API on top of a `libraryofcongress.Lookup` instance.
+type Service struct {
+	lookup libraryofcongress.Lookup
+	name   string
+	types  map[string]*Type
+}
+
+// NewService() returns a new `Service` instance for reconciling queries against records in 'l'.
+func NewService(ctx context.Context, l libraryofcongress.Lookup) (*Service, error) {
+
+	types := map[string]*Type{
+		lcsh.SOURCE: &Type{
+			Id:   lcsh.SOURCE,
+			Name: "Library of Congress Subject Headings",
+		},
+		lcnaf.SOURCE: &Type{
+			Id:   lcnaf.SOURCE,
+			Name: "Library of Congress Name Authority File",
+		},
+	}
+
+	s := &Service{
+		lookup: l,
+		name:   DEFAULT_NAME,
+		types:  types,
+	}
+
+	return s, nil
+}
+
+// Types() returns the list of types supported by 's'.
+func (s *Service) Types() []*Type {
+
+	types := make([]*Type, 0)
+
+	for _, t := range s.types {
+		types = append(types, t)
+	}
+
+	sort.Slice(types, func(i, j int) bool {
+		return types[i].Id < types[j].Id
+	})
+
+	return types
+}
+
+// Reconcile() returns the candidates for each query in 'queries', keyed by the same (batch) key used in 'queries'.
+// Queries for unsupported types have no candidates rather than failing the whole batch.
+func (s *Service) Reconcile(ctx context.Context, queries map[string]*Query) (map[string]*Results, error) {
+
+	results := make(map[string]*Results)
+
+	for key, q := range queries {
+
+		candidates, err := s.Candidates(ctx, q)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to reconcile query %s, %w", key, err)
+		}
+
+		results[key] = &Results{
+			Result: candidates,
+		}
+	}
+
+	return results, nil
+}
+
+// Candidates() returns the scored and sorted candidates for 'q'. A candidate is flagged as a match if it is the only
+// candidate with a score of 100. If 'q' is for an unsupported type there are no candidates. See `records` for
+// which records are considered.
+func (s *Service) Candidates(ctx context.Context, q *Query) ([]*Candidate, error) {
+
+	query := strings.TrimSpace(q.Query)
+
+	if query == "" {
+		return []*Candidate{}, nil
+	}
+
+	if q.Type != "" {
+
+		_, ok := s.types[q.Type]
+
+		if !ok {
+			return []*Candidate{}, nil
+		}
+	}
+
+	limit := q.Limit
+
+	if limit <= 0 {
+		limit = DEFAULT_LIMIT
+	}
+
+	records, err := s.records(ctx, query, limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]*Candidate, 0)
+
+	for _, r := range records {
+
+		if q.Type != "" && r.GetSource() != q.Type {
+			continue
+		}
+
+		c := &Candidate{
+			Id:    r.GetId(),
+			Name:  r.GetLabel(),
+			Type:  s.typesForRecord(r),
+			Score: Score(query, r.GetLabel()),
+		}
+
+		candidates = append(candidates, c)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+
+		return candidates[i].Name < candidates[j].Name
+	})
+
+	if len(candidates) > limit {
+		candidates = candidates[0:limit]
+	}
+
+	if len(candidates) == 1 && candidates[0].Score == 100 {
+		candidates[0].Match = true
+	}
+
+	if len(candidates) > 1 && candidates[0].Score == 100 && candidates[1].Score < 100 {
+		candidates[0].Match = true
+	}
+
+	return candidates, nil
+}
+
+// Suggest() returns records matching 'prefix'. If the underlying lookup implements the `Searcher` interface this
+// includes full-text matches for 'prefix'. Otherwise only records that the lookup's `Find` method matches exactly are
+// returned, so partial labels will not yield any suggestions.
+func (s *Service) Suggest(ctx context.Context, prefix string, limit int) (*SuggestResults, error) {
+
+	suggestions := &SuggestResults{
+		Result: make([]*SuggestResult, 0),
+	}
+
+	prefix = strings.TrimSpace(prefix)
+
+	if prefix == "" {
+		return suggestions, nil
+	}
+
+	if limit <= 0 {
+		limit = DEFAULT_LIMIT
+	}
+
+	records, err := s.records(ctx, prefix, limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range records {
+
+		desc := r.GetSource()
+
+		t, ok := s.types[r.GetSource()]
+
+		if ok {
+			desc = t.Name
+		}
+
+		suggestions.Result = append(suggestions.Result, &SuggestResult{
+			Id:          r.GetId(),
+			Name:        r.GetLabel(),
+			Description: desc,
+		})
+
+		if len(suggestions.Result) >= limit {
+			break
+		}
+	}
+
+	return suggestions, nil
+}
+
+// Record() returns the record whose identifier exactly matches 'id'.
+func (s *Service) Record(ctx context.Context, id string) (libraryofcongress.Record, error) {
+
+	results, err := s.lookup.Find(ctx, id)
+
+	if err != nil && !isNotFound(err) {
+		return nil, fmt.Errorf("Failed to find %s, %w", id, err)
+	}
+
+	for _, r := range results {
+
+		rec, ok := r.(libraryofcongress.Record)
+
+		if ok && rec.GetId() == id {
+			return rec, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Record %s not found", id)
+}
+
+// records() returns the (de-duplicated) records for 'query' returned by the underlying lookup's `Find` method
+// and, if the lookup implements the `Searcher` interface, its `Search` method. Since `Find` only matches whole
+// (normalized) labels and identifiers, lookups without a `Searcher` never return partial or prefix matches.
+func (s *Service) records(ctx context.Context, query string, limit int) ([]libraryofcongress.Record, error) {
+
+	records := make([]libraryofcongress.Record, 0)
+	seen := make(map[string]bool)
+
+	add := func(r libraryofcongress.Record) {
+
+		key := fmt.Sprintf("%s:%s", r.GetSource(), r.GetId())
+
+		if seen[key] {
+			return
+		}
+
+		seen[key] = true
+		records = append(records, r)
+	}
+
+	results, err := s.lookup.Find(ctx, query)
+
+	if err != nil && !isNotFound(err) {
+		return nil, fmt.Errorf("Failed to find %s, %w", query, err)
+	}
+
+	for _, r := range results {
+
+		rec, ok := r.(libraryofcongress.Record)
+
+		if !ok {
+			return nil, fmt.Errorf("Unsupported record type %T", r)
+		}
+
+		add(rec)
+	}
+
+	searcher, ok := s.lookup.(Searcher)
+
+	if !ok {
+		return records, nil
+	}
+
+	pg_opts, err := countable.NewCountableOptions()
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create pagination options, %w", err)
+	}
+
+	// Fetch more than we need since results will be filtered by type and re-sorted by score
+	pg_opts.PerPage(int64(limit * 4))
+
+	search_results, _, err := searcher.Search(ctx, tables.SearchTerms(query), pg_opts)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to search for %s, %w", query, err)
+	}
+
+	for _, r := range search_results {
+		add(&searchRecord{r})
+	}
+
+	return records, nil
+}
+
+// typesForRecord() returns the list of types for 'r'.
+func (s *Service) typesForRecord(r libraryofcongress.Record) []*Type {
+
+	t, ok := s.types[r.GetSource()]
+
+	if !ok {
+		t = &Type{
+			Id:   r.GetSource(),
+			Name: r.GetSource(),
+		}
+	}
+
+	return []*Type{t}
+}
+
+// isNotFound() returns a boolean value indicating whether 'err' is a "not found" error.
+func isNotFound(err error) bool {
+	return errors.Is(err, libraryofcongress.ErrNotFound)
+}
+
+// type searchRecord wraps a `loc_database.QueryResult` instance so that it implements the `libraryofcongress.Record` interface.
+type searchRecord struct {
+	*loc_database.QueryResult
+}
+
+func (r *searchRecord) GetId() string {
+	return r.Id
+}
+
+func (r *searchRecord) GetLabel() string {
+	return r.Label
+}
+
+func (r *searchRecord) GetSource() string {
+	return r.Source
+}
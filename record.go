@@ -0,0 +1,13 @@
+package libraryofcongress
+
+// type Record provides a common interface for the Library of Congress (LoC) records returned by `Lookup` implementations.
+// Method names are prefixed with "Get" because the concrete record types (for example `lcsh.SubjectHeading`) already
+// export `Id` and `Label` properties.
+type Record interface {
+	// GetId() returns the unique LoC identifier for the record.
+	GetId() string
+	// GetLabel() returns the name (or title) for the record.
+	GetLabel() string
+	// GetSource() returns the name of the LoC data source (for example "lcsh" or "lcnaf") for the record.
+	GetSource() string
+}
@@ -0,0 +1,30 @@
+package libraryofcongress
+
+import (
+	"context"
+	"fmt"
+)
+
+// type ReloadableLookup is an optional interface, implemented by the in-memory (`lcsh://` and `lcnaf://`) and SQLite
+// `Lookup` implementations in this package's sub-packages, for replacing the data in a lookup without restarting
+// the process using it. For example, to pick up a new monthly LoC data dump.
+type ReloadableLookup interface {
+	Lookup
+	// Reload() loads the data for a URI, whose semantics are specific to the package implementing the interface,
+	// off to the side and then atomically replaces the lookup's data with it. Queries in progress while the data
+	// is being loaded, or replaced, are unaffected.
+	Reload(context.Context, string) error
+}
+
+// Reload() replaces the data in 'l' with the data for 'uri'. It returns an error if 'l' does not implement the
+// `ReloadableLookup` interface.
+func Reload(ctx context.Context, l Lookup, uri string) error {
+
+	r, ok := l.(ReloadableLookup)
+
+	if !ok {
+		return fmt.Errorf("Lookup (%T) does not implement ReloadableLookup", l)
+	}
+
+	return r.Reload(ctx, uri)
+}
@@ -0,0 +1,141 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcnaf"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcsh"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/sqlite/tables"
+)
+
+// Append() adds 'data' to the underlying database. 'data' may be a `*lcsh.SubjectHeading`, a `*lcnaf.NamedAuthority`,
+// any other `libraryofcongress.Record` implementation or a `map[string]string` row with "id", "label" and "source" keys.
+// The record is written to the identifiers table and, if present, the search and normalized tables in a single
+// transaction. Existing records with the same identifier are replaced.
+func (l *SQLiteLookup) Append(ctx context.Context, data interface{}) error {
+
+	row, err := newRow(data)
+
+	if err != nil {
+		return err
+	}
+
+	if l.source != "" && row["source"] != l.source {
+		return fmt.Errorf("Record source '%s' does not match lookup source '%s'", row["source"], l.source)
+	}
+
+	h, release := l.acquire()
+	defer release()
+
+	return l.execStatements(ctx, h, l.appendStatements(h, row))
+}
+
+// appendStatements() returns the list of SQL statements, and their arguments, necessary to add 'row' to the identifiers
+// table and, if present, the search and normalized tables.
+func (l *SQLiteLookup) appendStatements(h *handle, row map[string]string) [][]interface{} {
+
+	statements := [][]interface{}{
+		[]interface{}{
+			"INSERT OR REPLACE INTO identifiers (id, source, label) VALUES (?, ?, ?)",
+			row["id"], row["source"], row["label"],
+		},
+	}
+
+	if h.has_search {
+
+		// The search table is a virtual (FTS) table without a primary key so any existing rows for the
+		// identifier are deleted first.
+
+		statements = append(statements, tables.SearchReplaceStatements(row, h.has_search_rowids)...)
+	}
+
+	if h.has_normalized {
+
+		statements = append(statements,
+			[]interface{}{
+				fmt.Sprintf("DELETE FROM %s WHERE id = ?", tables.NORMALIZED_TABLE_NAME),
+				row["id"],
+			},
+			[]interface{}{
+				fmt.Sprintf("INSERT INTO %s (id, source, label, normalized) VALUES (?, ?, ?, ?)", tables.NORMALIZED_TABLE_NAME),
+				row["id"], row["source"], row["label"], l.normalize(row["label"]),
+			},
+		)
+	}
+
+	return statements
+}
+
+// execStatements() executes 'statements', where each statement is a list whose first element is a SQL query
+// and whose remaining elements are its arguments, in a single transaction.
+func (l *SQLiteLookup) execStatements(ctx context.Context, h *handle, statements [][]interface{}) error {
+
+	conn, err := h.db.Conn()
+
+	if err != nil {
+		return fmt.Errorf("Failed to establish database connection, %w", err)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+
+	if err != nil {
+		return fmt.Errorf("Failed to begin transaction, %w", err)
+	}
+
+	for _, st := range statements {
+
+		q := st[0].(string)
+
+		_, err := tx.ExecContext(ctx, q, st[1:]...)
+
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("Failed to execute '%s', %w", q, err)
+		}
+	}
+
+	err = tx.Commit()
+
+	if err != nil {
+		return fmt.Errorf("Failed to commit transaction, %w", err)
+	}
+
+	return nil
+}
+
+// newRow() returns a `map[string]string` row, with "id", "label" and "source" keys, derived from 'data'.
+func newRow(data interface{}) (map[string]string, error) {
+
+	var row map[string]string
+
+	switch r := data.(type) {
+	case *lcsh.SubjectHeading:
+		row = map[string]string{"id": r.Id, "label": r.Label, "source": lcsh.SOURCE}
+	case *lcnaf.NamedAuthority:
+		row = map[string]string{"id": r.Id, "label": r.Label, "source": lcnaf.SOURCE}
+	case libraryofcongress.Record:
+		row = map[string]string{"id": r.GetId(), "label": r.GetLabel(), "source": r.GetSource()}
+	case map[string]string:
+		row = map[string]string{"id": r["id"], "label": r["label"], "source": r["source"]}
+	default:
+		return nil, fmt.Errorf("Unsupported record type %T", data)
+	}
+
+	for _, k := range []string{"id", "label", "source"} {
+
+		if row[k] == "" {
+			return nil, fmt.Errorf("Record is missing %s", k)
+		}
+	}
+
+	// Ensure that records can be read back from the database
+
+	_, err := newRecord(row["id"], row["label"], row["source"])
+
+	if err != nil {
+		return nil, err
+	}
+
+	return row, nil
+}
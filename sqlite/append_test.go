@@ -0,0 +1,139 @@
+package sqlite
+
+import (
+	"context"
+	loc_tables "github.com/sfomuseum/go-libraryofcongress-database/sqlite/tables"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcnaf"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcsh"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/sqlite/tables"
+	"path/filepath"
+	"testing"
+)
+
+func TestSQLiteLookupAppend(t *testing.T) {
+
+	ctx := context.Background()
+
+	dsn := filepath.Join(t.TempDir(), "append.db")
+
+	db, err := NewDB(ctx, dsn, "")
+
+	if err != nil {
+		t.Fatalf("Failed to create database, %v", err)
+	}
+
+	defer db.Close()
+
+	normalize, err := libraryofcongress.NewNormalizeFunc("casefold,subdivisions")
+
+	if err != nil {
+		t.Fatalf("Failed to create normalizer, %v", err)
+	}
+
+	_, err = loc_tables.NewIdentifiersTableWithDatabase(ctx, db)
+
+	if err != nil {
+		t.Fatalf("Failed to create identifiers table, %v", err)
+	}
+
+	_, err = tables.NewSearchTableWithDatabase(ctx, db)
+
+	if err != nil {
+		t.Fatalf("Failed to create search table, %v", err)
+	}
+
+	_, err = tables.NewNormalizedTableWithDatabase(ctx, db, normalize)
+
+	if err != nil {
+		t.Fatalf("Failed to create normalized table, %v", err)
+	}
+
+	opts := &SQLiteLookupOptions{
+		Normalize: normalize,
+	}
+
+	l, err := NewSQLiteLookupWithDatabaseAndOptions(ctx, db, opts)
+
+	if err != nil {
+		t.Fatalf("Failed to create new lookup, %v", err)
+	}
+
+	records := []interface{}{
+		&lcsh.SubjectHeading{Id: "sfom0001", Label: "Airports--Provisional"},
+		&lcnaf.NamedAuthority{Id: "sfom0002", Label: "San Francisco International Airport"},
+		map[string]string{"id": "sfom0003", "label": "Airport museums", "source": lcsh.SOURCE},
+	}
+
+	for _, r := range records {
+
+		err := l.Append(ctx, r)
+
+		if err != nil {
+			t.Fatalf("Failed to append %v, %v", r, err)
+		}
+	}
+
+	tests := map[string]string{
+		"airports -- provisional":             "sfom0001",
+		"San Francisco International Airport": "sfom0002",
+		"AIRPORT MUSEUMS":                     "sfom0003",
+	}
+
+	for code, expected := range tests {
+
+		rsp, err := l.Find(ctx, code)
+
+		if err != nil {
+			t.Fatalf("Failed to find '%s', %v", code, err)
+		}
+
+		if len(rsp) != 1 || rsp[0].(libraryofcongress.Record).GetId() != expected {
+			t.Fatalf("Unexpected results for '%s': %v", code, rsp)
+		}
+	}
+
+	// Appending a record with an existing identifier replaces it
+
+	err = l.Append(ctx, &lcsh.SubjectHeading{Id: "sfom0003", Label: "Aviation museums"})
+
+	if err != nil {
+		t.Fatalf("Failed to replace record, %v", err)
+	}
+
+	_, err = l.Find(ctx, "Airport museums")
+
+	if !libraryofcongress.IsNotFound(err) {
+		t.Fatalf("Expected replaced label to be not found, got %v", err)
+	}
+
+	search_l := l.(*SQLiteLookup)
+	search_l.mode = MODE_SEARCH
+
+	rsp, err := search_l.Find(ctx, "museums")
+
+	if err != nil {
+		t.Fatalf("Failed to search for museums, %v", err)
+	}
+
+	if len(rsp) != 1 || rsp[0].(libraryofcongress.Record).GetLabel() != "Aviation museums" {
+		t.Fatalf("Unexpected search results, %v", rsp)
+	}
+
+	// Invalid records
+
+	invalid := []interface{}{
+		"sfom0004",
+		map[string]string{"id": "sfom0004", "label": "Missing source"},
+		map[string]string{"id": "sfom0004", "label": "Unknown source", "source": "tgm"},
+	}
+
+	for _, r := range invalid {
+
+		err := l.Append(ctx, r)
+
+		if err == nil {
+			t.Fatalf("Expected append of %v to fail", r)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"github.com/aaronland/go-sqlite/database"
+	_ "modernc.org/sqlite"
+)
+
+// CGO_DRIVER is the name of the cgo-based (mattn/go-sqlite3) SQLite database driver. Full-text search requires that
+// it be compiled with the `fts5` build tag.
+const CGO_DRIVER string = "sqlite3"
+
+// PUREGO_DRIVER is the name of the pure-Go (modernc.org/sqlite) SQLite database driver. It does not require cgo and
+// always includes support for full-text search.
+const PUREGO_DRIVER string = "sqlite"
+
+// DRIVER_PARAMETER is the name of the query parameter, in `sqlite://` lookup URIs, used to select the SQLite database driver.
+const DRIVER_PARAMETER string = "driver"
+
+// NewDB() returns a new `aaronland/go-sqlite/database.SQLiteDatabase` instance for 'dsn' using the SQLite database driver
+// named 'driver'. Valid drivers are `CGO_DRIVER` and `PUREGO_DRIVER`. If 'driver' is empty then `DEFAULT_DRIVER` is used.
+func NewDB(ctx context.Context, dsn string, driver string) (*database.SQLiteDatabase, error) {
+
+	if driver == "" {
+		driver = DEFAULT_DRIVER
+	}
+
+	switch driver {
+	case CGO_DRIVER, PUREGO_DRIVER:
+		// pass
+	default:
+		return nil, fmt.Errorf("Unsupported driver, %s", driver)
+	}
+
+	db, err := database.NewDBWithDriver(ctx, driver, dsn)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create database using %s driver, %w", driver, err)
+	}
+
+	return db, nil
+}
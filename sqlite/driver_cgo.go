@@ -0,0 +1,7 @@
+//go:build cgo && !purego
+
+package sqlite
+
+// DEFAULT_DRIVER is the SQLite database driver used when one is not specified. When compiled with cgo enabled, and
+// without the `purego` build tag, it is `CGO_DRIVER`.
+const DEFAULT_DRIVER string = CGO_DRIVER
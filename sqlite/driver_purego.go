@@ -0,0 +1,7 @@
+//go:build !cgo || purego
+
+package sqlite
+
+// DEFAULT_DRIVER is the SQLite database driver used when one is not specified. When compiled with cgo disabled, or
+// with the `purego` build tag, it is `PUREGO_DRIVER`.
+const DEFAULT_DRIVER string = PUREGO_DRIVER
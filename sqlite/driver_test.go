@@ -0,0 +1,99 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"github.com/aaronland/go-sqlite"
+	loc_tables "github.com/sfomuseum/go-libraryofcongress-database/sqlite/tables"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcsh"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/sqlite/tables"
+	"path/filepath"
+	"testing"
+)
+
+func TestPureGoDriver(t *testing.T) {
+
+	ctx := context.Background()
+
+	dsn := filepath.Join(t.TempDir(), "purego.db")
+
+	db, err := NewDB(ctx, dsn, PUREGO_DRIVER)
+
+	if err != nil {
+		t.Fatalf("Failed to create database, %v", err)
+	}
+
+	identifiers_table, err := loc_tables.NewIdentifiersTableWithDatabase(ctx, db)
+
+	if err != nil {
+		t.Fatalf("Failed to create identifiers table, %v", err)
+	}
+
+	search_table, err := tables.NewSearchTableWithDatabase(ctx, db)
+
+	if err != nil {
+		t.Fatalf("Failed to create search table, %v", err)
+	}
+
+	rows := []map[string]string{
+		map[string]string{"id": "sh85002782", "label": "Airplanes"},
+		map[string]string{"id": "sh85002783", "label": "Airplanes--Accidents"},
+		map[string]string{"id": "sh2010007517", "label": "Cooking"},
+	}
+
+	for _, row := range rows {
+
+		row["source"] = lcsh.SOURCE
+
+		for _, tbl := range []sqlite.Table{identifiers_table, search_table} {
+
+			err := tbl.IndexRecord(ctx, db, row)
+
+			if err != nil {
+				t.Fatalf("Failed to index %v in %s table, %v", row, tbl.Name(), err)
+			}
+		}
+	}
+
+	err = db.Close()
+
+	if err != nil {
+		t.Fatalf("Failed to close database, %v", err)
+	}
+
+	tests := map[string]int{
+		fmt.Sprintf("sqlite://%s?driver=%s", dsn, PUREGO_DRIVER):             1,
+		fmt.Sprintf("sqlite://%s?driver=%s&mode=search", dsn, PUREGO_DRIVER): 2,
+	}
+
+	for uri, expected := range tests {
+
+		l, err := libraryofcongress.NewLookup(ctx, uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create lookup for %s, %v", uri, err)
+		}
+
+		rsp, err := l.Find(ctx, "Airplanes")
+
+		if err != nil {
+			t.Fatalf("Failed to find 'Airplanes' using %s, %v", uri, err)
+		}
+
+		if len(rsp) != expected {
+			t.Fatalf("Expected %d results using %s, got %d", expected, uri, len(rsp))
+		}
+	}
+}
+
+func TestNewDBUnsupportedDriver(t *testing.T) {
+
+	ctx := context.Background()
+
+	_, err := NewDB(ctx, filepath.Join(t.TempDir(), "unsupported.db"), "postgres")
+
+	if err == nil {
+		t.Fatalf("Expected unsupported driver to fail")
+	}
+}
@@ -27,7 +27,7 @@ func NewIndentifiersDatabase(ctx context.Context, dsn string, data_uris map[stri
 	// Some or all of this code should be reconciled with cmd/to-sqlite but not today...
 	// (20220708/thisisaaronland)
 
-	sqlite_db, err := database.NewDB(ctx, dsn)
+	sqlite_db, err := NewDB(ctx, dsn, "")
 
 	if err != nil {
 		return nil, fmt.Errorf("Failed to create new database, %v", err)
@@ -0,0 +1,70 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+)
+
+// Iterate() invokes 'cb' for each record in the identifiers table (restricted to the lookup's source, if defined).
+// Records are returned in no particular order.
+func (l *SQLiteLookup) Iterate(ctx context.Context, cb libraryofcongress.IterateFunc) error {
+
+	q := "SELECT id, label, source FROM identifiers"
+	args := make([]interface{}, 0)
+
+	if l.source != "" {
+		q = fmt.Sprintf("%s WHERE source = ?", q)
+		args = append(args, l.source)
+	}
+
+	h, release := l.acquire()
+	defer release()
+
+	conn, err := h.db.Conn()
+
+	if err != nil {
+		return fmt.Errorf("Failed to establish database connection, %w", err)
+	}
+
+	rows, err := conn.QueryContext(ctx, q, args...)
+
+	if err != nil {
+		return fmt.Errorf("Failed to query database, %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+
+		var id string
+		var label string
+		var source string
+
+		err := rows.Scan(&id, &label, &source)
+
+		if err != nil {
+			return fmt.Errorf("Failed to scan database row, %w", err)
+		}
+
+		r, err := newRecord(id, label, source)
+
+		if err != nil {
+			return err
+		}
+
+		err = cb(ctx, r.(libraryofcongress.Record))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return fmt.Errorf("Database reported an error, %w", err)
+	}
+
+	return nil
+}
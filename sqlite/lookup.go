@@ -8,13 +8,56 @@ import (
 	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
 	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcnaf"
 	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcsh"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/sqlite/tables"
 	"net/url"
-	"strings"
+	"strconv"
+	"sync"
 )
 
+// MODE_LABEL is the default lookup mode which matches codes against (normalized) labels.
+const MODE_LABEL string = "label"
+
+// MODE_SEARCH is a lookup mode which performs a full-text search for codes using the `search` table.
+const MODE_SEARCH string = "search"
+
+// DEFAULT_LIMIT is the default maximum number of results returned by the `Find` method when using `MODE_SEARCH`.
+const DEFAULT_LIMIT int64 = 10
+
 type SQLiteLookup struct {
 	libraryofcongress.Lookup
-	db *database.SQLiteDatabase
+	// mu guards 'handle' which is replaced when the lookup is reloaded.
+	mu        *sync.RWMutex
+	handle    *handle
+	normalize libraryofcongress.NormalizeFunc
+	mode      string
+	limit     int64
+	source    string
+}
+
+// type handle is a struct containing a SQLite database, and the properties derived from it, used by a `SQLiteLookup`
+// instance. Handles are replaced when the lookup is reloaded.
+type handle struct {
+	db             *database.SQLiteDatabase
+	has_normalized bool
+	has_search     bool
+	// has_search_rowids is a boolean flag indicating whether the database has a `tables.SEARCH_ROWIDS_TABLE_NAME` table.
+	has_search_rowids bool
+	// wg tracks the operations using the handle so that its database is only closed once they have completed.
+	wg *sync.WaitGroup
+}
+
+// type SQLiteLookupOptions defines configuration options for `SQLiteLookup` instances.
+type SQLiteLookupOptions struct {
+	// Normalize is the function used to normalize the codes used to query labels. If the database contains a
+	// `tables.NormalizedTable` table then this should be the same function that was used to populate that table.
+	Normalize libraryofcongress.NormalizeFunc
+	// Mode is the lookup mode used by the `Find` method. Valid options are `MODE_LABEL` and `MODE_SEARCH`. Default is `MODE_LABEL`.
+	Mode string
+	// Limit is the maximum number of results returned by the `Find` method when using `MODE_SEARCH`. Default is `DEFAULT_LIMIT`.
+	Limit int64
+	// Source is the (optional) name of the LoC data source to restrict results to. Valid options are "lcsh" and "lcnaf".
+	// If empty results from all the sources in the database are returned.
+	Source string
 }
 
 func init() {
@@ -32,17 +75,109 @@ func NewSQLiteLookup(ctx context.Context, uri string) (libraryofcongress.Lookup,
 
 	dsn := u.Path
 
-	db, err := database.NewDB(ctx, dsn)
+	q := u.Query()
+
+	normalize, err := libraryofcongress.NewNormalizeFunc(q.Get(libraryofcongress.NORMALIZE_PARAMETER))
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create normalizer, %w", err)
+	}
+
+	opts := &SQLiteLookupOptions{
+		Normalize: normalize,
+		Mode:      q.Get("mode"),
+		Source:    q.Get("source"),
+	}
+
+	if q.Has("limit") {
+
+		limit, err := strconv.ParseInt(q.Get("limit"), 10, 64)
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid ?limit= parameter, %w", err)
+		}
+
+		opts.Limit = limit
+	}
+
+	db, err := NewDB(ctx, dsn, q.Get(DRIVER_PARAMETER))
 
 	if err != nil {
 		return nil, fmt.Errorf("Failed to create database, %w", err)
 	}
 
-	return NewSQLiteLookupWithDatabase(ctx, db)
+	return NewSQLiteLookupWithDatabaseAndOptions(ctx, db, opts)
 }
 
 func NewSQLiteLookupWithDatabase(ctx context.Context, db *database.SQLiteDatabase) (libraryofcongress.Lookup, error) {
 
+	opts := &SQLiteLookupOptions{
+		Normalize: libraryofcongress.NormalizeSubdivisions,
+	}
+
+	return NewSQLiteLookupWithDatabaseAndOptions(ctx, db, opts)
+}
+
+// NewSQLiteLookupWithDatabaseAndOptions() returns a new `SQLiteLookup` instance for 'db' configured using 'opts'.
+func NewSQLiteLookupWithDatabaseAndOptions(ctx context.Context, db *database.SQLiteDatabase, opts *SQLiteLookupOptions) (libraryofcongress.Lookup, error) {
+
+	h, err := newHandle(ctx, db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	normalize := opts.Normalize
+
+	if normalize == nil {
+		normalize = libraryofcongress.NoNormalize
+	}
+
+	mode := opts.Mode
+
+	switch mode {
+	case "":
+		mode = MODE_LABEL
+	case MODE_LABEL:
+		// pass
+	case MODE_SEARCH:
+
+		if !h.has_search {
+			return nil, fmt.Errorf("Database is missing %s table", SEARCH_TABLE_NAME)
+		}
+
+	default:
+		return nil, fmt.Errorf("Invalid mode '%s'", mode)
+	}
+
+	switch opts.Source {
+	case "", lcsh.SOURCE, lcnaf.SOURCE:
+		// pass
+	default:
+		return nil, fmt.Errorf("Invalid source '%s'", opts.Source)
+	}
+
+	limit := opts.Limit
+
+	if limit <= 0 {
+		limit = DEFAULT_LIMIT
+	}
+
+	l := &SQLiteLookup{
+		mu:        new(sync.RWMutex),
+		handle:    h,
+		normalize: normalize,
+		mode:      mode,
+		limit:     limit,
+		source:    opts.Source,
+	}
+
+	return l, nil
+}
+
+// newHandle() returns a new `handle` instance for 'db'. It returns an error if 'db' does not have an identifiers table.
+func newHandle(ctx context.Context, db *database.SQLiteDatabase) (*handle, error) {
+
 	exists, err := sqlite.HasTable(ctx, db, "identifiers")
 
 	if err != nil {
@@ -53,24 +188,155 @@ func NewSQLiteLookupWithDatabase(ctx context.Context, db *database.SQLiteDatabas
 		return nil, fmt.Errorf("Database is missing identifiers table")
 	}
 
-	l := &SQLiteLookup{
-		db: db,
+	has_normalized, err := sqlite.HasTable(ctx, db, tables.NORMALIZED_TABLE_NAME)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to determine whether normalized table exists, %w", err)
 	}
 
-	return l, nil
+	has_search, err := sqlite.HasTable(ctx, db, SEARCH_TABLE_NAME)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to determine whether search table exists, %w", err)
+	}
+
+	has_search_rowids, err := sqlite.HasTable(ctx, db, tables.SEARCH_ROWIDS_TABLE_NAME)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to determine whether search rowids table exists, %w", err)
+	}
+
+	h := &handle{
+		db:                db,
+		has_normalized:    has_normalized,
+		has_search:        has_search,
+		has_search_rowids: has_search_rowids,
+		wg:                new(sync.WaitGroup),
+	}
+
+	return h, nil
+}
+
+// acquire() returns the handle currently used by the lookup and a function which must be called when the caller has
+// finished using it.
+func (l *SQLiteLookup) acquire() (*handle, func()) {
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	h := l.handle
+	h.wg.Add(1)
+
+	return h, h.wg.Done
 }
 
+// Find() returns the records whose (normalized) labels match 'code' or, if the lookup mode is `MODE_SEARCH`, the
+// records matching a full-text search for 'code'. Like the other lookups, if there are no matching labels then the
+// records whose identifier is 'code' are returned. If there are no matching records then a "not found" error is returned
+// which matches `libraryofcongress.ErrNotFound` when tested using `errors.Is`. If the lookup is restricted to a single
+// source that error will be `lcsh.NotFound` or `lcnaf.NotFound` so that code written against those lookups continues to
+// work; otherwise it will be `libraryofcongress.NotFound`.
 func (l *SQLiteLookup) Find(ctx context.Context, code string) ([]interface{}, error) {
 
-	conn, err := l.db.Conn()
+	h, release := l.acquire()
+	defer release()
+
+	rsp, err := l.find(ctx, h, code)
 
 	if err != nil {
-		return nil, fmt.Errorf("Failed to establish database connection, %w", err)
+		return nil, err
+	}
+
+	if len(rsp) == 0 {
+
+		rsp, err = l.findId(ctx, h, code)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(rsp) == 0 {
+		return nil, l.notFound(code)
+	}
+
+	return rsp, nil
+}
+
+func (l *SQLiteLookup) find(ctx context.Context, h *handle, code string) ([]interface{}, error) {
+
+	if l.mode == MODE_SEARCH {
+		return l.findWithSearch(ctx, h, code)
 	}
 
+	if h.has_normalized {
+		q := fmt.Sprintf("SELECT id, label, source FROM %s WHERE normalized = ?", tables.NORMALIZED_TABLE_NAME)
+		return l.queryWithSource(ctx, h, q, l.normalize(code))
+	}
+
+	// Databases without a normalized table only store the original labels so query those first and
+	// then try again with the normalized code (for example to account for the difference in syntax
+	// between SFOM and LoC subdivisions).
+
 	q := "SELECT id, label, source FROM identifiers WHERE label = ?"
 
-	rows, err := conn.QueryContext(ctx, q, code)
+	rsp, err := l.queryWithSource(ctx, h, q, code)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rsp) == 0 {
+
+		normalized := l.normalize(code)
+
+		if normalized != code {
+			return l.queryWithSource(ctx, h, q, normalized)
+		}
+	}
+
+	return rsp, nil
+}
+
+// findId() returns the records whose identifier is 'id' (for the lookup's source, if defined) in the identifiers table.
+func (l *SQLiteLookup) findId(ctx context.Context, h *handle, id string) ([]interface{}, error) {
+	return l.queryWithSource(ctx, h, "SELECT id, label, source FROM identifiers WHERE id = ?", id)
+}
+
+// notFound() returns a "not found" error for 'code' specific to the source the lookup is restricted to, if any.
+func (l *SQLiteLookup) notFound(code string) error {
+
+	switch l.source {
+	case lcsh.SOURCE:
+		return lcsh.NotFound{Code: code}
+	case lcnaf.SOURCE:
+		return lcnaf.NotFound{Code: code}
+	default:
+		return libraryofcongress.NotFound{Code: code}
+	}
+}
+
+// queryWithSource() appends a "source" constraint to 'q' if the lookup is restricted to a single source
+// before executing it.
+func (l *SQLiteLookup) queryWithSource(ctx context.Context, h *handle, q string, args ...interface{}) ([]interface{}, error) {
+
+	if l.source != "" {
+		q = fmt.Sprintf("%s AND source = ?", q)
+		args = append(args, l.source)
+	}
+
+	return l.query(ctx, h, q, args...)
+}
+
+func (l *SQLiteLookup) query(ctx context.Context, h *handle, q string, args ...interface{}) ([]interface{}, error) {
+
+	conn, err := h.db.Conn()
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to establish database connection, %w", err)
+	}
+
+	rows, err := conn.QueryContext(ctx, q, args...)
 
 	if err != nil {
 		return nil, fmt.Errorf("Failed to query database, %w", err)
@@ -92,28 +358,13 @@ func (l *SQLiteLookup) Find(ctx context.Context, code string) ([]interface{}, er
 			return nil, fmt.Errorf("Failed to scan database row, %w", err)
 		}
 
-		switch source {
-		case "lcnaf":
-
-			r := &lcnaf.NamedAuthority{
-				Id:    id,
-				Label: label,
-			}
+		r, err := newRecord(id, label, source)
 
-			rsp = append(rsp, r)
-
-		case "lcsh":
-
-			r := &lcsh.SubjectHeading{
-				Id:    id,
-				Label: label,
-			}
-
-			rsp = append(rsp, r)
-
-		default:
-			return nil, fmt.Errorf("Unsupported source, %s", source)
+		if err != nil {
+			return nil, err
 		}
+
+		rsp = append(rsp, r)
 	}
 
 	err = rows.Close()
@@ -128,21 +379,32 @@ func (l *SQLiteLookup) Find(ctx context.Context, code string) ([]interface{}, er
 		return nil, fmt.Errorf("Database reported an error, %w", err)
 	}
 
-	if len(rsp) == 0 {
+	return rsp, nil
+}
 
-		// START OF hack to account for the difference in syntax between SFOM and LoC
+// newRecord() returns a new LoC record for 'id' and 'label' whose type is determined by 'source'.
+func newRecord(id string, label string, source string) (interface{}, error) {
 
-		if strings.Contains(code, " -- ") {
-			code = strings.Replace(code, " -- ", "--", -1)
-			return l.Find(ctx, code)
+	switch source {
+	case lcnaf.SOURCE:
+
+		r := &lcnaf.NamedAuthority{
+			Id:    id,
+			Label: label,
 		}
 
-		// END OF hack to account for the difference in syntax between SFOM and LoC
-	}
+		return r, nil
 
-	return rsp, nil
-}
+	case lcsh.SOURCE:
 
-func (l *SQLiteLookup) Append(ctx context.Context, data interface{}) error {
-	return fmt.Errorf("Not implemented.")
+		r := &lcsh.SubjectHeading{
+			Id:    id,
+			Label: label,
+		}
+
+		return r, nil
+
+	default:
+		return nil, fmt.Errorf("Unsupported source, %s", source)
+	}
 }
@@ -2,10 +2,14 @@ package sqlite
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/aaronland/go-sqlite"
+	loc_tables "github.com/sfomuseum/go-libraryofcongress-database/sqlite/tables"
 	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
 	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcnaf"
 	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcsh"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/sqlite/tables"
 	"path/filepath"
 	"testing"
 )
@@ -23,7 +27,7 @@ func TestSQLiteLookup(t *testing.T) {
 
 	lookup_uri := fmt.Sprintf("sqlite://%s", abs_path)
 
-	l, err := libraryofcongress.NewLookup(ctx, lookup_uri)
+	l, err := libraryofcongress.NewTypedLookup[libraryofcongress.Record](ctx, lookup_uri)
 
 	if err != nil {
 		t.Fatalf("Failed to create new lookup, %v", err)
@@ -55,10 +59,13 @@ func TestSQLiteLookup(t *testing.T) {
 		}
 
 		for idx, r := range rsp {
-			sh := r.(*lcsh.SubjectHeading)
 
-			if sh.Id != expected_ids[idx] {
-				t.Fatalf("Unexpected ID for '%s': %s", label, sh.Id)
+			if r.GetSource() != lcsh.SOURCE {
+				t.Fatalf("Unexpected source for '%s': %s", label, r.GetSource())
+			}
+
+			if r.GetId() != expected_ids[idx] {
+				t.Fatalf("Unexpected ID for '%s': %s", label, r.GetId())
 			}
 		}
 	}
@@ -77,12 +84,237 @@ func TestSQLiteLookup(t *testing.T) {
 
 		for idx, r := range rsp {
 
-			na := r.(*lcnaf.NamedAuthority)
+			if r.GetSource() != lcnaf.SOURCE {
+				t.Fatalf("Unexpected source for '%s': %s", label, r.GetSource())
+			}
 
-			if na.Id != expected_ids[idx] {
-				t.Fatalf("Unexpected ID for '%s': %s", label, na.Id)
+			if r.GetId() != expected_ids[idx] {
+				t.Fatalf("Unexpected ID for '%s': %s", label, r.GetId())
 			}
 		}
 	}
 
 }
+
+func TestSQLiteLookupNormalized(t *testing.T) {
+
+	ctx := context.Background()
+
+	dsn := filepath.Join(t.TempDir(), "normalized.db")
+
+	db, err := NewDB(ctx, dsn, "")
+
+	if err != nil {
+		t.Fatalf("Failed to create database, %v", err)
+	}
+
+	defer db.Close()
+
+	normalize, err := libraryofcongress.NewNormalizeFunc("casefold,diacritics,whitespace,period,subdivisions")
+
+	if err != nil {
+		t.Fatalf("Failed to create normalizer, %v", err)
+	}
+
+	identifiers_table, err := loc_tables.NewIdentifiersTableWithDatabase(ctx, db)
+
+	if err != nil {
+		t.Fatalf("Failed to create identifiers table, %v", err)
+	}
+
+	normalized_table, err := tables.NewNormalizedTableWithDatabase(ctx, db, normalize)
+
+	if err != nil {
+		t.Fatalf("Failed to create normalized table, %v", err)
+	}
+
+	rows := []map[string]string{
+		map[string]string{"id": "sh2007100714", "label": "Aeronautics--Popular works", "source": lcsh.SOURCE},
+		map[string]string{"id": "n2003099999", "label": "Halstenberg, Friedrich", "source": lcnaf.SOURCE},
+		map[string]string{"id": "n79099999", "label": "Brockmann, Lester C.", "source": lcnaf.SOURCE},
+	}
+
+	for _, row := range rows {
+
+		for _, tbl := range []sqlite.Table{identifiers_table, normalized_table} {
+
+			err := tbl.IndexRecord(ctx, db, row)
+
+			if err != nil {
+				t.Fatalf("Failed to index %v in %s table, %v", row, tbl.Name(), err)
+			}
+		}
+	}
+
+	opts := &SQLiteLookupOptions{
+		Normalize: normalize,
+	}
+
+	l, err := NewSQLiteLookupWithDatabaseAndOptions(ctx, db, opts)
+
+	if err != nil {
+		t.Fatalf("Failed to create new lookup, %v", err)
+	}
+
+	tests := map[string]string{
+		"aeronautics -- popular works": "Aeronautics--Popular works",
+		"HALSTENBERG,  Friedrich.":     "Halstenberg, Friedrich",
+		"Brockmann, Lester C":          "Brockmann, Lester C.",
+	}
+
+	for code, expected := range tests {
+
+		rsp, err := l.Find(ctx, code)
+
+		if err != nil {
+			t.Fatalf("Failed to find '%s', %v", code, err)
+		}
+
+		if len(rsp) != 1 {
+			t.Fatalf("Unexpected results for '%s': %v", code, rsp)
+		}
+
+		r := rsp[0].(libraryofcongress.Record)
+
+		if r.GetLabel() != expected {
+			t.Fatalf("Unexpected label for '%s', expected '%s' but got '%s'", code, expected, r.GetLabel())
+		}
+	}
+}
+
+func TestSQLiteLookupId(t *testing.T) {
+
+	ctx := context.Background()
+
+	rel_path := "../fixtures/libraryofcongress.db"
+	abs_path, err := filepath.Abs(rel_path)
+
+	if err != nil {
+		t.Fatalf("Failed to derive absolute path for %s, %v", rel_path, err)
+	}
+
+	l, err := libraryofcongress.NewTypedLookup[libraryofcongress.Record](ctx, fmt.Sprintf("sqlite://%s", abs_path))
+
+	if err != nil {
+		t.Fatalf("Failed to create new lookup, %v", err)
+	}
+
+	tests := map[string]string{
+		"sh00000011":   "ActionScript (Computer program language)",
+		"n2003099999":  "Halstenberg, Friedrich",
+		"sh2007100714": "Aeronautics--Popular works",
+	}
+
+	for id, expected := range tests {
+
+		rsp, err := l.Find(ctx, id)
+
+		if err != nil {
+			t.Fatalf("Failed to find '%s', %v", id, err)
+		}
+
+		if len(rsp) != 1 || rsp[0].GetId() != id || rsp[0].GetLabel() != expected {
+			t.Fatalf("Unexpected results for '%s', %v", id, rsp)
+		}
+	}
+
+	// Source constraints
+
+	lcnaf_l, err := libraryofcongress.NewLookup(ctx, fmt.Sprintf("sqlite://%s?source=lcnaf", abs_path))
+
+	if err != nil {
+		t.Fatalf("Failed to create new lookup, %v", err)
+	}
+
+	_, err = lcnaf_l.Find(ctx, "sh00000011")
+
+	if !lcnaf.IsNotFound(err) {
+		t.Fatalf("Expected LCSH identifier to be excluded from lcnaf-only lookup, got %v", err)
+	}
+}
+
+func TestSQLiteLookupNotFound(t *testing.T) {
+
+	ctx := context.Background()
+
+	rel_path := "../fixtures/libraryofcongress.db"
+	abs_path, err := filepath.Abs(rel_path)
+
+	if err != nil {
+		t.Fatalf("Failed to derive absolute path for %s, %v", rel_path, err)
+	}
+
+	tests := map[string]func(error) bool{
+		fmt.Sprintf("sqlite://%s", abs_path):              libraryofcongress.IsNotFound,
+		fmt.Sprintf("sqlite://%s?source=lcsh", abs_path):  lcsh.IsNotFound,
+		fmt.Sprintf("sqlite://%s?source=lcnaf", abs_path): lcnaf.IsNotFound,
+	}
+
+	for lookup_uri, is_not_found := range tests {
+
+		l, err := libraryofcongress.NewLookup(ctx, lookup_uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create new lookup for %s, %v", lookup_uri, err)
+		}
+
+		_, err = l.Find(ctx, "This subject heading does not exist")
+
+		if !is_not_found(err) {
+			t.Fatalf("Expected source-specific not found error for %s, got %v", lookup_uri, err)
+		}
+
+		if !errors.Is(err, libraryofcongress.ErrNotFound) {
+			t.Fatalf("Expected error to match ErrNotFound for %s, got %v", lookup_uri, err)
+		}
+	}
+
+	// Source constraints
+
+	l, err := libraryofcongress.NewLookup(ctx, fmt.Sprintf("sqlite://%s?source=lcnaf", abs_path))
+
+	if err != nil {
+		t.Fatalf("Failed to create new lookup, %v", err)
+	}
+
+	_, err = l.Find(ctx, "Cooking")
+
+	if !lcnaf.IsNotFound(err) {
+		t.Fatalf("Expected LCSH record to be excluded from lcnaf-only lookup, got %v", err)
+	}
+}
+
+func TestSQLiteLookupIterate(t *testing.T) {
+
+	ctx := context.Background()
+
+	rel_path := "../fixtures/libraryofcongress.db"
+	abs_path, err := filepath.Abs(rel_path)
+
+	if err != nil {
+		t.Fatalf("Failed to derive absolute path for %s, %v", rel_path, err)
+	}
+
+	sources := make(map[string]int)
+
+	cb := func(ctx context.Context, r libraryofcongress.Record) error {
+		sources[r.GetSource()] += 1
+		return nil
+	}
+
+	l, err := libraryofcongress.NewLookup(ctx, fmt.Sprintf("sqlite://%s?source=lcnaf", abs_path))
+
+	if err != nil {
+		t.Fatalf("Failed to create new lookup, %v", err)
+	}
+
+	err = libraryofcongress.Iterate(ctx, l, cb)
+
+	if err != nil {
+		t.Fatalf("Failed to iterate records, %v", err)
+	}
+
+	if sources[lcnaf.SOURCE] == 0 || sources[lcsh.SOURCE] != 0 {
+		t.Fatalf("Unexpected sources, %v", sources)
+	}
+}
@@ -0,0 +1,115 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/sqlite/tables"
+)
+
+// Remove() removes the record whose identifier is 'id' from the identifiers table and, if present, the search and
+// normalized tables. If there is no matching record a "not found" error is returned.
+func (l *SQLiteLookup) Remove(ctx context.Context, id string) error {
+
+	h, release := l.acquire()
+	defer release()
+
+	err := l.ensureExists(ctx, h, id)
+
+	if err != nil {
+		return err
+	}
+
+	return l.execStatements(ctx, h, l.removeStatements(h, id))
+}
+
+// Update() replaces the record whose identifier matches the identifier of 'data' with 'data', for example when
+// the label for a heading has been changed. If there is no matching record a "not found" error is returned.
+func (l *SQLiteLookup) Update(ctx context.Context, data interface{}) error {
+
+	row, err := newRow(data)
+
+	if err != nil {
+		return err
+	}
+
+	if l.source != "" && row["source"] != l.source {
+		return fmt.Errorf("Record source '%s' does not match lookup source '%s'", row["source"], l.source)
+	}
+
+	h, release := l.acquire()
+	defer release()
+
+	err = l.ensureExists(ctx, h, row["id"])
+
+	if err != nil {
+		return err
+	}
+
+	return l.execStatements(ctx, h, l.appendStatements(h, row))
+}
+
+// Replace() removes the record whose identifier is 'id' and adds 'data' in its place, in a single transaction, for
+// example when a heading has been deprecated in favour of a new heading. If there is no record matching 'id' a
+// "not found" error is returned.
+func (l *SQLiteLookup) Replace(ctx context.Context, id string, data interface{}) error {
+
+	row, err := newRow(data)
+
+	if err != nil {
+		return err
+	}
+
+	if l.source != "" && row["source"] != l.source {
+		return fmt.Errorf("Record source '%s' does not match lookup source '%s'", row["source"], l.source)
+	}
+
+	h, release := l.acquire()
+	defer release()
+
+	err = l.ensureExists(ctx, h, id)
+
+	if err != nil {
+		return err
+	}
+
+	statements := l.removeStatements(h, id)
+	statements = append(statements, l.appendStatements(h, row)...)
+
+	return l.execStatements(ctx, h, statements)
+}
+
+// removeStatements() returns the list of SQL statements, and their arguments, necessary to remove the record whose
+// identifier is 'id' from the identifiers table and, if present, the search and normalized tables.
+func (l *SQLiteLookup) removeStatements(h *handle, id string) [][]interface{} {
+
+	statements := [][]interface{}{
+		[]interface{}{"DELETE FROM identifiers WHERE id = ?", id},
+	}
+
+	if h.has_search {
+		statements = append(statements, tables.SearchRemoveStatements(id, h.has_search_rowids)...)
+	}
+
+	if h.has_normalized {
+		statements = append(statements, []interface{}{fmt.Sprintf("DELETE FROM %s WHERE id = ?", tables.NORMALIZED_TABLE_NAME), id})
+	}
+
+	return statements
+}
+
+// ensureExists() returns a "not found" error if there is no record whose identifier is 'id' (for the lookup's source,
+// if defined) in the identifiers table.
+func (l *SQLiteLookup) ensureExists(ctx context.Context, h *handle, id string) error {
+
+	rsp, err := l.findId(ctx, h, id)
+
+	if err != nil {
+		return err
+	}
+
+	if len(rsp) == 0 {
+		return l.notFound(id)
+	}
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package sqlite
+
+import (
+	"context"
+	loc_tables "github.com/sfomuseum/go-libraryofcongress-database/sqlite/tables"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcnaf"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcsh"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/sqlite/tables"
+	"path/filepath"
+	"testing"
+)
+
+func TestSQLiteLookupMutable(t *testing.T) {
+
+	ctx := context.Background()
+
+	dsn := filepath.Join(t.TempDir(), "mutable.db")
+
+	db, err := NewDB(ctx, dsn, "")
+
+	if err != nil {
+		t.Fatalf("Failed to create database, %v", err)
+	}
+
+	defer db.Close()
+
+	_, err = loc_tables.NewIdentifiersTableWithDatabase(ctx, db)
+
+	if err != nil {
+		t.Fatalf("Failed to create identifiers table, %v", err)
+	}
+
+	_, err = tables.NewSearchTableWithDatabase(ctx, db)
+
+	if err != nil {
+		t.Fatalf("Failed to create search table, %v", err)
+	}
+
+	l, err := NewSQLiteLookupWithDatabase(ctx, db)
+
+	if err != nil {
+		t.Fatalf("Failed to create new lookup, %v", err)
+	}
+
+	m, ok := l.(libraryofcongress.MutableLookup)
+
+	if !ok {
+		t.Fatalf("Lookup does not implement MutableLookup")
+	}
+
+	err = m.Append(ctx, &lcsh.SubjectHeading{Id: "sh85002782", Label: "Airplanes"})
+
+	if err != nil {
+		t.Fatalf("Failed to append record, %v", err)
+	}
+
+	err = m.Update(ctx, &lcsh.SubjectHeading{Id: "sh85002782", Label: "Aeroplanes"})
+
+	if err != nil {
+		t.Fatalf("Failed to update record, %v", err)
+	}
+
+	_, err = m.Find(ctx, "Airplanes")
+
+	if !libraryofcongress.IsNotFound(err) {
+		t.Fatalf("Expected old label to be not found, got %v", err)
+	}
+
+	err = m.Replace(ctx, "sh85002782", &lcsh.SubjectHeading{Id: "sh00000001", Label: "Aeroplanes"})
+
+	if err != nil {
+		t.Fatalf("Failed to replace record, %v", err)
+	}
+
+	rsp, err := m.Find(ctx, "Aeroplanes")
+
+	if err != nil || len(rsp) != 1 || rsp[0].(libraryofcongress.Record).GetId() != "sh00000001" {
+		t.Fatalf("Unexpected results for replacement, %v %v", rsp, err)
+	}
+
+	err = m.Remove(ctx, "sh00000001")
+
+	if err != nil {
+		t.Fatalf("Failed to remove record, %v", err)
+	}
+
+	_, err = m.Find(ctx, "Aeroplanes")
+
+	if !libraryofcongress.IsNotFound(err) {
+		t.Fatalf("Expected removed record to be not found, got %v", err)
+	}
+
+	for _, err := range []error{
+		m.Remove(ctx, "sh00000001"),
+		m.Update(ctx, &lcsh.SubjectHeading{Id: "sh00000001", Label: "Aeroplanes"}),
+		m.Replace(ctx, "sh00000001", &lcsh.SubjectHeading{Id: "sh00000002", Label: "Aeroplanes"}),
+	} {
+
+		if !libraryofcongress.IsNotFound(err) {
+			t.Fatalf("Expected changes to a missing record to fail with NotFound, got %v", err)
+		}
+	}
+
+	var count int
+
+	conn, _ := db.Conn()
+	conn.QueryRowContext(ctx, "SELECT COUNT(id) FROM search").Scan(&count)
+
+	if count != 0 {
+		t.Fatalf("Expected search table to be empty, got %d rows", count)
+	}
+}
+
+func TestSQLiteLookupMutableSource(t *testing.T) {
+
+	ctx := context.Background()
+
+	dsn := filepath.Join(t.TempDir(), "mutable.db")
+
+	db, err := NewDB(ctx, dsn, "")
+
+	if err != nil {
+		t.Fatalf("Failed to create database, %v", err)
+	}
+
+	defer db.Close()
+
+	_, err = loc_tables.NewIdentifiersTableWithDatabase(ctx, db)
+
+	if err != nil {
+		t.Fatalf("Failed to create identifiers table, %v", err)
+	}
+
+	opts := &SQLiteLookupOptions{
+		Source: lcsh.SOURCE,
+	}
+
+	l, err := NewSQLiteLookupWithDatabaseAndOptions(ctx, db, opts)
+
+	if err != nil {
+		t.Fatalf("Failed to create new lookup, %v", err)
+	}
+
+	m := l.(libraryofcongress.MutableLookup)
+
+	err = m.Append(ctx, &lcsh.SubjectHeading{Id: "sh85002782", Label: "Airplanes"})
+
+	if err != nil {
+		t.Fatalf("Failed to append record, %v", err)
+	}
+
+	for _, err := range []error{
+		m.Append(ctx, &lcnaf.NamedAuthority{Id: "n00000001", Label: "Airplanes"}),
+		m.Update(ctx, &lcnaf.NamedAuthority{Id: "sh85002782", Label: "Aeroplanes"}),
+		m.Replace(ctx, "sh85002782", &lcnaf.NamedAuthority{Id: "n00000001", Label: "Aeroplanes"}),
+	} {
+
+		if err == nil {
+			t.Fatalf("Expected changes using a record from a different source to fail")
+		}
+	}
+
+	rsp, err := m.Find(ctx, "sh85002782")
+
+	if err != nil || len(rsp) != 1 || rsp[0].(*lcsh.SubjectHeading).Label != "Airplanes" {
+		t.Fatalf("Expected sh85002782 to be unchanged, %v %v", rsp, err)
+	}
+}
@@ -0,0 +1,63 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+)
+
+// Reload() opens the SQLite database for 'uri', which takes the same form as the URIs used to create `SQLiteLookup`
+// instances, and atomically replaces the lookup's current database with it. The new database is expected to have been
+// built off to the side, for example using the `to-sqlite` tool. The lookup's normalizer, mode, limit and source are
+// not changed; only the 'driver' query parameter in 'uri' is used. Queries in progress when the databases are swapped
+// continue to use the previous database which is closed once they have completed. If the new database can not be opened,
+// or is missing the tables required by the lookup, then an error is returned and the current database remains in use.
+func (l *SQLiteLookup) Reload(ctx context.Context, uri string) error {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	// Opening a database which doesn't exist creates an empty one so check first
+
+	_, err = os.Stat(u.Path)
+
+	if err != nil {
+		return fmt.Errorf("Failed to stat database, %w", err)
+	}
+
+	db, err := NewDB(ctx, u.Path, u.Query().Get(DRIVER_PARAMETER))
+
+	if err != nil {
+		return fmt.Errorf("Failed to create database, %w", err)
+	}
+
+	h, err := newHandle(ctx, db)
+
+	if err != nil {
+		db.Close()
+		return err
+	}
+
+	if l.mode == MODE_SEARCH && !h.has_search {
+		db.Close()
+		return fmt.Errorf("Database is missing %s table", SEARCH_TABLE_NAME)
+	}
+
+	l.mu.Lock()
+
+	previous := l.handle
+	l.handle = h
+
+	l.mu.Unlock()
+
+	go func() {
+		previous.wg.Wait()
+		previous.db.Close()
+	}()
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	loc_tables "github.com/sfomuseum/go-libraryofcongress-database/sqlite/tables"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcsh"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSQLiteLookupReload(t *testing.T) {
+
+	ctx := context.Background()
+
+	// newDatabase() creates a new database, with an identifiers table, at 'dsn' containing 'records'
+
+	newDatabase := func(dsn string, records ...*lcsh.SubjectHeading) {
+
+		db, err := NewDB(ctx, dsn, "")
+
+		if err != nil {
+			t.Fatalf("Failed to create database, %v", err)
+		}
+
+		defer db.Close()
+
+		_, err = loc_tables.NewIdentifiersTableWithDatabase(ctx, db)
+
+		if err != nil {
+			t.Fatalf("Failed to create identifiers table, %v", err)
+		}
+
+		l, err := NewSQLiteLookupWithDatabase(ctx, db)
+
+		if err != nil {
+			t.Fatalf("Failed to create new lookup, %v", err)
+		}
+
+		for _, r := range records {
+
+			err := l.Append(ctx, r)
+
+			if err != nil {
+				t.Fatalf("Failed to append %v, %v", r, err)
+			}
+		}
+	}
+
+	dir := t.TempDir()
+
+	current_dsn := filepath.Join(dir, "current.db")
+	next_dsn := filepath.Join(dir, "next.db")
+
+	newDatabase(current_dsn, &lcsh.SubjectHeading{Id: "sfom0001", Label: "Airports"})
+	newDatabase(next_dsn, &lcsh.SubjectHeading{Id: "sfom0002", Label: "Airport museums"})
+
+	l, err := libraryofcongress.NewLookup(ctx, fmt.Sprintf("sqlite://%s", current_dsn))
+
+	if err != nil {
+		t.Fatalf("Failed to create new lookup, %v", err)
+	}
+
+	_, err = l.Find(ctx, "Airports")
+
+	if err != nil {
+		t.Fatalf("Failed to find Airports, %v", err)
+	}
+
+	// Simulate a query which is still in progress when the lookup is reloaded
+
+	sqlite_l := l.(*SQLiteLookup)
+	previous, release := sqlite_l.acquire()
+
+	err = libraryofcongress.Reload(ctx, l, fmt.Sprintf("sqlite://%s", next_dsn))
+
+	if err != nil {
+		t.Fatalf("Failed to reload lookup, %v", err)
+	}
+
+	_, err = l.Find(ctx, "Airport museums")
+
+	if err != nil {
+		t.Fatalf("Failed to find Airport museums after reload, %v", err)
+	}
+
+	_, err = l.Find(ctx, "Airports")
+
+	if !libraryofcongress.IsNotFound(err) {
+		t.Fatalf("Expected Airports to not be found after reload, %v", err)
+	}
+
+	rsp, err := sqlite_l.query(ctx, previous, "SELECT id, label, source FROM identifiers WHERE label = ?", "Airports")
+
+	if err != nil || len(rsp) != 1 {
+		t.Fatalf("Expected in-progress query to use the previous database, %v", err)
+	}
+
+	release()
+
+	// The previous database is closed once the in-progress query has completed
+
+	closed := false
+
+	for i := 0; i < 100; i++ {
+
+		conn, _ := previous.db.Conn()
+
+		if conn.PingContext(ctx) != nil {
+			closed = true
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if !closed {
+		t.Fatalf("Expected previous database to be closed")
+	}
+
+	// Failed reloads leave the current database in place
+
+	err = sqlite_l.Reload(ctx, fmt.Sprintf("sqlite://%s", filepath.Join(dir, "missing.db")))
+
+	if err == nil {
+		t.Fatalf("Expected an error reloading a missing database")
+	}
+
+	_, err = l.Find(ctx, "Airport museums")
+
+	if err != nil {
+		t.Fatalf("Failed to find Airport museums after failed reload, %v", err)
+	}
+}
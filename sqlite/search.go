@@ -0,0 +1,208 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"github.com/aaronland/go-pagination"
+	"github.com/aaronland/go-pagination/countable"
+	"github.com/aaronland/go-sqlite"
+	loc_database "github.com/sfomuseum/go-libraryofcongress-database"
+	loc_sqlite "github.com/sfomuseum/go-libraryofcongress-database/sqlite"
+	loc_tables "github.com/sfomuseum/go-libraryofcongress-database/sqlite/tables"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/sqlite/tables"
+	"github.com/sfomuseum/go-timings"
+)
+
+// SEARCH_TABLE_NAME is the name of the SQLite (FTS) database table used for full-text searches.
+const SEARCH_TABLE_NAME string = tables.SEARCH_TABLE_NAME
+
+// Search() performs a full-text search for 'q', which may contain any valid SQLite FTS MATCH syntax, against the
+// labels in the `search` table returning results paginated according to 'pg_opts'. Results are ranked as follows:
+// Exact (case-sensitive) matches first, followed by labels that start with 'q' and then all other labels; each of
+// these groups is further sorted by label length and then alphabetically.
+func (l *SQLiteLookup) Search(ctx context.Context, q string, pg_opts pagination.Options) ([]*loc_database.QueryResult, pagination.Results, error) {
+
+	h, release := l.acquire()
+	defer release()
+
+	return l.search(ctx, h, q, q, pg_opts)
+}
+
+// search() performs a full-text search for 'match', which may contain any valid SQLite FTS MATCH syntax, using the
+// database in 'h' and ranks the results using 'q'. See `Search` for details.
+func (l *SQLiteLookup) search(ctx context.Context, h *handle, match string, q string, pg_opts pagination.Options) ([]*loc_database.QueryResult, pagination.Results, error) {
+
+	if !h.has_search {
+		return nil, nil, fmt.Errorf("Database is missing %s table", SEARCH_TABLE_NAME)
+	}
+
+	if pg_opts.Method() != pagination.Countable {
+		return nil, nil, fmt.Errorf("Unsupported pagination method")
+	}
+
+	conn, err := h.db.Conn()
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to establish database connection, %w", err)
+	}
+
+	where := "label MATCH ?"
+	where_args := []interface{}{match}
+
+	if l.source != "" {
+		where = fmt.Sprintf("%s AND source = ?", where)
+		where_args = append(where_args, l.source)
+	}
+
+	var total int64
+
+	count_q := fmt.Sprintf("SELECT COUNT(id) FROM %s WHERE %s", SEARCH_TABLE_NAME, where)
+
+	row := conn.QueryRowContext(ctx, count_q, where_args...)
+	err = row.Scan(&total)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to count search results for '%s', %w", q, err)
+	}
+
+	pg, err := countable.NewResultsFromCountWithOptions(pg_opts, total)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to derive pagination results, %w", err)
+	}
+
+	per_page := pg.PerPage()
+	offset := (pg.Page() - 1) * per_page
+
+	search_q := fmt.Sprintf(`SELECT id, label, source FROM %s WHERE %s
+		ORDER BY CASE WHEN label = ? THEN 0 WHEN label LIKE ? THEN 1 ELSE 2 END, LENGTH(label), label
+		LIMIT %d OFFSET %d`, SEARCH_TABLE_NAME, where, per_page, offset)
+
+	search_args := append(where_args, q, q+"%")
+
+	rows, err := conn.QueryContext(ctx, search_q, search_args...)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to query database, %w", err)
+	}
+
+	defer rows.Close()
+
+	results := make([]*loc_database.QueryResult, 0)
+
+	for rows.Next() {
+
+		var id string
+		var label string
+		var source string
+
+		err := rows.Scan(&id, &label, &source)
+
+		if err != nil {
+			return nil, nil, fmt.Errorf("Failed to scan database row, %w", err)
+		}
+
+		r := &loc_database.QueryResult{
+			Id:     id,
+			Label:  label,
+			Source: source,
+		}
+
+		results = append(results, r)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("Database reported an error, %w", err)
+	}
+
+	return results, pg, nil
+}
+
+// Query() is an alias for `Search` and, along with the `Index` method, allows `SQLiteLookup` instances to be used
+// with the `sfomuseum/go-libraryofcongress-database.QueryPaginated` method.
+func (l *SQLiteLookup) Query(ctx context.Context, q string, pg_opts pagination.Options) ([]*loc_database.QueryResult, pagination.Results, error) {
+	return l.Search(ctx, q, pg_opts)
+}
+
+// Index() indexes 'sources' in all of the identifiers, search and normalized tables present in the underlying database.
+func (l *SQLiteLookup) Index(ctx context.Context, sources []*loc_database.Source, monitor timings.Monitor) error {
+
+	h, release := l.acquire()
+	defer release()
+
+	db_tables := make([]sqlite.Table, 0)
+
+	identifiers_table, err := loc_tables.NewIdentifiersTable(ctx)
+
+	if err != nil {
+		return fmt.Errorf("Failed to create identifiers table, %w", err)
+	}
+
+	db_tables = append(db_tables, identifiers_table)
+
+	if h.has_search {
+
+		search_table, err := tables.NewSearchTable(ctx)
+
+		if err != nil {
+			return fmt.Errorf("Failed to create search table, %w", err)
+		}
+
+		db_tables = append(db_tables, search_table)
+	}
+
+	if h.has_normalized {
+
+		normalized_table, err := tables.NewNormalizedTable(ctx, l.normalize)
+
+		if err != nil {
+			return fmt.Errorf("Failed to create normalized table, %w", err)
+		}
+
+		db_tables = append(db_tables, normalized_table)
+	}
+
+	return loc_sqlite.Index(ctx, sources, h.db, db_tables, monitor)
+}
+
+// findWithSearch() returns the records matching a full-text search for 'code' using the database in 'h'. Unlike `Search`
+// 'code' is treated as a label, rather than a query, so any FTS MATCH syntax it contains is ignored.
+func (l *SQLiteLookup) findWithSearch(ctx context.Context, h *handle, code string) ([]interface{}, error) {
+
+	terms := tables.SearchTerms(code)
+
+	if terms == "" {
+		return []interface{}{}, nil
+	}
+
+	pg_opts, err := countable.NewCountableOptions()
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create pagination options, %w", err)
+	}
+
+	pg_opts.PerPage(l.limit)
+
+	results, _, err := l.search(ctx, h, terms, code, pg_opts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := make([]interface{}, len(results))
+
+	for idx, r := range results {
+
+		rec, err := newRecord(r.Id, r.Label, r.Source)
+
+		if err != nil {
+			return nil, err
+		}
+
+		rsp[idx] = rec
+	}
+
+	return rsp, nil
+}
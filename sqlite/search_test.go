@@ -0,0 +1,341 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"github.com/aaronland/go-pagination/countable"
+	"github.com/aaronland/go-sqlite"
+	loc_database "github.com/sfomuseum/go-libraryofcongress-database"
+	loc_tables "github.com/sfomuseum/go-libraryofcongress-database/sqlite/tables"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/lcsh"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/sqlite/tables"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newSearchLookup() returns a new `SQLiteLookup` instance using `MODE_SEARCH` for a database containing 'rows'.
+func newSearchLookup(t *testing.T, rows []map[string]string) libraryofcongress.Lookup {
+
+	ctx := context.Background()
+
+	dsn := filepath.Join(t.TempDir(), "search.db")
+
+	db, err := NewDB(ctx, dsn, "")
+
+	if err != nil {
+		t.Fatalf("Failed to create database, %v", err)
+	}
+
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	identifiers_table, err := loc_tables.NewIdentifiersTableWithDatabase(ctx, db)
+
+	if err != nil {
+		t.Fatalf("Failed to create identifiers table, %v", err)
+	}
+
+	search_table, err := tables.NewSearchTableWithDatabase(ctx, db)
+
+	if err != nil {
+		t.Fatalf("Failed to create search table, %v", err)
+	}
+
+	for _, row := range rows {
+
+		row["source"] = lcsh.SOURCE
+
+		for _, tbl := range []sqlite.Table{identifiers_table, search_table} {
+
+			err := tbl.IndexRecord(ctx, db, row)
+
+			if err != nil {
+				t.Fatalf("Failed to index %v in %s table, %v", row, tbl.Name(), err)
+			}
+		}
+	}
+
+	opts := &SQLiteLookupOptions{
+		Mode: MODE_SEARCH,
+	}
+
+	l, err := NewSQLiteLookupWithDatabaseAndOptions(ctx, db, opts)
+
+	if err != nil {
+		t.Fatalf("Failed to create new lookup, %v", err)
+	}
+
+	return l
+}
+
+func TestSQLiteLookupSearch(t *testing.T) {
+
+	ctx := context.Background()
+
+	rows := []map[string]string{
+		map[string]string{"id": "sh85015277", "label": "Boeing airplanes"},
+		map[string]string{"id": "sh85002782", "label": "Airplanes"},
+		map[string]string{"id": "sh85002783", "label": "Airplanes--Accidents"},
+		map[string]string{"id": "sh85002784", "label": "Airplanes--Design and construction"},
+		map[string]string{"id": "sh2010007517", "label": "Cooking"},
+	}
+
+	l := newSearchLookup(t, rows)
+
+	rsp, err := l.Find(ctx, "Airplanes")
+
+	if err != nil {
+		t.Fatalf("Failed to find 'Airplanes', %v", err)
+	}
+
+	expected := []string{"sh85002782", "sh85002783", "sh85002784", "sh85015277"}
+
+	if len(rsp) != len(expected) {
+		t.Fatalf("Unexpected results: %v", rsp)
+	}
+
+	for idx, r := range rsp {
+
+		id := r.(libraryofcongress.Record).GetId()
+
+		if id != expected[idx] {
+			t.Fatalf("Unexpected ranking at position %d, expected '%s' but got '%s'", idx, expected[idx], id)
+		}
+	}
+
+	pg_opts, err := countable.NewCountableOptions()
+
+	if err != nil {
+		t.Fatalf("Failed to create pagination options, %v", err)
+	}
+
+	pg_opts.PerPage(1)
+
+	count := 0
+
+	cb := func(ctx context.Context, results []*loc_database.QueryResult) error {
+
+		if len(results) != 1 {
+			return fmt.Errorf("Unexpected page size: %d", len(results))
+		}
+
+		count += len(results)
+		return nil
+	}
+
+	err = loc_database.QueryPaginated(ctx, l.(*SQLiteLookup), "airplanes", pg_opts, cb)
+
+	if err != nil {
+		t.Fatalf("Failed to query paginated results, %v", err)
+	}
+
+	if count != len(expected) {
+		t.Fatalf("Unexpected number of paginated results: %d", count)
+	}
+}
+
+func TestSQLiteLookupSearchPunctuation(t *testing.T) {
+
+	ctx := context.Background()
+
+	rows := []map[string]string{
+		map[string]string{"id": "sh00000011", "label": "ActionScript (Computer program language)"},
+		map[string]string{"id": "sh00000016", "label": "Trail Creek (Jackson County, Or.)"},
+		map[string]string{"id": "sh85002790", "label": "Airplanes--Motors"},
+		map[string]string{"id": "sh85118553", "label": "Science and technology"},
+		map[string]string{"id": "sh85002791", "label": "Airplanes--\"Hush kits\""},
+	}
+
+	l := newSearchLookup(t, rows)
+
+	tests := map[string]string{
+		"ActionScript (Computer program language)": "sh00000011",
+		"Trail Creek (Jackson County, Or.)":        "sh00000016",
+		"Airplanes--Motors":                        "sh85002790",
+		"Airplanes -- Motors":                      "sh85002790",
+		"AND":                                      "sh85118553",
+		"science AND NOT technology":               "",
+		`Airplanes--"Hush kits"`:                   "sh85002791",
+	}
+
+	for code, expected := range tests {
+
+		rsp, err := l.Find(ctx, code)
+
+		if expected == "" {
+
+			if !libraryofcongress.IsNotFound(err) {
+				t.Fatalf("Expected not found error for '%s', got %v", code, err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("Failed to find '%s', %v", code, err)
+		}
+
+		id := rsp[0].(libraryofcongress.Record).GetId()
+
+		if id != expected {
+			t.Fatalf("Unexpected first result for '%s', expected '%s' but got '%s'", code, expected, id)
+		}
+	}
+
+	for _, code := range []string{"", "--", "()"} {
+
+		_, err := l.Find(ctx, code)
+
+		if !libraryofcongress.IsNotFound(err) {
+			t.Fatalf("Expected not found error for '%s', got %v", code, err)
+		}
+	}
+
+	// The Search method still accepts FTS MATCH syntax
+
+	pg_opts, err := countable.NewCountableOptions()
+
+	if err != nil {
+		t.Fatalf("Failed to create pagination options, %v", err)
+	}
+
+	results, _, err := l.(*SQLiteLookup).Search(ctx, "airplanes NOT motors", pg_opts)
+
+	if err != nil {
+		t.Fatalf("Failed to search, %v", err)
+	}
+
+	if len(results) != 1 || results[0].Id != "sh85002791" {
+		t.Fatalf("Unexpected search results, %v", results)
+	}
+}
+
+func TestSearchTableReplace(t *testing.T) {
+
+	ctx := context.Background()
+
+	dsn := filepath.Join(t.TempDir(), "replace.db")
+
+	db, err := NewDB(ctx, dsn, "")
+
+	if err != nil {
+		t.Fatalf("Failed to create database, %v", err)
+	}
+
+	defer db.Close()
+
+	conn, err := db.Conn()
+
+	if err != nil {
+		t.Fatalf("Failed to connect to database, %v", err)
+	}
+
+	// A search table created before the search rowids table existed
+
+	legacy := []string{
+		"CREATE VIRTUAL TABLE search USING fts5(id UNINDEXED, source UNINDEXED, label)",
+		"INSERT INTO search (id, source, label) VALUES ('sh85002782', 'lcsh', 'Airplanes')",
+	}
+
+	for _, q := range legacy {
+
+		_, err := conn.Exec(q)
+
+		if err != nil {
+			t.Fatalf("Failed to execute '%s', %v", q, err)
+		}
+	}
+
+	search_table, err := tables.NewSearchTableWithDatabase(ctx, db)
+
+	if err != nil {
+		t.Fatalf("Failed to create search table, %v", err)
+	}
+
+	rows := []map[string]string{
+		map[string]string{"id": "sh85002782", "label": "Aeroplanes", "source": lcsh.SOURCE},
+		map[string]string{"id": "sh2010007517", "label": "Cooking", "source": lcsh.SOURCE},
+		map[string]string{"id": "sh2010007517", "label": "Cookery", "source": lcsh.SOURCE},
+	}
+
+	for _, row := range rows {
+
+		err := search_table.IndexRecord(ctx, db, row)
+
+		if err != nil {
+			t.Fatalf("Failed to index %v, %v", row, err)
+		}
+	}
+
+	tests := map[string]int{
+		"Airplanes":  0,
+		"Aeroplanes": 1,
+		"Cooking":    0,
+		"Cookery":    1,
+	}
+
+	for label, expected := range tests {
+
+		var count int
+
+		err := conn.QueryRow("SELECT COUNT(id) FROM search WHERE label MATCH ?", tables.SearchTerms(label)).Scan(&count)
+
+		if err != nil {
+			t.Fatalf("Failed to count rows for '%s', %v", label, err)
+		}
+
+		if count != expected {
+			t.Fatalf("Expected %d rows for '%s', got %d", expected, label, count)
+		}
+	}
+
+	var count int
+
+	err = conn.QueryRow("SELECT COUNT(*) FROM search_rowids JOIN search ON search.rowid = search_rowids.search_rowid AND search.id = search_rowids.id").Scan(&count)
+
+	if err != nil {
+		t.Fatalf("Failed to count search rowids, %v", err)
+	}
+
+	if count != 2 {
+		t.Fatalf("Expected 2 search rowids, got %d", count)
+	}
+
+	// Rows are deleted by rowid rather than by scanning the search table
+
+	q := tables.SearchRemoveStatements("sh85002782", true)[0][0].(string)
+
+	plan_rows, err := conn.Query("EXPLAIN QUERY PLAN "+q, "sh85002782")
+
+	if err != nil {
+		t.Fatalf("Failed to explain '%s', %v", q, err)
+	}
+
+	defer plan_rows.Close()
+
+	plan := make([]string, 0)
+
+	for plan_rows.Next() {
+
+		var id, parent, notused int
+		var detail string
+
+		err := plan_rows.Scan(&id, &parent, &notused, &detail)
+
+		if err != nil {
+			t.Fatalf("Failed to scan query plan, %v", err)
+		}
+
+		plan = append(plan, detail)
+	}
+
+	for _, detail := range plan {
+
+		if strings.HasPrefix(detail, "SCAN search_rowids") || (strings.HasPrefix(detail, "SCAN search") && !strings.Contains(detail, "VIRTUAL TABLE INDEX 0:=")) {
+			t.Fatalf("Unexpected table scan deleting rows, %v", plan)
+		}
+	}
+}
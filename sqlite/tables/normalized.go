@@ -0,0 +1,145 @@
+package tables
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"github.com/aaronland/go-sqlite"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+)
+
+//go:embed normalized.schema
+var normalized_schema string
+
+// NORMALIZED_TABLE_NAME is the name of the SQLite database table containing normalized labels.
+const NORMALIZED_TABLE_NAME string = "normalized"
+
+// type NormalizedTable implements the `sqlite.Table` interface for mapping normalized labels to their corresponding LoC identifiers
+// and (original) labels.
+type NormalizedTable struct {
+	sqlite.Table
+	// The name of the SQLite database table containing normalized labels.
+	name string
+	// The function used to normalize labels.
+	normalize libraryofcongress.NormalizeFunc
+}
+
+// NewNormalizedTableWithDatabase() returns a new `NormalizedTable` instance for use with the database identifier by 'db'.
+func NewNormalizedTableWithDatabase(ctx context.Context, db sqlite.Database, normalize libraryofcongress.NormalizeFunc) (sqlite.Table, error) {
+
+	t, err := NewNormalizedTable(ctx, normalize)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create normalized table, %w", err)
+	}
+
+	err = t.InitializeTable(ctx, db)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to initialize normalized table, %w", err)
+	}
+
+	return t, nil
+}
+
+// NewNormalizedTable() returns a new `NormalizedTable` instance which will normalize labels using 'normalize'.
+func NewNormalizedTable(ctx context.Context, normalize libraryofcongress.NormalizeFunc) (sqlite.Table, error) {
+
+	t := NormalizedTable{
+		name:      NORMALIZED_TABLE_NAME,
+		normalize: normalize,
+	}
+
+	return &t, nil
+}
+
+// InitializeTable() will ensure the a normalized table has been created in the database represented by 'db'.
+func (t *NormalizedTable) InitializeTable(ctx context.Context, db sqlite.Database) error {
+	return sqlite.CreateTableIfNecessary(ctx, db, t)
+}
+
+// Name() returns the name of the normalized table.
+func (t *NormalizedTable) Name() string {
+	return t.name
+}
+
+// Schema() returns the schema used to create the normalized table.
+func (t *NormalizedTable) Schema() string {
+	return normalized_schema
+}
+
+// IndexRecord() indexes 'i' in the database represented by 'db'.
+func (t *NormalizedTable) IndexRecord(ctx context.Context, db sqlite.Database, i interface{}) error {
+	return t.IndexRow(ctx, db, i.(map[string]string))
+}
+
+// IndexRow() indexes 'row' in the database represented by 'db'.
+func (t *NormalizedTable) IndexRow(ctx context.Context, db sqlite.Database, row map[string]string) error {
+
+	sql := fmt.Sprintf(`INSERT INTO %s (
+		id, source, label, normalized
+		) VALUES (
+		?, ?, ?, ?
+		)`, t.Name())
+
+	args := []interface{}{
+		row["id"],
+		row["source"],
+		row["label"],
+		t.normalize(row["label"]),
+	}
+
+	conn, err := db.Conn()
+
+	if err != nil {
+		return fmt.Errorf("Failed to connect to database, %w", err)
+	}
+
+	tx, err := conn.Begin()
+
+	if err != nil {
+		return fmt.Errorf("Failed to begin transaction, %w", err)
+	}
+
+	// Mirror the "INSERT OR REPLACE" semantics of the identifiers table
+
+	s, err := tx.Prepare(fmt.Sprintf("DELETE FROM %s WHERE id = ?", t.Name()))
+
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("Failed to prepare statement, %w", err)
+	}
+
+	defer s.Close()
+
+	_, err = s.Exec(row["id"])
+
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("Failed to delete rows for ID %s, %w", row["id"], err)
+	}
+
+	stmt, err := tx.Prepare(sql)
+
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("Failed to prepare statement, %w", err)
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.Exec(args...)
+
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("Failed to execute statement, %w", err)
+	}
+
+	err = tx.Commit()
+
+	if err != nil {
+		return fmt.Errorf("Failed to commit transaction, %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,262 @@
+package tables
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"github.com/aaronland/go-sqlite"
+	"strings"
+	"sync"
+)
+
+//go:embed search.schema
+var search_schema string
+
+// SEARCH_TABLE_NAME is the name of the SQLite (FTS) database table used for full-text searches.
+const SEARCH_TABLE_NAME string = "search"
+
+// SEARCH_ROWIDS_TABLE_NAME is the name of the SQLite database table mapping identifiers to the rowids of their rows
+// in the search table. FTS tables can only be queried efficiently by rowid (or full-text search) so this table is used
+// to replace and remove the rows for an identifier without scanning the entire search table.
+const SEARCH_ROWIDS_TABLE_NAME string = "search_rowids"
+
+// type SearchTable implements the `sqlite.Table` interface for full-text searches of LoC labels using the SQLite FTS5
+// extension. Unlike the (FTS4) `sfomuseum/go-libraryofcongress-database/sqlite/tables.SearchTable` table it can be used
+// with both the cgo (compiled with the `fts5` build tag) and pure-Go SQLite database drivers.
+type SearchTable struct {
+	sqlite.Table
+	// The name of the SQLite database table containing searchable labels.
+	name string
+	// has_rowids is a boolean flag indicating whether the database has a `SEARCH_ROWIDS_TABLE_NAME` table.
+	has_rowids bool
+	// init is used to determine whether the database has a `SEARCH_ROWIDS_TABLE_NAME` table the first time a row is indexed.
+	init     *sync.Once
+	init_err error
+}
+
+// SearchTerms() returns 'q' as a list of quoted full-text search terms so that reserved characters and words (for
+// example "--", "." or "AND") in LoC labels are not interpreted as FTS5 query syntax.
+func SearchTerms(q string) string {
+
+	terms := make([]string, 0)
+
+	for _, t := range strings.FieldsFunc(q, isSeparator) {
+		terms = append(terms, fmt.Sprintf(`"%s"`, strings.ReplaceAll(t, `"`, `""`)))
+	}
+
+	return strings.Join(terms, " ")
+}
+
+// isSeparator() returns a boolean value indicating whether 'r' separates the terms in a search query.
+func isSeparator(r rune) bool {
+
+	switch r {
+	case ' ', '\t', '-', ',', '.', '(', ')', '"':
+		return true
+	default:
+		return false
+	}
+}
+
+// NewSearchTableWithDatabase() returns a new `SearchTable` instance for use with the database identifier by 'db'.
+func NewSearchTableWithDatabase(ctx context.Context, db sqlite.Database) (sqlite.Table, error) {
+
+	t, err := NewSearchTable(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create search table, %w", err)
+	}
+
+	err = t.InitializeTable(ctx, db)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to initialize search table, %w", err)
+	}
+
+	return t, nil
+}
+
+// NewSearchTable() returns a new `SearchTable` instance.
+func NewSearchTable(ctx context.Context) (sqlite.Table, error) {
+
+	t := SearchTable{
+		name: SEARCH_TABLE_NAME,
+		init: new(sync.Once),
+	}
+
+	return &t, nil
+}
+
+// InitializeTable() will ensure the a search table, and its `SEARCH_ROWIDS_TABLE_NAME` table, has been created in the
+// database represented by 'db'. If the search table was created without a `SEARCH_ROWIDS_TABLE_NAME` table then one is
+// created and populated from the search table.
+func (t *SearchTable) InitializeTable(ctx context.Context, db sqlite.Database) error {
+
+	has_search, err := sqlite.HasTable(ctx, db, t.Name())
+
+	if err != nil {
+		return fmt.Errorf("Failed to determine whether search table exists, %w", err)
+	}
+
+	if !has_search {
+		return sqlite.CreateTableIfNecessary(ctx, db, t)
+	}
+
+	has_rowids, err := sqlite.HasTable(ctx, db, SEARCH_ROWIDS_TABLE_NAME)
+
+	if err != nil {
+		return fmt.Errorf("Failed to determine whether search rowids table exists, %w", err)
+	}
+
+	if has_rowids {
+		return nil
+	}
+
+	conn, err := db.Conn()
+
+	if err != nil {
+		return fmt.Errorf("Failed to connect to database, %w", err)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+
+	if err != nil {
+		return fmt.Errorf("Failed to begin transaction, %w", err)
+	}
+
+	statements := []string{
+		fmt.Sprintf("CREATE TABLE %s (id TEXT PRIMARY KEY, search_rowid INTEGER)", SEARCH_ROWIDS_TABLE_NAME),
+		fmt.Sprintf("INSERT OR REPLACE INTO %s (id, search_rowid) SELECT id, rowid FROM %s", SEARCH_ROWIDS_TABLE_NAME, t.Name()),
+	}
+
+	for _, q := range statements {
+
+		_, err := tx.ExecContext(ctx, q)
+
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("Failed to execute '%s', %w", q, err)
+		}
+	}
+
+	err = tx.Commit()
+
+	if err != nil {
+		return fmt.Errorf("Failed to commit transaction, %w", err)
+	}
+
+	return nil
+}
+
+// SearchReplaceStatements() returns the list of SQL statements, and their arguments, necessary to replace the rows
+// in the search table for the identifier in 'row' with 'row'. If 'has_rowids' is false, because the database was
+// created without a `SEARCH_ROWIDS_TABLE_NAME` table, then existing rows are found by scanning the search table.
+func SearchReplaceStatements(row map[string]string, has_rowids bool) [][]interface{} {
+
+	statements := SearchRemoveStatements(row["id"], has_rowids)
+
+	statements = append(statements, []interface{}{
+		fmt.Sprintf("INSERT INTO %s (id, source, label) VALUES (?, ?, ?)", SEARCH_TABLE_NAME),
+		row["id"], row["source"], row["label"],
+	})
+
+	if has_rowids {
+
+		statements = append(statements, []interface{}{
+			fmt.Sprintf("INSERT OR REPLACE INTO %s (id, search_rowid) VALUES (?, last_insert_rowid())", SEARCH_ROWIDS_TABLE_NAME),
+			row["id"],
+		})
+	}
+
+	return statements
+}
+
+// SearchRemoveStatements() returns the list of SQL statements, and their arguments, necessary to remove the rows in
+// the search table for 'id'. If 'has_rowids' is false, because the database was created without a `SEARCH_ROWIDS_TABLE_NAME`
+// table, then the rows are found by scanning the search table.
+func SearchRemoveStatements(id string, has_rowids bool) [][]interface{} {
+
+	if !has_rowids {
+
+		statements := [][]interface{}{
+			[]interface{}{fmt.Sprintf("DELETE FROM %s WHERE id = ?", SEARCH_TABLE_NAME), id},
+		}
+
+		return statements
+	}
+
+	statements := [][]interface{}{
+		[]interface{}{
+			fmt.Sprintf("DELETE FROM %s WHERE rowid = (SELECT search_rowid FROM %s WHERE id = ?)", SEARCH_TABLE_NAME, SEARCH_ROWIDS_TABLE_NAME),
+			id,
+		},
+		[]interface{}{
+			fmt.Sprintf("DELETE FROM %s WHERE id = ?", SEARCH_ROWIDS_TABLE_NAME),
+			id,
+		},
+	}
+
+	return statements
+}
+
+// Name() returns the name of the search table.
+func (t *SearchTable) Name() string {
+	return t.name
+}
+
+// Schema() returns the schema used to create the search table.
+func (t *SearchTable) Schema() string {
+	return search_schema
+}
+
+// IndexRecord() indexes 'i' in the database represented by 'db'.
+func (t *SearchTable) IndexRecord(ctx context.Context, db sqlite.Database, i interface{}) error {
+	return t.IndexRow(ctx, db, i.(map[string]string))
+}
+
+// IndexRow() indexes 'row' in the database represented by 'db'.
+func (t *SearchTable) IndexRow(ctx context.Context, db sqlite.Database, row map[string]string) error {
+
+	t.init.Do(func() {
+		t.has_rowids, t.init_err = sqlite.HasTable(ctx, db, SEARCH_ROWIDS_TABLE_NAME)
+	})
+
+	if t.init_err != nil {
+		return fmt.Errorf("Failed to determine whether search rowids table exists, %w", t.init_err)
+	}
+
+	conn, err := db.Conn()
+
+	if err != nil {
+		return fmt.Errorf("Failed to connect to database, %w", err)
+	}
+
+	tx, err := conn.Begin()
+
+	if err != nil {
+		return fmt.Errorf("Failed to begin transaction, %w", err)
+	}
+
+	// FTS tables do not have primary keys so mirror the "INSERT OR REPLACE" semantics of the identifiers table. Existing
+	// rows are found using the search rowids table, rather than scanning the search table, so that indexing is not quadratic.
+
+	for _, st := range SearchReplaceStatements(row, t.has_rowids) {
+
+		q := st[0].(string)
+
+		_, err := tx.Exec(q, st[1:]...)
+
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("Failed to execute '%s', %w", q, err)
+		}
+	}
+
+	err = tx.Commit()
+
+	if err != nil {
+		return fmt.Errorf("Failed to commit transaction, %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,2 @@
+// Package tables implements the `aaronland/go-sqlite.Table` interfaces for SFO Museum specific Library of Congress (LoC) data stored in SQLite databases.
+package tables
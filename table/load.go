@@ -0,0 +1,240 @@
+package table
+
+import (
+	"context"
+	"fmt"
+	"github.com/sfomuseum/go-csvdict"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/pbzip2"
+	"io"
+	"sync"
+)
+
+// LOAD_BATCH_SIZE is the number of records passed between the stages of the loading pipeline at a time.
+const LOAD_BATCH_SIZE int = 1000
+
+// type loadEntry is a struct containing a record, and its position in the table, passed from the CSV parser
+// to the workers in the loading pipeline.
+type loadEntry struct {
+	idx   uint32
+	id    string
+	label string
+}
+
+// type loadKey is a struct containing a (normalized) code, and the position of the record to index it for, passed
+// from the workers to the shards in the loading pipeline.
+type loadKey struct {
+	code string
+	idx  uint32
+}
+
+// Load() adds the records in the bzip2-compressed CSV data in 'r' to the table. Data is loaded using a pipeline: the
+// bzip2 blocks in 'r' are decompressed in parallel, rows are parsed from the decompressed data and appended to the table
+// and then dispatched, in batches, to a pool of workers which normalize their codes; the codes are then handed to a set
+// of goroutines, each of which owns a disjoint set of the label index's shards, which update the label index. The size of
+// each pool is 'workers' (or `libraryofcongress.DefaultWorkers()` if less than 1). If there is only one worker the data
+// is loaded sequentially. Either way the records for each code are stored in the same order as the records in 'r'.
+// Load() is not safe to use concurrently with any other methods.
+func (t *Table) Load(ctx context.Context, r io.Reader, workers int) error {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if workers < 1 {
+		workers = libraryofcongress.DefaultWorkers()
+	}
+
+	bz_r := pbzip2.NewReader(ctx, r, workers)
+	defer bz_r.Close()
+
+	csv_r, err := csvdict.NewReader(bz_r)
+
+	if err != nil {
+		return fmt.Errorf("Failed to create CSV reader, %w", err)
+	}
+
+	defer t.Sort()
+
+	if workers == 1 {
+		return t.loadSequential(ctx, csv_r)
+	}
+
+	batches := make(chan []*loadEntry, workers)
+	shards := make([]chan []*loadKey, workers)
+
+	for i := range shards {
+		shards[i] = make(chan []*loadKey, workers)
+	}
+
+	shards_wg := new(sync.WaitGroup)
+	workers_wg := new(sync.WaitGroup)
+
+	for _, ch := range shards {
+
+		shards_wg.Add(1)
+
+		go func(ch chan []*loadKey) {
+
+			defer shards_wg.Done()
+
+			for keys := range ch {
+
+				for _, k := range keys {
+					t.IndexCode(k.code, k.idx)
+				}
+			}
+
+		}(ch)
+	}
+
+	for i := 0; i < workers; i++ {
+
+		workers_wg.Add(1)
+
+		go func() {
+
+			defer workers_wg.Done()
+
+			for batch := range batches {
+
+				keys := make([][]*loadKey, len(shards))
+
+				for _, e := range batch {
+
+					for _, code := range t.Codes(e.id, e.label) {
+						shard := ShardIndex(code) % len(shards)
+						keys[shard] = append(keys[shard], &loadKey{code: code, idx: e.idx})
+					}
+				}
+
+				for shard, k := range keys {
+
+					if len(k) == 0 {
+						continue
+					}
+
+					select {
+					case <-ctx.Done():
+						return
+					case shards[shard] <- k:
+						// pass
+					}
+				}
+			}
+		}()
+	}
+
+	load_err := t.parse(ctx, csv_r, batches)
+
+	close(batches)
+	workers_wg.Wait()
+
+	for _, ch := range shards {
+		close(ch)
+	}
+
+	shards_wg.Wait()
+
+	if load_err != nil {
+		return load_err
+	}
+
+	return ctx.Err()
+}
+
+// parse() reads rows from 'csv_r', appends them to the table and dispatches them, in batches, to 'batches'.
+func (t *Table) parse(ctx context.Context, csv_r *csvdict.Reader, batches chan []*loadEntry) error {
+
+	batch := make([]*loadEntry, 0, LOAD_BATCH_SIZE)
+
+	dispatch := func() error {
+
+		if len(batch) == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case batches <- batch:
+			// pass
+		}
+
+		batch = make([]*loadEntry, 0, LOAD_BATCH_SIZE)
+		return nil
+	}
+
+	for {
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			// pass
+		}
+
+		row, err := csv_r.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return fmt.Errorf("Failed to read row, %w", err)
+		}
+
+		id := row["id"]
+		label := row["label"]
+
+		idx, err := t.Append(id, label)
+
+		if err != nil {
+			return fmt.Errorf("Failed to append row (%s %s), %w", id, label, err)
+		}
+
+		batch = append(batch, &loadEntry{idx: idx, id: id, label: label})
+
+		if len(batch) == LOAD_BATCH_SIZE {
+
+			err := dispatch()
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return dispatch()
+}
+
+// loadSequential() adds the rows in 'csv_r' to the table using a single goroutine.
+func (t *Table) loadSequential(ctx context.Context, csv_r *csvdict.Reader) error {
+
+	for {
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			// pass
+		}
+
+		row, err := csv_r.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return fmt.Errorf("Failed to read row, %w", err)
+		}
+
+		_, err = t.Add(row["id"], row["label"])
+
+		if err != nil {
+			return fmt.Errorf("Failed to append row (%s %s), %w", row["id"], row["label"], err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package table
+
+import (
+	"context"
+	"errors"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress/data"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+
+	if testing.Short() {
+		t.Skip("Skipping comparison of embedded data loaded with different numbers of workers in short mode")
+	}
+
+	ctx := context.Background()
+
+	load := func(workers int) *Table {
+
+		r, err := data.FS.Open("lcsh.csv.bz2")
+
+		if err != nil {
+			t.Fatalf("Failed to open data, %v", err)
+		}
+
+		defer r.Close()
+
+		tbl := NewTable(libraryofcongress.NormalizeSubdivisions, true)
+
+		err = tbl.Load(ctx, r, workers)
+
+		if err != nil {
+			t.Fatalf("Failed to load data with %d workers, %v", workers, err)
+		}
+
+		return tbl
+	}
+
+	sequential := load(1)
+	parallel := load(4)
+
+	if sequential.Len() == 0 || sequential.Len() != parallel.Len() {
+		t.Fatalf("Expected the same number of records, got %d and %d", sequential.Len(), parallel.Len())
+	}
+
+	// Compare a sample of the records since finding every record in both tables is slow
+
+	count := 0
+
+	err := sequential.Iterate(ctx, func(ctx context.Context, r Row) error {
+
+		count += 1
+
+		if count%50 != 0 {
+			return nil
+		}
+
+		expected := sequential.Find(r.Label)
+		rows := parallel.Find(r.Label)
+
+		if len(rows) != len(expected) {
+			t.Fatalf("Expected %d results for '%s', got %d", len(expected), r.Label, len(rows))
+		}
+
+		for i, row := range rows {
+
+			if row != expected[i] {
+				t.Fatalf("Unexpected result %d for '%s', %v", i, r.Label, row)
+			}
+		}
+
+		if len(parallel.FindId(r.Id)) != len(sequential.FindId(r.Id)) {
+			t.Fatalf("Unexpected results for '%s'", r.Id)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("Failed to iterate table, %v", err)
+	}
+}
+
+func TestLoadCancelled(t *testing.T) {
+
+	r, err := data.FS.Open("lcsh.csv.bz2")
+
+	if err != nil {
+		t.Fatalf("Failed to open data, %v", err)
+	}
+
+	defer r.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tbl := NewTable(libraryofcongress.NormalizeSubdivisions, true)
+
+	err = tbl.Load(ctx, r, 2)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled error, got %v", err)
+	}
+}
@@ -0,0 +1,644 @@
+// Package table implements a memory-lean, in-memory table of (identifier, label) records used by the `lcsh://` and
+// `lcnaf://` lookups.
+//
+// Rather than allocating a struct and a handful of strings for each record, identifiers and labels are copied in to
+// large, fixed-size chunks of bytes (an "arena") and each record is stored as a fixed-size struct of offsets in to
+// that arena. Records are referred to by their (uint32) position in the table. Identifiers are indexed using a list of
+// record positions sorted by identifier, which is queried using a binary search, and (normalized) labels are indexed
+// using a set of maps keyed by the 64-bit hash of each label whose values are record positions. Hash collisions are
+// resolved by comparing the normalized labels of candidate records at query time.
+package table
+
+import (
+	"context"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"sort"
+	"sync"
+)
+
+// SHARDS is the number of shards (maps) used to index labels. Codes are assigned to a shard using their hash.
+const SHARDS int = 64
+
+// CHUNK_SIZE is the size, in bytes, of each chunk in the arena used to store identifiers and labels.
+const CHUNK_SIZE int = 1 << 22
+
+// MAX_CHUNKS is the maximum number of chunks in the arena. Offsets in to the arena are stored as uint32 values so
+// the arena can hold at most 4GB of strings.
+const MAX_CHUNKS int = (1 << 32) / CHUNK_SIZE
+
+// ITERATE_BATCH_SIZE is the number of records read from the table, while holding its lock, at a time during iteration.
+const ITERATE_BATCH_SIZE int = 1000
+
+// multiple is the flag set on values in the label index which are the position of a list of records (in the shard's
+// lists) rather than the position of a single record.
+const multiple uint32 = 1 << 31
+
+// fnv_offset and fnv_prime are the parameters for the 64-bit FNV-1a hash function.
+const fnv_offset uint64 = 14695981039346656037
+const fnv_prime uint64 = 1099511628211
+
+// type Row is a struct containing the identifier and label for a record in a `Table`.
+type Row struct {
+	// Id is the identifier for the record.
+	Id string
+	// Label is the label for the record.
+	Label string
+}
+
+// type record is a struct containing the offsets, in to the arena, and lengths of the identifier and label for a record.
+type record struct {
+	id        uint32
+	id_len    uint32
+	label     uint32
+	label_len uint32
+}
+
+// type shard is a struct containing the label index for a subset of codes.
+type shard struct {
+	codes map[uint64]uint32
+	lists [][]uint32
+}
+
+// type Table is a struct containing a set of (identifier, label) records, and indices for querying them.
+type Table struct {
+	normalize  libraryofcongress.NormalizeFunc
+	index_ids  bool
+	mu         *sync.RWMutex
+	chunks     [][]byte
+	records    []record
+	removed    map[uint32]bool
+	ids        []uint32
+	ids_sorted bool
+	shards     []*shard
+}
+
+// NewTable() returns a new (empty) `Table` instance whose labels are indexed using their value after being normalized
+// by 'normalize'. If 'index_ids' is true then (normalized) identifiers are indexed alongside labels, so they can be
+// queried using `Find`, otherwise identifiers can only be queried using `FindId`.
+func NewTable(normalize libraryofcongress.NormalizeFunc, index_ids bool) *Table {
+
+	if normalize == nil {
+		normalize = libraryofcongress.NoNormalize
+	}
+
+	t := &Table{
+		normalize: normalize,
+		index_ids: index_ids,
+		mu:        new(sync.RWMutex),
+	}
+
+	t.reset()
+	return t
+}
+
+// Reset() removes all the records from the table.
+func (t *Table) Reset() {
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.reset()
+}
+
+// Len() returns the number of records in the table.
+func (t *Table) Len() int {
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return len(t.records) - len(t.removed)
+}
+
+// Insert() adds a record for 'id' and 'label' to the table, updating all its indices.
+func (t *Table) Insert(id string, label string) error {
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.insert(id, label)
+}
+
+// insert() adds a record for 'id' and 'label' to the table, updating all its indices. The caller must hold the table's lock.
+func (t *Table) insert(id string, label string) error {
+
+	sorted := t.ids_sorted
+
+	idx, err := t.appendRecord(id, label)
+
+	if err != nil {
+		return err
+	}
+
+	for _, code := range t.Codes(id, label) {
+		t.indexCode(code, idx)
+	}
+
+	// The record was appended to the end of the identifiers index so move it in to place
+
+	if sorted {
+
+		i := sort.Search(len(t.ids)-1, func(i int) bool {
+			return t.idAt(t.ids[i]) > id
+		})
+
+		copy(t.ids[i+1:], t.ids[i:len(t.ids)-1])
+		t.ids[i] = idx
+
+		t.ids_sorted = true
+	}
+
+	return nil
+}
+
+// Find() returns the records whose (normalized) label, or identifier if the table was created with 'index_ids' set, match 'code'
+// once it has been normalized. Records are returned in the order they were added to the table.
+func (t *Table) Find(code string) []Row {
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	code = t.normalize(code)
+	rows := make([]Row, 0)
+
+	for _, idx := range t.lookupCode(code) {
+
+		r := t.row(idx)
+
+		if t.normalize(r.Label) == code || (t.index_ids && t.normalize(r.Id) == code) {
+			rows = append(rows, r)
+		}
+	}
+
+	return rows
+}
+
+// FindId() returns the records whose identifier is 'id'.
+func (t *Table) FindId(id string) []Row {
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	rows := make([]Row, 0)
+
+	for _, idx := range t.findId(id) {
+		rows = append(rows, t.row(idx))
+	}
+
+	return rows
+}
+
+// Remove() removes all the records whose identifier is 'id' from the table and returns the number of records removed.
+// The space used by the identifiers and labels of removed records is not reclaimed.
+func (t *Table) Remove(id string) int {
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	matches := t.findId(id)
+
+	if len(matches) == 0 {
+		return 0
+	}
+
+	t.removeRecords(matches)
+	return len(matches)
+}
+
+// Replace() removes all the records whose identifier is 'id' from the table and adds a record for 'new_id' and 'label'
+// in their place, returning the number of records removed. Both steps happen while the table is locked, so queries never
+// see the table with neither record, and the new record is added first so that if it can not be added the table is left
+// unchanged. If there are no records whose identifier is 'id' then the table is not changed.
+func (t *Table) Replace(id string, new_id string, label string) (int, error) {
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	matches := t.findId(id)
+
+	if len(matches) == 0 {
+		return 0, nil
+	}
+
+	err := t.insert(new_id, label)
+
+	if err != nil {
+		return 0, err
+	}
+
+	t.removeRecords(matches)
+	return len(matches), nil
+}
+
+// removeRecords() removes the records at positions 'matches' from the table. The caller must hold the table's lock.
+func (t *Table) removeRecords(matches []uint32) {
+
+	for _, idx := range matches {
+
+		r := t.row(idx)
+
+		for _, code := range t.Codes(r.Id, r.Label) {
+			t.unindexCode(code, idx)
+		}
+
+		t.removed[idx] = true
+	}
+
+	ids := t.ids[:0]
+
+	for _, idx := range t.ids {
+
+		if !t.removed[idx] {
+			ids = append(ids, idx)
+		}
+	}
+
+	t.ids = ids
+}
+
+// Iterate() invokes 'cb' for each record in the table in the order they were added. The table is not locked while
+// 'cb' is invoked so it is safe for 'cb' to modify the table; records added during iteration may or may not be visited.
+func (t *Table) Iterate(ctx context.Context, cb func(context.Context, Row) error) error {
+
+	offset := 0
+
+	for {
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			// pass
+		}
+
+		t.mu.RLock()
+
+		rows := make([]Row, 0, ITERATE_BATCH_SIZE)
+
+		for ; offset < len(t.records) && len(rows) < ITERATE_BATCH_SIZE; offset++ {
+
+			idx := uint32(offset)
+
+			if len(t.removed) > 0 && t.removed[idx] {
+				continue
+			}
+
+			rows = append(rows, t.row(idx))
+		}
+
+		done := offset >= len(t.records)
+
+		t.mu.RUnlock()
+
+		for _, r := range rows {
+
+			err := cb(ctx, r)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		if done {
+			return nil
+		}
+	}
+}
+
+// Codes() returns the list of (normalized) codes that a record for 'id' and 'label' is indexed by.
+func (t *Table) Codes(id string, label string) []string {
+
+	codes := make([]string, 0, 2)
+
+	if label != "" {
+		codes = append(codes, t.normalize(label))
+	}
+
+	if t.index_ids && id != "" {
+
+		code := t.normalize(id)
+
+		if len(codes) == 0 || codes[0] != code {
+			codes = append(codes, code)
+		}
+	}
+
+	return codes
+}
+
+// Add() adds a record for 'id' and 'label' to the table and indexes its codes but does not sort the identifiers index.
+// It is meant to be used when bulk-loading data, is not safe to use concurrently and should be followed by a call to
+// `Sort` once all the records have been added.
+func (t *Table) Add(id string, label string) (uint32, error) {
+
+	idx, err := t.Append(id, label)
+
+	if err != nil {
+		return 0, err
+	}
+
+	for _, code := range t.Codes(id, label) {
+		t.IndexCode(code, idx)
+	}
+
+	return idx, nil
+}
+
+// Append() adds a record for 'id' and 'label' to the table, returning its position, without indexing its codes or
+// sorting the identifiers index. It is meant to be used when bulk-loading data and is not safe to use concurrently.
+// Records should be indexed using `IndexCode` and the identifiers index sorted using `Sort` once all the records
+// have been added.
+func (t *Table) Append(id string, label string) (uint32, error) {
+	return t.appendRecord(id, label)
+}
+
+// IndexCode() adds the record at position 'idx' to the label index for 'code', keeping the records for each code in
+// the order they were added. It is meant to be used when bulk-loading data. It is safe to call concurrently for codes
+// in different shards (as determined by `ShardIndex`).
+func (t *Table) IndexCode(code string, idx uint32) {
+	t.indexCode(code, idx)
+}
+
+// Sort() sorts the identifiers index.
+func (t *Table) Sort() {
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.ids_sorted {
+		return
+	}
+
+	sort.SliceStable(t.ids, func(i, j int) bool {
+		return t.idAt(t.ids[i]) < t.idAt(t.ids[j])
+	})
+
+	t.ids_sorted = true
+}
+
+// ShardIndex() returns the index of the shard in the label index that 'code' is stored in.
+func ShardIndex(code string) int {
+	return int(hashCode(code) % uint64(SHARDS))
+}
+
+// reset() removes all the records from the table without locking it.
+func (t *Table) reset() {
+
+	t.chunks = make([][]byte, 0)
+	t.records = make([]record, 0)
+	t.removed = make(map[uint32]bool)
+	t.ids = make([]uint32, 0)
+	t.ids_sorted = true
+	t.shards = make([]*shard, SHARDS)
+
+	for i := range t.shards {
+
+		t.shards[i] = &shard{
+			codes: make(map[uint64]uint32),
+			lists: make([][]uint32, 0),
+		}
+	}
+}
+
+// appendRecord() stores 'id' and 'label' in the arena and appends a new record, and its position in the (unsorted)
+// identifiers index, to the table.
+func (t *Table) appendRecord(id string, label string) (uint32, error) {
+
+	if len(t.records) >= int(multiple) {
+		return 0, fmt.Errorf("Table is full")
+	}
+
+	id_offset, err := t.storeString(id)
+
+	if err != nil {
+		return 0, fmt.Errorf("Failed to store identifier, %w", err)
+	}
+
+	label_offset, err := t.storeString(label)
+
+	if err != nil {
+		return 0, fmt.Errorf("Failed to store label, %w", err)
+	}
+
+	r := record{
+		id:        id_offset,
+		id_len:    uint32(len(id)),
+		label:     label_offset,
+		label_len: uint32(len(label)),
+	}
+
+	idx := uint32(len(t.records))
+
+	t.records = append(t.records, r)
+	t.ids = append(t.ids, idx)
+	t.ids_sorted = false
+
+	return idx, nil
+}
+
+// storeString() copies 's' in to the arena and returns its offset.
+func (t *Table) storeString(s string) (uint32, error) {
+
+	if len(s) > CHUNK_SIZE {
+		return 0, fmt.Errorf("String exceeds maximum length (%d bytes)", CHUNK_SIZE)
+	}
+
+	last := len(t.chunks) - 1
+
+	if last == -1 || len(t.chunks[last])+len(s) > CHUNK_SIZE {
+
+		if len(t.chunks) == MAX_CHUNKS {
+			return 0, fmt.Errorf("Arena is full")
+		}
+
+		t.chunks = append(t.chunks, make([]byte, 0, CHUNK_SIZE))
+		last += 1
+	}
+
+	offset := uint32(last*CHUNK_SIZE + len(t.chunks[last]))
+	t.chunks[last] = append(t.chunks[last], s...)
+
+	return offset, nil
+}
+
+// loadString() returns the string of 'length' bytes stored at 'offset' in the arena.
+func (t *Table) loadString(offset uint32, length uint32) string {
+
+	chunk := t.chunks[int(offset)/CHUNK_SIZE]
+	start := int(offset) % CHUNK_SIZE
+
+	return string(chunk[start : start+int(length)])
+}
+
+// row() returns the `Row` for the record at position 'idx'.
+func (t *Table) row(idx uint32) Row {
+
+	r := t.records[idx]
+
+	row := Row{
+		Id:    t.loadString(r.id, r.id_len),
+		Label: t.loadString(r.label, r.label_len),
+	}
+
+	return row
+}
+
+// idAt() returns the identifier for the record at position 'idx'.
+func (t *Table) idAt(idx uint32) string {
+	r := t.records[idx]
+	return t.loadString(r.id, r.id_len)
+}
+
+// findId() returns the positions of the records whose identifier is 'id'.
+func (t *Table) findId(id string) []uint32 {
+
+	matches := make([]uint32, 0)
+
+	if !t.ids_sorted {
+
+		for _, idx := range t.ids {
+
+			if t.idAt(idx) == id {
+				matches = append(matches, idx)
+			}
+		}
+
+		return matches
+	}
+
+	i := sort.Search(len(t.ids), func(i int) bool {
+		return t.idAt(t.ids[i]) >= id
+	})
+
+	for ; i < len(t.ids) && t.idAt(t.ids[i]) == id; i++ {
+		matches = append(matches, t.ids[i])
+	}
+
+	return matches
+}
+
+// lookupCode() returns the positions of the records indexed under the hash of 'code'.
+func (t *Table) lookupCode(code string) []uint32 {
+
+	h := hashCode(code)
+	s := t.shards[h%uint64(SHARDS)]
+
+	v, ok := s.codes[h]
+
+	if !ok {
+		return nil
+	}
+
+	if v&multiple == 0 {
+		return []uint32{v}
+	}
+
+	return s.lists[v&^multiple]
+}
+
+// indexCode() adds the record at position 'idx' to the label index for 'code'.
+func (t *Table) indexCode(code string, idx uint32) {
+
+	h := hashCode(code)
+	s := t.shards[h%uint64(SHARDS)]
+
+	v, ok := s.codes[h]
+
+	if !ok {
+		s.codes[h] = idx
+		return
+	}
+
+	var list []uint32
+
+	if v&multiple == 0 {
+		list = []uint32{v}
+	} else {
+		list = s.lists[v&^multiple]
+	}
+
+	// Keep the list sorted by position, so that records are returned in the order they were added,
+	// and create a new list rather than modifying the existing one in place
+
+	i := sort.Search(len(list), func(i int) bool {
+		return list[i] >= idx
+	})
+
+	if i < len(list) && list[i] == idx {
+		return
+	}
+
+	updated := make([]uint32, len(list)+1)
+	copy(updated, list[:i])
+	updated[i] = idx
+	copy(updated[i+1:], list[i:])
+
+	if v&multiple == 0 {
+		s.codes[h] = uint32(len(s.lists)) | multiple
+		s.lists = append(s.lists, updated)
+		return
+	}
+
+	s.lists[v&^multiple] = updated
+}
+
+// unindexCode() removes the record at position 'idx' from the label index for 'code'.
+func (t *Table) unindexCode(code string, idx uint32) {
+
+	h := hashCode(code)
+	s := t.shards[h%uint64(SHARDS)]
+
+	v, ok := s.codes[h]
+
+	if !ok {
+		return
+	}
+
+	if v&multiple == 0 {
+
+		if v == idx {
+			delete(s.codes, h)
+		}
+
+		return
+	}
+
+	list := s.lists[v&^multiple]
+	updated := make([]uint32, 0, len(list))
+
+	for _, other := range list {
+
+		if other != idx {
+			updated = append(updated, other)
+		}
+	}
+
+	switch len(updated) {
+	case 0:
+		delete(s.codes, h)
+	case 1:
+		s.codes[h] = updated[0]
+	default:
+		s.lists[v&^multiple] = updated
+		return
+	}
+
+	// The list is no longer referenced so release its memory; its slot in the shard's
+	// lists is not reused
+
+	s.lists[v&^multiple] = nil
+}
+
+// hashCode() returns the 64-bit FNV-1a hash of 'code'. It is computed inline, rather than using the "hash/fnv"
+// package, to avoid allocating a new hash and a copy of 'code' for every call.
+func hashCode(code string) uint64 {
+
+	h := uint64(fnv_offset)
+
+	for i := 0; i < len(code); i++ {
+		h ^= uint64(code[i])
+		h *= fnv_prime
+	}
+
+	return h
+}
@@ -0,0 +1,276 @@
+package table
+
+import (
+	"context"
+	"fmt"
+	"github.com/sfomuseum/go-sfomuseum-libraryofcongress"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestTable(t *testing.T) {
+
+	tbl := NewTable(libraryofcongress.NormalizeSubdivisions, true)
+
+	records := [][]string{
+		[]string{"sh85002782", "Airplanes"},
+		[]string{"sh2010007517", "Cooking"},
+		[]string{"sh85031842", "Cooking"},
+		[]string{"sh85003553", "Aeronautics--Popular works"},
+	}
+
+	for _, r := range records {
+
+		_, err := tbl.Add(r[0], r[1])
+
+		if err != nil {
+			t.Fatalf("Failed to add %s, %v", r[0], err)
+		}
+	}
+
+	tbl.Sort()
+
+	err := tbl.Insert("sh00000001", "Cooking")
+
+	if err != nil {
+		t.Fatalf("Failed to insert record, %v", err)
+	}
+
+	if tbl.Len() != 5 {
+		t.Fatalf("Expected 5 records, got %d", tbl.Len())
+	}
+
+	tests := map[string][]string{
+		"Airplanes":                    []string{"sh85002782"},
+		"sh85002782":                   []string{"sh85002782"},
+		"Cooking":                      []string{"sh2010007517", "sh85031842", "sh00000001"},
+		"Aeronautics -- Popular works": []string{"sh85003553"},
+		"Tacos":                        []string{},
+	}
+
+	for code, expected := range tests {
+
+		rows := tbl.Find(code)
+
+		if len(rows) != len(expected) {
+			t.Fatalf("Expected %d results for '%s', got %d", len(expected), code, len(rows))
+		}
+
+		for i, r := range rows {
+
+			if r.Id != expected[i] {
+				t.Fatalf("Unexpected result %d for '%s', %s", i, code, r.Id)
+			}
+		}
+	}
+
+	rows := tbl.FindId("sh00000001")
+
+	if len(rows) != 1 || rows[0].Label != "Cooking" {
+		t.Fatalf("Unexpected results for sh00000001, %v", rows)
+	}
+
+	if tbl.Remove("sh85031842") != 1 {
+		t.Fatalf("Failed to remove sh85031842")
+	}
+
+	if tbl.Remove("sh85031842") != 0 {
+		t.Fatalf("Expected sh85031842 to have already been removed")
+	}
+
+	rows = tbl.Find("Cooking")
+
+	if len(rows) != 2 || rows[0].Id != "sh2010007517" || rows[1].Id != "sh00000001" {
+		t.Fatalf("Unexpected results for Cooking after removal, %v", rows)
+	}
+
+	if len(tbl.FindId("sh85031842")) != 0 {
+		t.Fatalf("Expected sh85031842 to be removed from the identifiers index")
+	}
+
+	ids := make([]string, 0)
+
+	err = tbl.Iterate(context.Background(), func(ctx context.Context, r Row) error {
+		ids = append(ids, r.Id)
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("Failed to iterate table, %v", err)
+	}
+
+	if fmt.Sprintf("%v", ids) != "[sh85002782 sh2010007517 sh85003553 sh00000001]" {
+		t.Fatalf("Unexpected records, %v", ids)
+	}
+
+	tbl.Reset()
+
+	if tbl.Len() != 0 || len(tbl.Find("Airplanes")) != 0 {
+		t.Fatalf("Expected table to be empty after reset")
+	}
+}
+
+func TestTableReplace(t *testing.T) {
+
+	tbl := NewTable(libraryofcongress.NormalizeSubdivisions, true)
+
+	for _, r := range [][]string{
+		[]string{"sh85002782", "Airplanes"},
+		[]string{"sh2010007517", "Cooking"},
+	} {
+
+		err := tbl.Insert(r[0], r[1])
+
+		if err != nil {
+			t.Fatalf("Failed to insert %s, %v", r[0], err)
+		}
+	}
+
+	removed, err := tbl.Replace("sh85002782", "sh85002782", "Aeroplanes")
+
+	if err != nil || removed != 1 {
+		t.Fatalf("Failed to replace sh85002782, %d %v", removed, err)
+	}
+
+	if len(tbl.Find("Airplanes")) != 0 || len(tbl.Find("Aeroplanes")) != 1 || len(tbl.FindId("sh85002782")) != 1 {
+		t.Fatalf("Unexpected results after replacing sh85002782")
+	}
+
+	removed, err = tbl.Replace("sh00000000", "sh00000001", "Tacos")
+
+	if err != nil || removed != 0 || len(tbl.Find("Tacos")) != 0 {
+		t.Fatalf("Expected replacing a missing record to leave the table unchanged, %d %v", removed, err)
+	}
+
+	// Records which can not be added leave the table unchanged
+
+	_, err = tbl.Replace("sh2010007517", "sh2010007517", strings.Repeat("x", CHUNK_SIZE+1))
+
+	if err == nil {
+		t.Fatalf("Expected replacing a record with an oversized label to fail")
+	}
+
+	rows := tbl.FindId("sh2010007517")
+
+	if len(rows) != 1 || rows[0].Label != "Cooking" || len(tbl.Find("Cooking")) != 1 || tbl.Len() != 2 {
+		t.Fatalf("Expected failed replacement to leave the table unchanged, %v", rows)
+	}
+}
+
+func TestTableIdentifiers(t *testing.T) {
+
+	tbl := NewTable(libraryofcongress.NoNormalize, false)
+
+	records := [][]string{
+		[]string{"no2006016666", "Paige, Colin"},
+		[]string{"n79100565", "Lindbergh, Charles A. (Charles Augustus), 1902-1974"},
+		[]string{"n2003099999", "Halstenberg, Friedrich"},
+	}
+
+	for _, r := range records {
+
+		_, err := tbl.Add(r[0], r[1])
+
+		if err != nil {
+			t.Fatalf("Failed to add %s, %v", r[0], err)
+		}
+	}
+
+	tbl.Sort()
+
+	err := tbl.Insert("n94099999", "Tampa Joe")
+
+	if err != nil {
+		t.Fatalf("Failed to insert record, %v", err)
+	}
+
+	tests := map[string]string{
+		"n79100565":    "Lindbergh, Charles A. (Charles Augustus), 1902-1974",
+		"no2006016666": "Paige, Colin",
+		"n94099999":    "Tampa Joe",
+	}
+
+	for id, label := range tests {
+
+		rows := tbl.FindId(id)
+
+		if len(rows) != 1 {
+			t.Fatalf("Expected a single match for '%s', got %d", id, len(rows))
+		}
+
+		if rows[0].Label != label {
+			t.Fatalf("Unexpected label for '%s': %s", id, rows[0].Label)
+		}
+
+		// Identifiers are not indexed alongside labels
+
+		if len(tbl.Find(id)) != 0 {
+			t.Fatalf("Expected '%s' not to be found in the label index", id)
+		}
+	}
+
+	if len(tbl.FindId("n00000000")) != 0 {
+		t.Fatalf("Expected no matches for n00000000")
+	}
+}
+
+func TestTableHashCollision(t *testing.T) {
+
+	tbl := NewTable(libraryofcongress.NoNormalize, false)
+
+	idx, err := tbl.Add("sh2010007517", "Cooking")
+
+	if err != nil {
+		t.Fatalf("Failed to add record, %v", err)
+	}
+
+	// Simulate a hash collision between "Tacos" and "Cooking"
+
+	tbl.IndexCode("Tacos", idx)
+
+	if len(tbl.Find("Tacos")) != 0 {
+		t.Fatalf("Expected colliding record to be excluded from results")
+	}
+
+	if len(tbl.Find("Cooking")) != 1 {
+		t.Fatalf("Expected to find Cooking")
+	}
+}
+
+func BenchmarkTableAdd(b *testing.B) {
+
+	count := 1000000
+
+	for i := 0; i < b.N; i++ {
+
+		var before runtime.MemStats
+		var after runtime.MemStats
+
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+
+		tbl := NewTable(libraryofcongress.NormalizeSubdivisions, false)
+
+		for j := 0; j < count; j++ {
+
+			_, err := tbl.Add(fmt.Sprintf("n%d", 79000000+j), fmt.Sprintf("Person, Example %d, 1900-1990", j))
+
+			if err != nil {
+				b.Fatalf("Failed to add record, %v", err)
+			}
+		}
+
+		tbl.Sort()
+
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+
+		heap := float64(after.HeapAlloc) - float64(before.HeapAlloc)
+
+		b.ReportMetric(heap/(1024*1024), "heap-MB")
+		b.ReportMetric(heap/float64(count), "heap-bytes/record")
+
+		runtime.KeepAlive(tbl)
+	}
+}
@@ -0,0 +1,91 @@
+package libraryofcongress
+
+import (
+	"context"
+	"fmt"
+)
+
+// type TypedLookup wraps a `Lookup` instance and returns results of type 'T' rather than `interface{}`. Results which
+// can not be converted to 'T' trigger an error rather than a panic.
+type TypedLookup[T Record] struct {
+	lookup Lookup
+}
+
+// NewTypedLookup() returns a new `TypedLookup` instance wrapping the `Lookup` instance derived from 'uri'.
+func NewTypedLookup[T Record](ctx context.Context, uri string) (*TypedLookup[T], error) {
+
+	l, err := NewLookup(ctx, uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create lookup, %w", err)
+	}
+
+	return NewTypedLookupWithLookup[T](l), nil
+}
+
+// NewTypedLookupWithLookup() returns a new `TypedLookup` instance wrapping 'l'.
+func NewTypedLookupWithLookup[T Record](l Lookup) *TypedLookup[T] {
+
+	tl := &TypedLookup[T]{
+		lookup: l,
+	}
+
+	return tl
+}
+
+// Find() searches for a given LoC identifier returning results of type 'T'.
+func (tl *TypedLookup[T]) Find(ctx context.Context, code string) ([]T, error) {
+
+	results, err := tl.lookup.Find(ctx, code)
+
+	if err != nil {
+		return nil, err
+	}
+
+	typed := make([]T, len(results))
+
+	for idx, r := range results {
+
+		v, ok := r.(T)
+
+		if !ok {
+			var t T
+			return nil, fmt.Errorf("Unexpected result type %T for '%s', expected %T", r, code, t)
+		}
+
+		typed[idx] = v
+	}
+
+	return typed, nil
+}
+
+// FindOne() searches for a given LoC identifier returning exactly one result of type 'T'. It returns the same
+// errors as the `FindOne` function.
+func (tl *TypedLookup[T]) FindOne(ctx context.Context, code string) (T, error) {
+
+	var t T
+
+	r, err := FindOne(ctx, tl.lookup, code)
+
+	if err != nil {
+		return t, err
+	}
+
+	v, ok := r.(T)
+
+	if !ok {
+		return t, fmt.Errorf("Unexpected result type %T for '%s', expected %T", r, code, t)
+	}
+
+	return v, nil
+}
+
+// Append() indexes a LoC record of type 'T'.
+func (tl *TypedLookup[T]) Append(ctx context.Context, r T) error {
+	return tl.lookup.Append(ctx, r)
+}
+
+// Lookup() returns the underlying `Lookup` instance.
+func (tl *TypedLookup[T]) Lookup() Lookup {
+	return tl.lookup
+}
@@ -0,0 +1,76 @@
+package libraryofcongress
+
+import (
+	"context"
+	"testing"
+)
+
+type TestRecord struct {
+	Id    string
+	Label string
+}
+
+func (r *TestRecord) GetId() string {
+	return r.Id
+}
+
+func (r *TestRecord) GetLabel() string {
+	return r.Label
+}
+
+func (r *TestRecord) GetSource() string {
+	return "test"
+}
+
+type TestRecordLookup struct {
+	Lookup
+	records []interface{}
+}
+
+func (t *TestRecordLookup) Find(ctx context.Context, code string) ([]interface{}, error) {
+	return t.records, nil
+}
+
+func (t *TestRecordLookup) Append(ctx context.Context, rec interface{}) error {
+	t.records = append(t.records, rec)
+	return nil
+}
+
+func TestTypedLookup(t *testing.T) {
+
+	ctx := context.Background()
+
+	l := &TestRecordLookup{
+		records: make([]interface{}, 0),
+	}
+
+	tl := NewTypedLookupWithLookup[*TestRecord](l)
+
+	err := tl.Append(ctx, &TestRecord{Id: "test1", Label: "Testing"})
+
+	if err != nil {
+		t.Fatalf("Failed to append record, %v", err)
+	}
+
+	results, err := tl.Find(ctx, "Testing")
+
+	if err != nil {
+		t.Fatalf("Failed to find record, %v", err)
+	}
+
+	if len(results) != 1 || results[0].Id != "test1" {
+		t.Fatalf("Unexpected results: %v", results)
+	}
+
+	err = l.Append(ctx, "testing")
+
+	if err != nil {
+		t.Fatalf("Failed to append string, %v", err)
+	}
+
+	_, err = tl.Find(ctx, "Testing")
+
+	if err == nil {
+		t.Fatalf("Expected mismatched result type to trigger an error")
+	}
+}